@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"../pkg/api"
+)
+
+const adminNeighborhoodsPath = "/admin/neighborhoods"
+
+// Requests to the admin endpoints must carry "Authorization: Bearer <ADMIN_TOKEN>".
+func requireAdminToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeErrorResponse(w, http.StatusUnauthorized, errors.New("A valid admin token is required."))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// Routes:
+//
+//	GET    /admin/neighborhoods?city=<city>&state=<state>  list a city's neighborhoods
+//	POST   /admin/neighborhoods                             create from a GeoJSON Feature
+//	GET    /admin/neighborhoods/<id>                        get as a GeoJSON Feature
+//	PATCH  /admin/neighborhoods/<id>                        rename, i.e, {"name": "Downtown"}
+//	DELETE /admin/neighborhoods/<id>                        delete
+//	PUT    /admin/neighborhoods/<id>/geometry               replace the boundary with a GeoJSON geometry
+func adminNeighborhoodsHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, adminNeighborhoodsPath), "/")
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			listAdminNeighborhoods(w, r)
+		case http.MethodPost:
+			createAdminNeighborhood(w, r)
+		default:
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	segments := strings.Split(path, "/")
+	neighborhoodID, err := strconv.ParseInt(segments[0], 10, 64)
+	if err != nil || len(segments) > 2 || (len(segments) == 2 && segments[1] != "geometry") {
+		writeErrorResponse(w, http.StatusNotFound, errors.New("Not found."))
+		return
+	}
+
+	if len(segments) == 2 {
+		if r.Method != http.MethodPut {
+			writeMethodNotAllowed(w, http.MethodPut)
+			return
+		}
+		replaceAdminNeighborhoodGeometry(w, r, neighborhoodID)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		feature, err := api.FindNeighborhoodFeature(neighborhoodID)
+		writeAdminResult(w, http.StatusOK, feature, err)
+	case http.MethodPatch:
+		renameAdminNeighborhood(w, r, neighborhoodID)
+	case http.MethodDelete:
+		if err := api.DeleteNeighborhood(neighborhoodID); err != nil {
+			writeAdminResult(w, http.StatusNoContent, nil, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func listAdminNeighborhoods(w http.ResponseWriter, r *http.Request) {
+	city := r.URL.Query().Get("city")
+	if city == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("The city query parameter is required."))
+		return
+	}
+
+	neighborhoods, err := api.ListNeighborhoodsOfCity(city, r.URL.Query().Get("state"))
+	if neighborhoods == nil {
+		neighborhoods = []api.Neighborhood{}
+	}
+	writeAdminResult(w, http.StatusOK, neighborhoods, err)
+}
+
+func createAdminNeighborhood(w http.ResponseWriter, r *http.Request) {
+	var feature api.NeighborhoodFeature
+	if err := decodeStrictJSON(r.Body, &feature); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	created, err := api.CreateNeighborhood(feature)
+	writeAdminResult(w, http.StatusCreated, created, err)
+}
+
+func replaceAdminNeighborhoodGeometry(w http.ResponseWriter, r *http.Request, neighborhoodID int64) {
+	var geometry json.RawMessage
+	if err := decodeStrictJSON(r.Body, &geometry); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	replaced, err := api.ReplaceNeighborhoodGeometry(neighborhoodID, geometry)
+	writeAdminResult(w, http.StatusOK, replaced, err)
+}
+
+func renameAdminNeighborhood(w http.ResponseWriter, r *http.Request, neighborhoodID int64) {
+	var rename struct {
+		Name string `json:"name"`
+	}
+	if err := decodeStrictJSON(r.Body, &rename); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	renamed, err := api.RenameNeighborhood(neighborhoodID, rename.Name)
+	writeAdminResult(w, http.StatusOK, renamed, err)
+}
+
+// Writes the result, or the error with a status matching its type.
+func writeAdminResult(w http.ResponseWriter, status int, result interface{}, err error) {
+	var notFound *api.NoNeighborhoodFoundError
+	var invalidGeometry *api.InvalidGeometryError
+	var invalidNeighborhood *api.InvalidNeighborhoodError
+	var hasChildren *api.NeighborhoodHasChildrenError
+	switch {
+	case err == nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(result)
+	case errors.As(err, &notFound):
+		writeErrorResponse(w, http.StatusNotFound, err)
+	case errors.As(err, &invalidGeometry), errors.As(err, &invalidNeighborhood):
+		writeErrorResponse(w, http.StatusUnprocessableEntity, err)
+	case errors.As(err, &hasChildren):
+		writeErrorResponse(w, http.StatusConflict, err)
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowedMethods ...string) {
+	w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+	writeErrorResponse(w, http.StatusMethodNotAllowed, errors.New("Method not allowed."))
+}
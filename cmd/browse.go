@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"../pkg/api"
+)
+
+// GET /cities lists every city with known neighborhoods.
+func citiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	cities, err := api.ListCities()
+	if cities == nil {
+		cities = []api.CitySummary{}
+	}
+	writeBrowseResult(w, cities, err)
+}
+
+// GET /neighborhoods?city=<city>&state=<state> lists a city's neighborhoods. state is optional.
+func neighborhoodsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	city := r.URL.Query().Get("city")
+	if city == "" {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("The city query parameter is required."))
+		return
+	}
+
+	neighborhoods, err := api.ListActiveNeighborhoods(city, r.URL.Query().Get("state"))
+	if neighborhoods == nil {
+		neighborhoods = []api.NeighborhoodSummary{}
+	}
+	writeBrowseResult(w, neighborhoods, err)
+}
+
+// GET /neighborhoods/lookup?latitude=<latitude>&longitude=<longitude> finds the neighborhood containing
+// the point, along with the areas containing it.
+func neighborhoodLookupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	latitude, latitudeErr := strconv.ParseFloat(r.URL.Query().Get("latitude"), 64)
+	longitude, longitudeErr := strconv.ParseFloat(r.URL.Query().Get("longitude"), 64)
+	point := api.Attraction{Latitude: latitude, Longitude: longitude}
+	if latitudeErr != nil || longitudeErr != nil {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("The latitude and longitude query parameters must be numbers."))
+		return
+	}
+
+	if err := point.ValidateCoordinates(); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	neighborhood, err := api.FindNeighborhoodContainingAttraction(point)
+	if err == nil && neighborhood.ID == 0 {
+		writeErrorResponse(w, http.StatusNotFound, errors.New("No neighborhood contains the point."))
+		return
+	}
+	writeBrowseResult(w, neighborhood, err)
+}
+
+// GET /neighborhoods/search?q=<name>&city=<city>&state=<state>&limit=<limit> finds neighborhoods by name,
+// tolerating typos. city, state and limit are optional.
+func neighborhoodSearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	query := r.URL.Query()
+	limit := api.DefaultNeighborhoodSearchLimit
+	if limitParameter := query.Get("limit"); limitParameter != "" {
+		parsedLimit, err := strconv.Atoi(limitParameter)
+		if err != nil || parsedLimit < 1 || parsedLimit > 100 {
+			writeErrorResponse(w, http.StatusBadRequest, errors.New("limit must be between 1 and 100."))
+			return
+		}
+		limit = parsedLimit
+	}
+
+	var region api.Region
+	if city := query.Get("city"); city != "" {
+		region.Cities = []api.RegionCity{{City: city, StateOrProvinceName: query.Get("state")}}
+	}
+
+	results, err := api.SearchNeighborhoods(query.Get("q"), region, limit)
+	var invalidSearch *api.InvalidNeighborhoodError
+	if errors.As(err, &invalidSearch) {
+		writeErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if results == nil {
+		results = []api.NeighborhoodSearchResult{}
+	}
+	writeBrowseResult(w, results, err)
+}
+
+func writeBrowseResult(w http.ResponseWriter, result interface{}, err error) {
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"../pkg/api"
+)
+
+// ComparisonResponse is the comparison matrix along with the attractions which could not be located, and
+// so are left out of it.
+type ComparisonResponse struct {
+	api.NeighborhoodComparisonMatrix
+	FailedAttractions []api.Attraction `json:"failed_attractions"`
+}
+
+// POST /neighborhoods/compare?neighborhood=<name>&neighborhood=<name> compares two or more shortlisted
+// neighborhoods against the attractions in the body, given as for /attractions. Names are resolved within
+// the attractions' cities; strategy picks where distances are measured from.
+func compareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	preferences, err := parsePlanningPreferences(r.URL.Query())
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	names := distinctNames(r.URL.Query()["neighborhood"])
+	if len(names) < 2 {
+		writeErrorResponse(w, http.StatusBadRequest, errors.New("At least two neighborhood query parameters are required."))
+		return
+	}
+
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	locateErrors, err := locateAttractions(r.Context(), attractions, planningGeocoder, noPlanningObserver{})
+	if err != nil {
+		return
+	}
+
+	var response ComparisonResponse
+	var locatedAttractions []api.Attraction
+	for i, attraction := range attractions {
+		if locateErrors[i] != nil {
+			response.FailedAttractions = append(response.FailedAttractions, attraction)
+		} else {
+			locatedAttractions = append(locatedAttractions, attraction)
+		}
+	}
+
+	neighborhoods, err := api.FindNeighborhoodsByName(names, api.NewRegion(locatedAttractions))
+	var notFound *api.NoNeighborhoodFoundError
+	if errors.As(err, &notFound) {
+		writeErrorResponse(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err == nil {
+		response.NeighborhoodComparisonMatrix, err = api.CompareNeighborhoods(neighborhoods, locatedAttractions, preferences.ScoringStrategy)
+	}
+	writeBrowseResult(w, response, err)
+}
+
+// Drops blank and repeated names, ignoring case.
+func distinctNames(names []string) []string {
+	var distinct []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		distinct = append(distinct, strings.TrimSpace(name))
+	}
+
+	return distinct
+}
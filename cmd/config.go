@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"../pkg/api"
+)
+
+// serverConfig is how the server is run. Each setting is read, in increasing precedence, from its
+// default, the JSON config file, its environment variable and its flag (see configSettings).
+type serverConfig struct {
+	ListenAddress string
+	GRPCAddress   string
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	IdleTimeout   time.Duration
+	// ShutdownTimeout bounds how long in-flight requests and planning jobs are waited for on shutdown.
+	ShutdownTimeout time.Duration
+	// TLS is served when both the certificate and key files are set.
+	TLSCertFile         string
+	TLSKeyFile          string
+	MaxRequestBodyBytes int64
+	NominatimURL        string
+	GeocoderTimeout     time.Duration
+	AdminToken          string
+	RedisAddress        string
+	OSRMURL             string
+	OSRMProfile         string
+	// CallbackHosts restricts job callbacks to these hosts, which may then be private.
+	CallbackHosts []string
+	// RefreshDistancesOnStart rebuilds the precomputed neighborhood distances in the background on start,
+	// so they include neighborhoods imported while the server was down.
+	RefreshDistancesOnStart bool
+}
+
+// Planning a long list of attractions through Nominatim, which allows about one request a second, takes
+// minutes; the write timeout must outlast it.
+func defaultServerConfig() serverConfig {
+	return serverConfig{
+		ListenAddress:           ":8080",
+		GRPCAddress:             defaultGRPCAddress,
+		ReadTimeout:             time.Minute,
+		WriteTimeout:            10 * time.Minute,
+		IdleTimeout:             2 * time.Minute,
+		ShutdownTimeout:         2 * time.Minute,
+		MaxRequestBodyBytes:     defaultMaxRequestBodyBytes,
+		NominatimURL:            api.DefaultNominatimURL,
+		GeocoderTimeout:         api.DefaultNominatimTimeout,
+		OSRMProfile:             api.DefaultOSRMProfile,
+		RefreshDistancesOnStart: true,
+	}
+}
+
+// configSetting is a setting's name in the config file, which is also its flag with dashes for
+// underscores, and its environment variable.
+type configSetting struct {
+	name  string
+	env   string
+	usage string
+	set   func(config *serverConfig, value string) error
+}
+
+var configSettings = []configSetting{
+	stringSetting("listen_address", "LISTEN_ADDR", "address the HTTP API listens on", func(config *serverConfig) *string { return &config.ListenAddress }),
+	stringSetting("grpc_address", "GRPC_ADDR", "address the gRPC service listens on", func(config *serverConfig) *string { return &config.GRPCAddress }),
+	durationSetting("read_timeout", "READ_TIMEOUT", "longest time to read a request, i.e 1m", func(config *serverConfig) *time.Duration { return &config.ReadTimeout }),
+	durationSetting("write_timeout", "WRITE_TIMEOUT", "longest time to plan and write a response", func(config *serverConfig) *time.Duration { return &config.WriteTimeout }),
+	durationSetting("idle_timeout", "IDLE_TIMEOUT", "how long idle keep-alive connections are kept", func(config *serverConfig) *time.Duration { return &config.IdleTimeout }),
+	durationSetting("shutdown_timeout", "SHUTDOWN_TIMEOUT", "how long in-flight planning is waited for on shutdown", func(config *serverConfig) *time.Duration { return &config.ShutdownTimeout }),
+	stringSetting("tls_cert_file", "TLS_CERT_FILE", "PEM certificate to serve TLS with", func(config *serverConfig) *string { return &config.TLSCertFile }),
+	stringSetting("tls_key_file", "TLS_KEY_FILE", "PEM private key of the TLS certificate", func(config *serverConfig) *string { return &config.TLSKeyFile }),
+	{"max_request_body_bytes", "MAX_REQUEST_BODY_BYTES", "largest request body accepted", func(config *serverConfig, value string) error {
+		maxBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxBytes < 1 {
+			return fmt.Errorf("must be a positive number of bytes, got %q", value)
+		}
+		config.MaxRequestBodyBytes = maxBytes
+		return nil
+	}},
+	stringSetting("nominatim_url", "NOMINATIM_URL", "Nominatim instance attractions are geocoded with", func(config *serverConfig) *string { return &config.NominatimURL }),
+	durationSetting("geocoder_timeout", "GEOCODER_TIMEOUT", "longest time to wait on each geocoding search", func(config *serverConfig) *time.Duration { return &config.GeocoderTimeout }),
+	stringSetting("admin_token", "ADMIN_TOKEN", "bearer token enabling the admin endpoints", func(config *serverConfig) *string { return &config.AdminToken }),
+	stringSetting("redis_address", "REDIS_ADDR", "Redis server to cache distances in, instead of memory", func(config *serverConfig) *string { return &config.RedisAddress }),
+	stringSetting("osrm_url", "OSRM_URL", "OSRM server to estimate travel times with", func(config *serverConfig) *string { return &config.OSRMURL }),
+	stringSetting("osrm_profile", "OSRM_PROFILE", "OSRM profile travel times are estimated for", func(config *serverConfig) *string { return &config.OSRMProfile }),
+	{"callback_hosts", "CALLBACK_HOSTS", "comma-separated hosts job callbacks may be delivered to", func(config *serverConfig, value string) error {
+		config.CallbackHosts = nil
+		for _, host := range strings.Split(value, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				config.CallbackHosts = append(config.CallbackHosts, host)
+			}
+		}
+		return nil
+	}},
+	{"refresh_distances_on_start", "REFRESH_DISTANCES_ON_START", "rebuild the neighborhood distance table in the background on start", func(config *serverConfig, value string) error {
+		refresh, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+		config.RefreshDistancesOnStart = refresh
+		return nil
+	}},
+}
+
+func stringSetting(name string, env string, usage string, field func(config *serverConfig) *string) configSetting {
+	return configSetting{name, env, usage, func(config *serverConfig, value string) error {
+		*field(config) = value
+		return nil
+	}}
+}
+
+func durationSetting(name string, env string, usage string, field func(config *serverConfig) *time.Duration) configSetting {
+	return configSetting{name, env, usage, func(config *serverConfig, value string) error {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return fmt.Errorf("must be a non-negative duration (i.e, 30s), got %q", value)
+		}
+		*field(config) = duration
+		return nil
+	}}
+}
+
+func (setting configSetting) flagName() string {
+	return strings.Replace(setting.name, "_", "-", -1)
+}
+
+// configFlags are the flags overriding settings, registered before the command line is parsed.
+type configFlags struct {
+	flags    *flag.FlagSet
+	path     *string
+	settings map[string]*string
+}
+
+func registerConfigFlags(flags *flag.FlagSet) *configFlags {
+	registered := &configFlags{
+		flags:    flags,
+		path:     flags.String("config", os.Getenv("CONFIG_FILE"), "JSON file of server settings, keyed by the names of their flags with underscores"),
+		settings: make(map[string]*string),
+	}
+
+	for _, setting := range configSettings {
+		registered.settings[setting.name] = flags.String(setting.flagName(), "", setting.usage+" (env "+setting.env+")")
+	}
+
+	return registered
+}
+
+// Loads the config from the config file, the environment and the flags given on the command line.
+func (registered *configFlags) load(lookupEnv func(string) (string, bool)) (serverConfig, error) {
+	config := defaultServerConfig()
+	if *registered.path != "" {
+		if err := applyConfigFile(&config, *registered.path); err != nil {
+			return serverConfig{}, err
+		}
+	}
+
+	for _, setting := range configSettings {
+		if value, ok := lookupEnv(setting.env); ok && value != "" {
+			if err := setting.set(&config, value); err != nil {
+				return serverConfig{}, fmt.Errorf("%s %v", setting.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	registered.flags.Visit(func(f *flag.Flag) {
+		for _, setting := range configSettings {
+			if f.Name == setting.flagName() && flagErr == nil {
+				if err := setting.set(&config, *registered.settings[setting.name]); err != nil {
+					flagErr = fmt.Errorf("-%s %v", f.Name, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return serverConfig{}, flagErr
+	}
+
+	return config, config.validate()
+}
+
+// The config file is a JSON object of settings, i.e {"listen_address": ":8443", "read_timeout": "30s"}.
+func applyConfigFile(config *serverConfig, path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]json.RawMessage
+	if err := decodeStrictJSON(bytes.NewReader(contents), &values); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	for _, setting := range configSettings {
+		raw, ok := values[setting.name]
+		if !ok {
+			continue
+		}
+		delete(values, setting.name)
+
+		// Numbers may be given bare; everything else is a string.
+		value := string(raw)
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			value = s
+		}
+
+		if err := setting.set(config, value); err != nil {
+			return fmt.Errorf("%s: %s %v", path, setting.name, err)
+		}
+	}
+
+	for name := range values {
+		return fmt.Errorf("%s: unknown setting %q", path, name)
+	}
+
+	return nil
+}
+
+func (config serverConfig) validate() error {
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return errors.New("tls_cert_file and tls_key_file must be set together")
+	}
+
+	if config.ListenAddress == "" || config.GRPCAddress == "" {
+		return errors.New("listen_address and grpc_address must be set")
+	}
+
+	return nil
+}
+
+func (config serverConfig) tlsEnabled() bool {
+	return config.TLSCertFile != ""
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadTestConfig(t *testing.T, args []string, env map[string]string) (serverConfig, error) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	registered := registerConfigFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	return registered.load(func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	})
+}
+
+func TestLoadConfig_precedence(t *testing.T) {
+	path := writeConfigFile(t, `{"listen_address": ":1", "grpc_address": ":1", "read_timeout": "1s", "idle_timeout": "1s"}`)
+	env := map[string]string{"LISTEN_ADDR": ":2", "GRPC_ADDR": ":2", "READ_TIMEOUT": "2s"}
+
+	config, err := loadTestConfig(t, []string{"-config", path, "-listen-address", ":3"}, env)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	tests := []struct {
+		setting  string
+		got      interface{}
+		expected interface{}
+	}{
+		{"listen_address (flag over env and file)", config.ListenAddress, ":3"},
+		{"grpc_address (env over file)", config.GRPCAddress, ":2"},
+		{"read_timeout (env over file)", config.ReadTimeout, 2 * time.Second},
+		{"idle_timeout (file over default)", config.IdleTimeout, time.Second},
+		{"write_timeout (default)", config.WriteTimeout, defaultServerConfig().WriteTimeout},
+	}
+	for _, test := range tests {
+		if test.got != test.expected {
+			t.Errorf("%s: Expected %v. Got: %v.", test.setting, test.expected, test.got)
+		}
+	}
+}
+
+func TestLoadConfig_emptyEnvIgnored(t *testing.T) {
+	config, err := loadTestConfig(t, nil, map[string]string{"LISTEN_ADDR": ""})
+
+	if err != nil || config.ListenAddress != defaultServerConfig().ListenAddress {
+		t.Errorf("Expected the default listen address. Got: %q, %v.", config.ListenAddress, err)
+	}
+}
+
+func TestLoadConfig_invalidEnv(t *testing.T) {
+	_, err := loadTestConfig(t, nil, map[string]string{"READ_TIMEOUT": "soon"})
+
+	if err == nil {
+		t.Errorf("An invalid READ_TIMEOUT should have been rejected.")
+	}
+}
+
+func TestApplyConfigFile_unknownSetting(t *testing.T) {
+	config := defaultServerConfig()
+
+	err := applyConfigFile(&config, writeConfigFile(t, `{"listen_address": ":1", "listen_adress": ":2"}`))
+
+	if err == nil {
+		t.Errorf("An unknown setting should have been rejected.")
+	}
+}
+
+func TestApplyConfigFile_bareNumber(t *testing.T) {
+	config := defaultServerConfig()
+
+	err := applyConfigFile(&config, writeConfigFile(t, `{"max_request_body_bytes": 1024}`))
+
+	if err != nil || config.MaxRequestBodyBytes != 1024 {
+		t.Errorf("Expected 1024 bytes. Got: %d, %v.", config.MaxRequestBodyBytes, err)
+	}
+}
@@ -0,0 +1,715 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"../pkg/api"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Queries nesting deeper than this (i.e, following parent after parent) are rejected.
+const graphqlMaxDepth = 12
+
+// Each plan geocodes its attractions through Nominatim, so a request may only plan once however many
+// aliased plan fields it has.
+const graphqlMaxPlansPerRequest = 1
+
+// graphqlPlansKey holds the number of plans resolved for the request, as an *int32.
+type graphqlPlansKey struct{}
+
+// graphqlSchema lets clients pick the slices of the API they need: geometries and listings are only
+// looked up when asked for.
+const graphqlSchema = `
+schema {
+    query: Query
+}
+
+type Query {
+    # Every city with active neighborhoods.
+    cities: [City!]!
+    # A city's active neighborhoods. An omitted state matches the city in any state.
+    neighborhoods(city: String!, state: String): [Neighborhood!]!
+    # The neighborhood with the id, active or not.
+    neighborhood(id: ID!): Neighborhood
+    # Finds the best neighborhood for the attractions, as POST /v1/attractions does. Only one plan may be
+    # requested per query.
+    plan(attractions: [AttractionInput!]!, preferences: PreferencesInput): Plan!
+}
+
+type City {
+    name: String!
+    stateOrProvinceName: String!
+    country: String!
+    neighborhoodCount: Int!
+    extent: BoundingBox!
+    neighborhoods: [Neighborhood!]!
+}
+
+type BoundingBox {
+    minLatitude: Float!
+    minLongitude: Float!
+    maxLatitude: Float!
+    maxLongitude: Float!
+}
+
+type Neighborhood {
+    id: ID!
+    name: String!
+    cityName: String!
+    stateOrProvinceName: String!
+    country: String!
+    # The centroid or, for a plan's neighborhoods, the center distances were measured from.
+    latitude: Float!
+    longitude: Float!
+    level: AreaLevel!
+    # The area containing the neighborhood, if any.
+    parent: Neighborhood
+    # The boundary as a GeoJSON geometry.
+    geometry: String
+    # Null when the listings table does not exist.
+    listingSummary: ListingSummary
+    # Listings within the neighborhood, cheapest first.
+    listings(limit: Int = 20): [Listing!]!
+}
+
+type ListingSummary {
+    count: Int!
+    medianPrice: Float
+}
+
+type Listing {
+    id: ID!
+    price: Float
+    roomType: String
+    propertyType: String
+    latitude: Float!
+    longitude: Float!
+}
+
+input AttractionInput {
+    name: String
+    city: String
+    stateOrProvinceName: String
+    country: String
+    # Attractions without coordinates are geocoded.
+    latitude: Float
+    longitude: Float
+    weight: Float
+    notes: String
+}
+
+input PreferencesInput {
+    nearCityBufferInMeters: Float
+    strategy: ScoringStrategy
+    granularity: AreaLevel
+}
+
+enum ScoringStrategy {
+    centroid
+    point_on_surface
+    population_weighted
+    listing_density_weighted
+    edge_distance
+}
+
+enum AreaLevel {
+    city
+    district
+    neighborhood
+    sub_neighborhood
+}
+
+type Plan {
+    # Null when no attraction was matched to a neighborhood.
+    bestNeighborhood: Neighborhood
+    successfulAttractions: [Attraction!]!
+    failedAttractions: [Attraction!]!
+    insideCityAttractions: [Attraction!]!
+    nearCityAttractions: [Attraction!]!
+    outsideCityAttractions: [Attraction!]!
+    # Every matched neighborhood, best first.
+    candidates: [Candidate!]!
+    # Why the best neighborhood was picked. Null when no attraction was matched.
+    explanation: Explanation
+    # Listings within the best neighborhood, cheapest first.
+    listings(limit: Int = 20): [Listing!]!
+}
+
+type Attraction {
+    name: String!
+    city: String!
+    stateOrProvinceName: String!
+    country: String!
+    latitude: Float!
+    longitude: Float!
+    # The neighborhood the attraction was matched to, if any.
+    neighborhood: Neighborhood
+    # covers or nearest.
+    matchType: String
+    distanceToNeighborhoodInMeters: Float
+}
+
+type Candidate {
+    neighborhood: Neighborhood!
+    matchedAttractions: Int!
+    # Finalists tie for the most matched attractions; only they are measured against each other.
+    finalist: Boolean!
+    # Measured for finalists only. Lower is better.
+    totalDistanceInMeters: Float
+    rank: Int!
+}
+
+type Explanation {
+    strategy: ScoringStrategy!
+    # How many matched attractions the best neighborhood has, out of matchedAttractions.
+    bestNeighborhoodAttractions: Int!
+    matchedAttractions: Int!
+    # Neighborhoods tied with the best for the most matched attractions, including it.
+    finalists: [Candidate!]!
+    # How the best neighborhood changes with each attraction left out.
+    attractions: [AttractionSensitivity!]!
+}
+
+type AttractionSensitivity {
+    attraction: Attraction!
+    # The best neighborhood without the attraction; null when no other attraction was matched.
+    bestNeighborhood: Neighborhood
+    pivotal: Boolean!
+    matchedAttractionsChange: Int!
+    totalDistanceChangeInMeters: Float
+}
+`
+
+var plannerGraphQLSchema = graphql.MustParseSchema(graphqlSchema, &graphqlQuery{}, graphql.MaxDepth(graphqlMaxDepth))
+
+// GraphQLRequest is the body of a POST /graphql request.
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// POST /graphql runs a query against graphqlSchema. Like any GraphQL server, errors in the query are
+// reported in the response's errors rather than by status.
+func graphqlHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var request GraphQLRequest
+	if err := decodeStrictJSON(r.Body, &request); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphqlPlansKey{}, new(int32))
+	response := plannerGraphQLSchema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+type graphqlQuery struct{}
+
+func (query *graphqlQuery) Cities() ([]*graphqlCity, error) {
+	cities, err := api.ListCities()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*graphqlCity, len(cities))
+	for i, city := range cities {
+		resolvers[i] = &graphqlCity{city}
+	}
+
+	return resolvers, nil
+}
+
+func (query *graphqlQuery) Neighborhoods(args struct {
+	City  string
+	State *string
+}) ([]*graphqlNeighborhood, error) {
+	var state string
+	if args.State != nil {
+		state = *args.State
+	}
+
+	return listGraphQLNeighborhoods(args.City, state)
+}
+
+func (query *graphqlQuery) Neighborhood(args struct{ ID graphql.ID }) (*graphqlNeighborhood, error) {
+	neighborhoodID, err := strconv.ParseInt(string(args.ID), 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	return findGraphQLNeighborhood(neighborhoodID)
+}
+
+type graphqlAttractionInput struct {
+	Name                *string
+	City                *string
+	StateOrProvinceName *string
+	Country             *string
+	Latitude            *float64
+	Longitude           *float64
+	Weight              *float64
+	Notes               *string
+}
+
+type graphqlPreferencesInput struct {
+	NearCityBufferInMeters *float64
+	Strategy               *string
+	Granularity            *string
+}
+
+func (query *graphqlQuery) Plan(ctx context.Context, args struct {
+	Attractions []graphqlAttractionInput
+	Preferences *graphqlPreferencesInput
+}) (*graphqlPlan, error) {
+	if plans, ok := ctx.Value(graphqlPlansKey{}).(*int32); ok && atomic.AddInt32(plans, 1) > graphqlMaxPlansPerRequest {
+		return nil, fmt.Errorf("At most %d plan may be requested per query.", graphqlMaxPlansPerRequest)
+	}
+
+	preferences := defaultPlanningPreferences()
+	if input := args.Preferences; input != nil {
+		var err error
+		preferences, err = newPlanningPreferences(input.NearCityBufferInMeters, stringValue(input.Strategy), stringValue(input.Granularity))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	attractions := make([]api.Attraction, len(args.Attractions))
+	for i, input := range args.Attractions {
+		attractions[i] = api.Attraction{
+			Name:                stringValue(input.Name),
+			City:                stringValue(input.City),
+			StateOrProvinceName: stringValue(input.StateOrProvinceName),
+			Country:             stringValue(input.Country),
+			Latitude:            floatValue(input.Latitude),
+			Longitude:           floatValue(input.Longitude),
+			Weight:              floatValue(input.Weight),
+			Notes:               stringValue(input.Notes),
+		}
+	}
+
+	candidates := &candidateRecorder{}
+	response, err := planAttractions(ctx, attractions, planningGeocoder, preferences, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphqlPlan{response: response, candidates: candidates.candidates, strategy: preferences.ScoringStrategy}, nil
+}
+
+// candidateRecorder keeps the scored candidates of a plan.
+type candidateRecorder struct {
+	noPlanningObserver
+	candidates []api.NeighborhoodCandidate
+}
+
+func (recorder *candidateRecorder) candidatesScored(candidates []api.NeighborhoodCandidate) {
+	recorder.candidates = candidates
+}
+
+type graphqlCity struct {
+	city api.CitySummary
+}
+
+func (city *graphqlCity) Name() string                { return city.city.City }
+func (city *graphqlCity) StateOrProvinceName() string { return city.city.StateOrProvinceName }
+func (city *graphqlCity) Country() string             { return city.city.Country }
+func (city *graphqlCity) NeighborhoodCount() int32    { return int32(city.city.NeighborhoodCount) }
+
+func (city *graphqlCity) Extent() *graphqlBoundingBox {
+	return &graphqlBoundingBox{city.city.Extent}
+}
+
+func (city *graphqlCity) Neighborhoods() ([]*graphqlNeighborhood, error) {
+	return listGraphQLNeighborhoods(city.city.City, city.city.StateOrProvinceName)
+}
+
+type graphqlBoundingBox struct {
+	box api.BoundingBox
+}
+
+func (box *graphqlBoundingBox) MinLatitude() float64  { return box.box.MinLatitude }
+func (box *graphqlBoundingBox) MinLongitude() float64 { return box.box.MinLongitude }
+func (box *graphqlBoundingBox) MaxLatitude() float64  { return box.box.MaxLatitude }
+func (box *graphqlBoundingBox) MaxLongitude() float64 { return box.box.MaxLongitude }
+
+// graphqlNeighborhood resolves a neighborhood's geometry and listings only when they are asked for.
+// geometry is set when the neighborhood was looked up with it.
+type graphqlNeighborhood struct {
+	neighborhood api.Neighborhood
+	geometry     json.RawMessage
+}
+
+func listGraphQLNeighborhoods(city string, stateOrProvinceName string) ([]*graphqlNeighborhood, error) {
+	neighborhoods, err := api.ListActiveNeighborhoods(city, stateOrProvinceName)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*graphqlNeighborhood, len(neighborhoods))
+	for i, neighborhood := range neighborhoods {
+		resolvers[i] = &graphqlNeighborhood{neighborhood: neighborhood.Neighborhood}
+	}
+
+	return resolvers, nil
+}
+
+// Returns nil when there is no such neighborhood.
+func findGraphQLNeighborhood(neighborhoodID int64) (*graphqlNeighborhood, error) {
+	feature, err := api.FindNeighborhoodFeature(neighborhoodID)
+	var noNeighborhood *api.NoNeighborhoodFoundError
+	if errors.As(err, &noNeighborhood) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphqlNeighborhood{feature.Properties, feature.Geometry}, nil
+}
+
+// Returns nil for the empty neighborhood (i.e, when no attraction was matched).
+func newGraphQLNeighborhood(neighborhood api.Neighborhood) *graphqlNeighborhood {
+	if neighborhood.ID == 0 {
+		return nil
+	}
+
+	return &graphqlNeighborhood{neighborhood: neighborhood}
+}
+
+func (neighborhood *graphqlNeighborhood) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(neighborhood.neighborhood.ID, 10))
+}
+
+func (neighborhood *graphqlNeighborhood) Name() string     { return neighborhood.neighborhood.Name }
+func (neighborhood *graphqlNeighborhood) CityName() string { return neighborhood.neighborhood.City }
+func (neighborhood *graphqlNeighborhood) StateOrProvinceName() string {
+	return neighborhood.neighborhood.StateOrProvinceName
+}
+func (neighborhood *graphqlNeighborhood) Country() string { return neighborhood.neighborhood.Country }
+func (neighborhood *graphqlNeighborhood) Latitude() float64 {
+	return neighborhood.neighborhood.Latitude
+}
+func (neighborhood *graphqlNeighborhood) Longitude() float64 {
+	return neighborhood.neighborhood.Longitude
+}
+
+// Neighborhoods predating levels are neighborhoods.
+func (neighborhood *graphqlNeighborhood) Level() string {
+	if neighborhood.neighborhood.Level == "" {
+		return string(api.NeighborhoodLevel)
+	}
+
+	return string(neighborhood.neighborhood.Level)
+}
+
+func (neighborhood *graphqlNeighborhood) Parent() (*graphqlNeighborhood, error) {
+	if neighborhood.neighborhood.ParentID == 0 {
+		return nil, nil
+	}
+
+	return findGraphQLNeighborhood(neighborhood.neighborhood.ParentID)
+}
+
+func (neighborhood *graphqlNeighborhood) Geometry() (*string, error) {
+	if neighborhood.geometry == nil {
+		feature, err := api.FindNeighborhoodFeature(neighborhood.neighborhood.ID)
+		if err != nil {
+			return nil, err
+		}
+		neighborhood.geometry = feature.Geometry
+	}
+
+	geometry := string(neighborhood.geometry)
+	return &geometry, nil
+}
+
+func (neighborhood *graphqlNeighborhood) ListingSummary() (*graphqlListingSummary, error) {
+	summary, err := api.SummarizeNeighborhoodListings(neighborhood.neighborhood.ID)
+	if err != nil || summary == nil {
+		return nil, err
+	}
+
+	return &graphqlListingSummary{*summary}, nil
+}
+
+func (neighborhood *graphqlNeighborhood) Listings(args struct{ Limit int32 }) ([]*graphqlListing, error) {
+	return findGraphQLListings(neighborhood.neighborhood.ID, args.Limit)
+}
+
+type graphqlListingSummary struct {
+	summary api.ListingSummary
+}
+
+func (summary *graphqlListingSummary) Count() int32          { return int32(summary.summary.Count) }
+func (summary *graphqlListingSummary) MedianPrice() *float64 { return summary.summary.MedianPrice }
+
+type graphqlListing struct {
+	listing api.Listing
+}
+
+func findGraphQLListings(neighborhoodID int64, limit int32) ([]*graphqlListing, error) {
+	if limit < 1 || limit > 100 {
+		return nil, errors.New("limit must be between 1 and 100")
+	}
+
+	listings, err := api.FindNeighborhoodListings(neighborhoodID, int(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*graphqlListing, len(listings))
+	for i, listing := range listings {
+		resolvers[i] = &graphqlListing{listing}
+	}
+
+	return resolvers, nil
+}
+
+func (listing *graphqlListing) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(listing.listing.ID, 10))
+}
+
+func (listing *graphqlListing) Price() *float64   { return listing.listing.Price }
+func (listing *graphqlListing) RoomType() *string { return optionalString(listing.listing.RoomType) }
+func (listing *graphqlListing) PropertyType() *string {
+	return optionalString(listing.listing.PropertyType)
+}
+func (listing *graphqlListing) Latitude() float64  { return listing.listing.Latitude }
+func (listing *graphqlListing) Longitude() float64 { return listing.listing.Longitude }
+
+type graphqlPlan struct {
+	response   AttractionsResponse
+	candidates []api.NeighborhoodCandidate
+	strategy   api.ScoringStrategy
+}
+
+func (plan *graphqlPlan) BestNeighborhood() *graphqlNeighborhood {
+	return newGraphQLNeighborhood(plan.response.ClosestNeighborhood)
+}
+
+func (plan *graphqlPlan) SuccessfulAttractions() []*graphqlAttraction {
+	return newGraphQLAttractions(plan.response.SuccessfulAttractions)
+}
+
+func (plan *graphqlPlan) FailedAttractions() []*graphqlAttraction {
+	return newGraphQLAttractions(plan.response.FailedAttractions)
+}
+
+func (plan *graphqlPlan) InsideCityAttractions() []*graphqlAttraction {
+	return newGraphQLAttractions(plan.response.InsideCityAttractions)
+}
+
+func (plan *graphqlPlan) NearCityAttractions() []*graphqlAttraction {
+	return newGraphQLAttractions(plan.response.NearCityAttractions)
+}
+
+func (plan *graphqlPlan) OutsideCityAttractions() []*graphqlAttraction {
+	return newGraphQLAttractions(plan.response.OutsideCityAttractions)
+}
+
+func (plan *graphqlPlan) Candidates() []*graphqlCandidate {
+	return newGraphQLCandidates(plan.candidates)
+}
+
+// The explanation is only analyzed when asked for, as it re-scores the candidates once per attraction.
+func (plan *graphqlPlan) Explanation() (*graphqlExplanation, error) {
+	if plan.response.ClosestNeighborhood.ID == 0 {
+		return nil, nil
+	}
+
+	analysis, err := api.AnalyzeSensitivity(plan.response.SuccessfulAttractions, plan.strategy)
+	var noNeighborhood *api.NoNeighborhoodFoundError
+	if errors.As(err, &noNeighborhood) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &graphqlExplanation{analysis, plan.strategy}, nil
+}
+
+func (plan *graphqlPlan) Listings(args struct{ Limit int32 }) ([]*graphqlListing, error) {
+	if plan.response.ClosestNeighborhood.ID == 0 {
+		return []*graphqlListing{}, nil
+	}
+
+	return findGraphQLListings(plan.response.ClosestNeighborhood.ID, args.Limit)
+}
+
+type graphqlAttraction struct {
+	attraction api.Attraction
+}
+
+func newGraphQLAttractions(attractions []api.Attraction) []*graphqlAttraction {
+	resolvers := make([]*graphqlAttraction, len(attractions))
+	for i, attraction := range attractions {
+		resolvers[i] = &graphqlAttraction{attraction}
+	}
+
+	return resolvers
+}
+
+func (attraction *graphqlAttraction) Name() string { return attraction.attraction.Name }
+func (attraction *graphqlAttraction) City() string { return attraction.attraction.City }
+func (attraction *graphqlAttraction) StateOrProvinceName() string {
+	return attraction.attraction.StateOrProvinceName
+}
+func (attraction *graphqlAttraction) Country() string    { return attraction.attraction.Country }
+func (attraction *graphqlAttraction) Latitude() float64  { return attraction.attraction.Latitude }
+func (attraction *graphqlAttraction) Longitude() float64 { return attraction.attraction.Longitude }
+
+func (attraction *graphqlAttraction) Neighborhood() *graphqlNeighborhood {
+	if attraction.attraction.NeighborhoodMatch == nil {
+		return nil
+	}
+
+	return newGraphQLNeighborhood(attraction.attraction.NeighborhoodMatch.Neighborhood)
+}
+
+func (attraction *graphqlAttraction) MatchType() *string {
+	if attraction.attraction.NeighborhoodMatch == nil {
+		return nil
+	}
+
+	matchType := string(attraction.attraction.NeighborhoodMatch.MatchType)
+	return &matchType
+}
+
+func (attraction *graphqlAttraction) DistanceToNeighborhoodInMeters() *float64 {
+	if attraction.attraction.NeighborhoodMatch == nil {
+		return nil
+	}
+
+	return &attraction.attraction.NeighborhoodMatch.DistanceInMeters
+}
+
+type graphqlCandidate struct {
+	candidate api.NeighborhoodCandidate
+}
+
+func newGraphQLCandidates(candidates []api.NeighborhoodCandidate) []*graphqlCandidate {
+	resolvers := make([]*graphqlCandidate, len(candidates))
+	for i, candidate := range candidates {
+		resolvers[i] = &graphqlCandidate{candidate}
+	}
+
+	return resolvers
+}
+
+func (candidate *graphqlCandidate) Neighborhood() *graphqlNeighborhood {
+	return &graphqlNeighborhood{neighborhood: candidate.candidate.Neighborhood}
+}
+
+func (candidate *graphqlCandidate) MatchedAttractions() int32 {
+	return int32(candidate.candidate.MatchedAttractions)
+}
+
+func (candidate *graphqlCandidate) Finalist() bool { return candidate.candidate.Finalist }
+
+func (candidate *graphqlCandidate) TotalDistanceInMeters() *float64 {
+	if !candidate.candidate.Finalist {
+		return nil
+	}
+
+	return &candidate.candidate.TotalDistanceInMeters
+}
+
+func (candidate *graphqlCandidate) Rank() int32 { return int32(candidate.candidate.Rank) }
+
+type graphqlExplanation struct {
+	analysis api.SensitivityAnalysis
+	strategy api.ScoringStrategy
+}
+
+func (explanation *graphqlExplanation) Strategy() string { return string(explanation.strategy) }
+
+func (explanation *graphqlExplanation) BestNeighborhoodAttractions() int32 {
+	return int32(explanation.analysis.Candidates[0].MatchedAttractions)
+}
+
+func (explanation *graphqlExplanation) MatchedAttractions() int32 {
+	return int32(len(explanation.analysis.Attractions))
+}
+
+func (explanation *graphqlExplanation) Finalists() []*graphqlCandidate {
+	var finalists []api.NeighborhoodCandidate
+	for _, candidate := range explanation.analysis.Candidates {
+		if candidate.Finalist {
+			finalists = append(finalists, candidate)
+		}
+	}
+
+	return newGraphQLCandidates(finalists)
+}
+
+func (explanation *graphqlExplanation) Attractions() []*graphqlAttractionSensitivity {
+	resolvers := make([]*graphqlAttractionSensitivity, len(explanation.analysis.Attractions))
+	for i, sensitivity := range explanation.analysis.Attractions {
+		resolvers[i] = &graphqlAttractionSensitivity{sensitivity}
+	}
+
+	return resolvers
+}
+
+type graphqlAttractionSensitivity struct {
+	sensitivity api.AttractionSensitivity
+}
+
+func (sensitivity *graphqlAttractionSensitivity) Attraction() *graphqlAttraction {
+	return &graphqlAttraction{sensitivity.sensitivity.Attraction}
+}
+
+func (sensitivity *graphqlAttractionSensitivity) BestNeighborhood() *graphqlNeighborhood {
+	return newGraphQLNeighborhood(sensitivity.sensitivity.BestNeighborhood)
+}
+
+func (sensitivity *graphqlAttractionSensitivity) Pivotal() bool {
+	return sensitivity.sensitivity.Pivotal
+}
+
+func (sensitivity *graphqlAttractionSensitivity) MatchedAttractionsChange() int32 {
+	return int32(sensitivity.sensitivity.MatchedAttractionsChange)
+}
+
+func (sensitivity *graphqlAttractionSensitivity) TotalDistanceChangeInMeters() *float64 {
+	return sensitivity.sensitivity.TotalDistanceChangeInMeters
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func floatValue(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+
+	return *f
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+
+	"../pkg/api"
+	"../pkg/plannerpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultGRPCAddress = ":9090"
+
+// plannerServer serves the Planner gRPC service from the same planning code as the HTTP API.
+type plannerServer struct {
+	plannerpb.UnimplementedPlannerServer
+}
+
+// Starts serving the Planner service in the background, over TLS when the HTTP API is. Requests are
+// limited to the same size as HTTP request bodies.
+func startGRPCServer(config serverConfig) (*grpc.Server, error) {
+	options := []grpc.ServerOption{grpc.MaxRecvMsgSize(int(config.MaxRequestBodyBytes))}
+	if config.tlsEnabled() {
+		creds, err := credentials.NewServerTLSFromFile(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", config.GRPCAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	s := grpc.NewServer(options...)
+	plannerpb.RegisterPlannerServer(s, &plannerServer{})
+
+	log.Printf("Serving gRPC on %s", config.GRPCAddress)
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped; having error: %v", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// FindBestNeighborhood plans the attractions as POST /attractions does.
+func (server *plannerServer) FindBestNeighborhood(
+	ctx context.Context,
+	request *plannerpb.FindBestNeighborhoodRequest) (*plannerpb.AttractionsResponse, error) {
+	preferences, err := planningPreferencesFromProto(request.GetPreferences())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	responseAttractions, err := planAttractions(
+		ctx,
+		attractionsFromProto(request.GetAttractions()),
+		planningGeocoder,
+		preferences,
+		nil)
+	if err != nil {
+		return nil, planningStatus(err)
+	}
+
+	return attractionsResponseToProto(responseAttractions), nil
+}
+
+// ResolveAttractions plans the attractions as POST /attractions/stream does, sending each attraction as it
+// is located and classified, then the result.
+func (server *plannerServer) ResolveAttractions(
+	request *plannerpb.FindBestNeighborhoodRequest,
+	stream plannerpb.Planner_ResolveAttractionsServer) error {
+	preferences, err := planningPreferencesFromProto(request.GetPreferences())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resolutions := &resolutionStream{stream: stream}
+	responseAttractions, err := planAttractions(
+		stream.Context(),
+		attractionsFromProto(request.GetAttractions()),
+		planningGeocoder,
+		preferences,
+		resolutions)
+	if err != nil {
+		return planningStatus(err)
+	}
+
+	resolutions.send(&plannerpb.ResolveAttractionsResponse{
+		Event: &plannerpb.ResolveAttractionsResponse_Result{Result: attractionsResponseToProto(responseAttractions)},
+	})
+
+	return resolutions.err
+}
+
+// Maps why attractions could not be planned to a status, as writePlanningError does for HTTP: NOT_FOUND
+// when none could be matched to a neighborhood, CANCELLED when the client went away, otherwise INTERNAL.
+func planningStatus(err error) error {
+	var notFound *api.NoNeighborhoodFoundError
+	switch {
+	case errors.As(err, &notFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		log.Printf("Unable to plan attractions; having error: %v", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// resolutionStream sends planning progress on a ResolveAttractions stream.
+type resolutionStream struct {
+	stream plannerpb.Planner_ResolveAttractionsServer
+	// err is the first failed send. Once the client has gone away nothing more is sent, and planning stops
+	// as the stream's context is cancelled.
+	err error
+}
+
+func (resolutions *resolutionStream) attractionLocated(attraction api.Attraction, err error) {
+	resolution := &plannerpb.AttractionResolution{Attraction: attractionToProto(attraction)}
+	if err != nil {
+		resolution.Error = err.Error()
+	}
+	resolutions.sendResolution(resolution)
+}
+
+func (resolutions *resolutionStream) attractionClassified(attraction api.Attraction, classification api.CityBoundaryClassification) {
+	resolutions.sendResolution(&plannerpb.AttractionResolution{
+		Attraction:     attractionToProto(attraction),
+		Classification: string(classification),
+	})
+}
+
+func (resolutions *resolutionStream) sendResolution(resolution *plannerpb.AttractionResolution) {
+	resolutions.send(&plannerpb.ResolveAttractionsResponse{
+		Event: &plannerpb.ResolveAttractionsResponse_Resolution{Resolution: resolution},
+	})
+}
+
+func (resolutions *resolutionStream) send(response *plannerpb.ResolveAttractionsResponse) {
+	if resolutions.err != nil {
+		return
+	}
+
+	resolutions.err = resolutions.stream.Send(response)
+}
+
+func planningPreferencesFromProto(message *plannerpb.PlanningPreferences) (PlanningPreferences, error) {
+	if message == nil {
+		return defaultPlanningPreferences(), nil
+	}
+
+	return newPlanningPreferences(message.NearCityBufferInMeters, message.GetScoringStrategy(), message.GetGranularity())
+}
+
+// Only the fields a client supplies are read; geocoding and neighborhood matches are determined here.
+func attractionsFromProto(messages []*plannerpb.Attraction) []api.Attraction {
+	attractions := make([]api.Attraction, len(messages))
+	for i, message := range messages {
+		attractions[i] = api.Attraction{
+			Name:                message.GetName(),
+			City:                message.GetCity(),
+			StateOrProvinceName: message.GetStateOrProvinceName(),
+			Country:             message.GetCountry(),
+			Latitude:            message.GetLatitude(),
+			Longitude:           message.GetLongitude(),
+			Weight:              message.GetWeight(),
+			Notes:               message.GetNotes(),
+			CoordinatePrecision: int(message.GetCoordinatePrecision()),
+		}
+	}
+
+	return attractions
+}
+
+func attractionsResponseToProto(response AttractionsResponse) *plannerpb.AttractionsResponse {
+	message := &plannerpb.AttractionsResponse{
+		SuccessfulAttractions:  attractionsToProto(response.SuccessfulAttractions),
+		FailedAttractions:      attractionsToProto(response.FailedAttractions),
+		InsideCityAttractions:  attractionsToProto(response.InsideCityAttractions),
+		NearCityAttractions:    attractionsToProto(response.NearCityAttractions),
+		OutsideCityAttractions: attractionsToProto(response.OutsideCityAttractions),
+		ClosestNeighborhood:    neighborhoodToProto(response.ClosestNeighborhood),
+		Region:                 &plannerpb.Region{},
+	}
+
+	for _, city := range response.Region.Cities {
+		message.Region.Cities = append(message.Region.Cities, &plannerpb.RegionCity{
+			CityName:            city.City,
+			StateOrProvinceName: city.StateOrProvinceName,
+		})
+	}
+
+	for _, city := range response.Cities {
+		message.Cities = append(message.Cities, &plannerpb.CityStatistics{
+			CityName:                    city.City,
+			StateOrProvinceName:         city.StateOrProvinceName,
+			SuccessfulAttractions:       int32(city.SuccessfulAttractions),
+			FailedAttractions:           int32(city.FailedAttractions),
+			InsideCityAttractions:       int32(city.InsideCityAttractions),
+			NearCityAttractions:         int32(city.NearCityAttractions),
+			OutsideCityAttractions:      int32(city.OutsideCityAttractions),
+			MatchedNeighborhoods:        int32(city.MatchedNeighborhoods),
+			CrossCityMatches:            int32(city.CrossCityMatches),
+			ContainsClosestNeighborhood: city.ContainsClosestNeighborhood,
+		})
+	}
+
+	for _, dataset := range response.Datasets {
+		message.Datasets = append(message.Datasets, &plannerpb.Dataset{
+			Id:         dataset.ID,
+			Name:       dataset.Name,
+			Version:    dataset.Version,
+			Source:     dataset.Source,
+			License:    dataset.License,
+			ImportedAt: timestamppb.New(dataset.ImportedAt),
+			Active:     dataset.Active,
+		})
+	}
+
+	return message
+}
+
+func attractionsToProto(attractions []api.Attraction) []*plannerpb.Attraction {
+	messages := make([]*plannerpb.Attraction, len(attractions))
+	for i, attraction := range attractions {
+		messages[i] = attractionToProto(attraction)
+	}
+
+	return messages
+}
+
+func attractionToProto(attraction api.Attraction) *plannerpb.Attraction {
+	message := &plannerpb.Attraction{
+		Name:                attraction.Name,
+		City:                attraction.City,
+		StateOrProvinceName: attraction.StateOrProvinceName,
+		Country:             attraction.Country,
+		Latitude:            attraction.Latitude,
+		Longitude:           attraction.Longitude,
+		Weight:              attraction.Weight,
+		Notes:               attraction.Notes,
+		CoordinatePrecision: int32(attraction.CoordinatePrecision),
+	}
+
+	if geocoding := attraction.Geocoding; geocoding != nil {
+		message.Geocoding = &plannerpb.GeocodingResult{
+			Provider:         geocoding.Provider,
+			Candidate:        geocodingCandidateToProto(geocoding.GeocodingCandidate),
+			Ambiguous:        geocoding.Ambiguous,
+			Suspicious:       geocoding.Suspicious,
+			SuspiciousReason: geocoding.SuspiciousReason,
+		}
+		for _, alternative := range geocoding.Alternatives {
+			message.Geocoding.Alternatives = append(message.Geocoding.Alternatives, geocodingCandidateToProto(alternative))
+		}
+	}
+
+	if match := attraction.NeighborhoodMatch; match != nil {
+		message.NeighborhoodMatch = &plannerpb.NeighborhoodMatch{
+			Neighborhood:     neighborhoodToProto(match.Neighborhood),
+			MatchType:        string(match.MatchType),
+			DistanceInMeters: match.DistanceInMeters,
+		}
+	}
+
+	return message
+}
+
+func geocodingCandidateToProto(candidate api.GeocodingCandidate) *plannerpb.GeocodingCandidate {
+	message := &plannerpb.GeocodingCandidate{
+		DisplayName: candidate.DisplayName,
+		Latitude:    candidate.Latitude,
+		Longitude:   candidate.Longitude,
+		Confidence:  candidate.Confidence,
+	}
+
+	if box := candidate.BoundingBox; box != nil {
+		message.BoundingBox = &plannerpb.BoundingBox{
+			MinLatitude:  box.MinLatitude,
+			MinLongitude: box.MinLongitude,
+			MaxLatitude:  box.MaxLatitude,
+			MaxLongitude: box.MaxLongitude,
+		}
+	}
+
+	return message
+}
+
+func neighborhoodToProto(neighborhood api.Neighborhood) *plannerpb.Neighborhood {
+	message := &plannerpb.Neighborhood{
+		Id:                  neighborhood.ID,
+		Name:                neighborhood.Name,
+		CityName:            neighborhood.City,
+		StateOrProvinceName: neighborhood.StateOrProvinceName,
+		Country:             neighborhood.Country,
+		Latitude:            neighborhood.Latitude,
+		Longitude:           neighborhood.Longitude,
+		ParentId:            neighborhood.ParentID,
+		Level:               string(neighborhood.Level),
+		DatasetId:           neighborhood.DatasetID,
+	}
+
+	for _, ancestor := range neighborhood.Ancestors {
+		message.Ancestors = append(message.Ancestors, neighborhoodToProto(ancestor))
+	}
+
+	return message
+}
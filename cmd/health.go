@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"../pkg/api"
+	"../pkg/metrics"
+)
+
+// HealthResponse is returned while the server is up, whether or not it can plan.
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// Registers the probes and metrics, which are neither versioned nor included in the request metrics.
+func registerOperationalRoutes(routes *http.ServeMux) {
+	routes.HandleFunc("/healthz", healthHandler)
+	routes.HandleFunc("/readyz", readinessHandler)
+	routes.Handle("/metrics", metrics.Handler())
+}
+
+// GET /healthz reports that the server is up.
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HealthResponse{"ok"})
+}
+
+// GET /readyz reports whether the database can be planned against, responding 503 Service Unavailable
+// until it is reachable, has PostGIS and has neighborhood boundaries loaded.
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	readiness, err := api.CheckReadiness()
+	if err != nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(readiness)
+}
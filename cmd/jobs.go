@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"../pkg/api"
+)
+
+const jobsPath = "/jobs"
+
+// Finished jobs can be polled for this long.
+const jobRetention = time.Hour
+
+// Planning is mostly spent waiting on Nominatim, whose usage policy allows about one request a second, so
+// only a few jobs run at once; the rest wait their turn.
+const maxConcurrentJobs = 2
+
+// Jobs waiting or running beyond this many are refused with 503 Service Unavailable, rather than queued.
+const maxPendingJobs = 100
+
+const callbackTimeout = 10 * time.Second
+
+var (
+	errJobQueueFull     = errors.New("Too many jobs are waiting to be planned; try again later.")
+	errJobsShuttingDown = errors.New("The server is shutting down; no more jobs are accepted.")
+)
+
+// callbackHosts, when set, are the only hosts job callbacks are delivered to. Otherwise callbacks are
+// delivered to any host outside loopback, private and link-local networks.
+var callbackHosts []string
+
+// callbackClient refuses to connect to addresses callbacks may not be delivered to, whatever the callback
+// URL's host resolves to, and does not follow redirects.
+var callbackClient = &http.Client{
+	Timeout: callbackTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{Timeout: callbackTimeout, Control: restrictCallbackAddress}).DialContext,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// JobStatus is the state of a planning job.
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobProgress counts the attractions planned so far.
+type JobProgress struct {
+	TotalAttractions int `json:"total_attractions"`
+	// GeocodedAttractions have been located, successfully or not.
+	GeocodedAttractions int `json:"geocoded_attractions"`
+	FailedAttractions   int `json:"failed_attractions"`
+	// ResolvedNeighborhoods counts located attractions which have been matched to a neighborhood (or found
+	// to be outside every city).
+	ResolvedNeighborhoods int `json:"resolved_neighborhoods"`
+}
+
+// PlanningJob is an /attractions request planned in the background.
+type PlanningJob struct {
+	ID          string               `json:"id"`
+	Status      JobStatus            `json:"status"`
+	Progress    JobProgress          `json:"progress"`
+	Result      *AttractionsResponse `json:"result,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	CallbackURL string               `json:"callback_url,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+}
+
+// jobStore holds every job until it has been finished for jobRetention.
+type jobStore struct {
+	mutex sync.Mutex
+	jobs  map[string]*PlanningJob
+	// pending counts the jobs queued or running.
+	pending int
+	// stopping is closed once the store stops starting jobs; queued jobs then fail instead.
+	stopping chan struct{}
+	stopped  bool
+	// ctx is cancelled to stop the jobs still planning (and delivering callbacks).
+	ctx     context.Context
+	cancel  context.CancelFunc
+	slots   chan struct{}
+	running sync.WaitGroup
+}
+
+var planningJobs = newJobStore()
+
+func newJobStore() *jobStore {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &jobStore{
+		jobs:     make(map[string]*PlanningJob),
+		stopping: make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+		slots:    make(chan struct{}, maxConcurrentJobs),
+	}
+}
+
+// Queues a job planning the attractions, returning a copy of it as queued. errJobQueueFull is returned
+// when maxPendingJobs are already queued or running, and errJobsShuttingDown once the store is stopped.
+func (store *jobStore) submit(
+	attractions []api.Attraction,
+	preferences PlanningPreferences,
+	callbackURL string) (PlanningJob, error) {
+	id, err := newJobID()
+	if err != nil {
+		return PlanningJob{}, err
+	}
+
+	job := &PlanningJob{
+		ID:          id,
+		Status:      JobQueued,
+		Progress:    JobProgress{TotalAttractions: len(attractions)},
+		CallbackURL: callbackURL,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	store.mutex.Lock()
+	store.pruneLocked()
+	if store.stopped {
+		store.mutex.Unlock()
+		return PlanningJob{}, errJobsShuttingDown
+	}
+	if store.pending >= maxPendingJobs {
+		store.mutex.Unlock()
+		return PlanningJob{}, errJobQueueFull
+	}
+	store.pending++
+	store.jobs[id] = job
+	snapshot := *job
+	store.mutex.Unlock()
+
+	store.running.Add(1)
+	go store.run(job, attractions, preferences)
+
+	return snapshot, nil
+}
+
+// Returns a copy of the job, if known.
+func (store *jobStore) get(id string) (PlanningJob, bool) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	job, ok := store.jobs[id]
+	if !ok {
+		return PlanningJob{}, false
+	}
+
+	return *job, true
+}
+
+// Stops starting jobs: queued jobs fail, and new ones are refused, while running jobs carry on.
+func (store *jobStore) stop() {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if !store.stopped {
+		store.stopped = true
+		close(store.stopping)
+	}
+}
+
+// Waits for running jobs to finish. Once the context is done, they are cancelled, which stops each before
+// geocoding its next attraction, and waited for still; the context's error is then returned.
+func (store *jobStore) wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		store.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		store.cancel()
+		<-finished
+		return ctx.Err()
+	}
+}
+
+func (store *jobStore) run(job *PlanningJob, attractions []api.Attraction, preferences PlanningPreferences) {
+	defer store.running.Done()
+
+	select {
+	case store.slots <- struct{}{}:
+		defer func() { <-store.slots }()
+	case <-store.stopping:
+		store.finish(job, nil, errJobsShuttingDown)
+		return
+	}
+
+	store.update(job, func() { job.Status = JobRunning })
+
+	result, err := planAttractions(
+		store.ctx,
+		attractions,
+		planningGeocoder,
+		preferences,
+		&jobProgressObserver{store, job})
+	store.finish(job, &result, err)
+}
+
+// Records the job's result, or the error it failed with, then delivers it to its callback URL.
+func (store *jobStore) finish(job *PlanningJob, result *AttractionsResponse, err error) {
+	store.update(job, func() {
+		completedAt := time.Now().UTC()
+		job.CompletedAt = &completedAt
+		job.Result = result
+		job.Status = JobSucceeded
+		if err != nil {
+			job.Status = JobFailed
+			job.Error = err.Error()
+		}
+		store.pending--
+	})
+
+	if job.CallbackURL != "" {
+		finished, _ := store.get(job.ID)
+		if err := deliverJobCallback(store.ctx, finished); err != nil {
+			log.Printf("Unable to deliver job %s to %s; having error: %v", job.ID, job.CallbackURL, err)
+		}
+	}
+}
+
+func (store *jobStore) update(job *PlanningJob, change func()) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	change()
+}
+
+// Forgets jobs finished more than jobRetention ago. The mutex must be held.
+func (store *jobStore) pruneLocked() {
+	for id, job := range store.jobs {
+		if job.CompletedAt != nil && time.Since(*job.CompletedAt) > jobRetention {
+			delete(store.jobs, id)
+		}
+	}
+}
+
+// jobProgressObserver counts a job's progress as it is planned.
+type jobProgressObserver struct {
+	store *jobStore
+	job   *PlanningJob
+}
+
+func (observer *jobProgressObserver) attractionLocated(attraction api.Attraction, err error) {
+	observer.store.update(observer.job, func() {
+		observer.job.Progress.GeocodedAttractions++
+		if err != nil {
+			observer.job.Progress.FailedAttractions++
+		}
+	})
+}
+
+func (observer *jobProgressObserver) attractionClassified(attraction api.Attraction, classification api.CityBoundaryClassification) {
+	observer.store.update(observer.job, func() { observer.job.Progress.ResolvedNeighborhoods++ })
+}
+
+// POSTs the finished job as JSON to its callback URL.
+func deliverJobCallback(ctx context.Context, job PlanningJob) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, job.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := callbackClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("callback failed with status %s", response.Status)
+	}
+
+	return nil
+}
+
+func newJobID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+// Callback URLs must be absolute http(s) URLs, on one of callbackHosts if set. Otherwise, hosts given as
+// addresses must be public; names are checked as they are resolved, when the callback is delivered.
+func parseCallbackURL(callbackURL string) (string, error) {
+	if callbackURL == "" {
+		return "", nil
+	}
+
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("callback_url must be an absolute http or https URL, got %q", callbackURL)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if len(callbackHosts) > 0 {
+		for _, allowed := range callbackHosts {
+			if host == strings.ToLower(allowed) {
+				return parsed.String(), nil
+			}
+		}
+		return "", fmt.Errorf("callback_url must be on one of the hosts %s, got %q", strings.Join(callbackHosts, ", "), host)
+	}
+
+	isLocalhost := host == "localhost" || strings.HasSuffix(host, ".localhost")
+	if ip := net.ParseIP(host); isLocalhost || ip != nil && !isPublicAddress(ip) {
+		return "", fmt.Errorf("callback_url must not be a loopback, private or link-local address, got %q", host)
+	}
+
+	return parsed.String(), nil
+}
+
+// Refuses connections to non-public addresses, unless callbacks are restricted to callbackHosts, which
+// parseCallbackURL has already checked and which may deliberately be private.
+func restrictCallbackAddress(network string, address string, _ syscall.RawConn) error {
+	if len(callbackHosts) > 0 {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+		return fmt.Errorf("callbacks may not be delivered to %s", host)
+	}
+
+	return nil
+}
+
+func isPublicAddress(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// POST /jobs accepts the same body and query parameters as /attractions, plus an optional callback_url,
+// and replies 202 Accepted with the queued job. GET /jobs/<id> reports the job's progress and, once
+// finished, its result.
+func jobsHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, jobsPath), "/")
+	if id == "" {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		submitJob(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	job, ok := planningJobs.get(id)
+	if !ok {
+		writeErrorResponse(w, http.StatusNotFound, errors.New("No job with that id."))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func submitJob(w http.ResponseWriter, r *http.Request) {
+	preferences, err := parsePlanningPreferences(r.URL.Query())
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	callbackURL, err := parseCallbackURL(r.URL.Query().Get("callback_url"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	job, err := planningJobs.submit(attractions, preferences, callbackURL)
+	if err == errJobQueueFull || err == errJobsShuttingDown {
+		w.Header().Set("Retry-After", "60")
+		writeErrorResponse(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", apiVersionPrefix+jobsPath+"/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestJobStore_stoppedRefusesJobs(t *testing.T) {
+	store := newJobStore()
+	store.stop()
+
+	_, err := store.submit(nil, PlanningPreferences{}, "")
+
+	if err != errJobsShuttingDown {
+		t.Errorf("Expected %v. Got: %v.", errJobsShuttingDown, err)
+	}
+}
+
+func TestJobStore_stopFailsQueuedJobs(t *testing.T) {
+	store := newJobStore()
+	for i := 0; i < maxConcurrentJobs; i++ {
+		store.slots <- struct{}{}
+	}
+
+	queued, err := store.submit(nil, PlanningPreferences{}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+	store.stop()
+
+	if err := store.wait(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v.", err)
+	}
+
+	job, _ := store.get(queued.ID)
+	if job.Status != JobFailed || job.Error != errJobsShuttingDown.Error() {
+		t.Errorf("Expected the queued job to fail. Got: %+v.", job)
+	}
+
+	if store.pending != 0 {
+		t.Errorf("Expected no pending jobs. Got: %d.", store.pending)
+	}
+}
+
+func TestJobStore_queueFull(t *testing.T) {
+	store := newJobStore()
+	store.pending = maxPendingJobs
+
+	_, err := store.submit(nil, PlanningPreferences{}, "")
+
+	if err != errJobQueueFull {
+		t.Errorf("Expected %v. Got: %v.", errJobQueueFull, err)
+	}
+}
+
+func TestParseCallbackURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		accepted bool
+	}{
+		{"", true},
+		{"https://hooks.example.com/done", true},
+		{"http://203.0.113.7:8080/done", true},
+		{"http://[2001:db8::1]/done", true},
+		{"ftp://hooks.example.com/done", false},
+		{"/done", false},
+		{"http://localhost/done", false},
+		{"http://LOCALHOST./done", false},
+		{"http://api.localhost/done", false},
+		{"http://127.0.0.1/done", false},
+		{"http://127.1.2.3:9000/done", false},
+		{"http://10.0.0.5/done", false},
+		{"http://172.16.4.1/done", false},
+		{"http://192.168.1.1/done", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://0.0.0.0/done", false},
+		{"http://[::1]/done", false},
+		{"http://[fe80::1]/done", false},
+		{"http://[fd00::1]/done", false},
+		{"http://[::ffff:127.0.0.1]/done", false},
+	}
+
+	for _, test := range tests {
+		_, err := parseCallbackURL(test.url)
+
+		if (err == nil) != test.accepted {
+			t.Errorf("%q: Expected accepted to be %t. Got: %v.", test.url, test.accepted, err)
+		}
+	}
+}
+
+func TestParseCallbackURL_allowedHosts(t *testing.T) {
+	callbackHosts = []string{"hooks.internal", "10.0.0.5"}
+	defer func() { callbackHosts = nil }()
+
+	tests := []struct {
+		url      string
+		accepted bool
+	}{
+		{"http://hooks.internal/done", true},
+		{"http://HOOKS.INTERNAL:8080/done", true},
+		{"http://10.0.0.5/done", true},
+		{"https://hooks.example.com/done", false},
+		{"http://10.0.0.6/done", false},
+	}
+
+	for _, test := range tests {
+		_, err := parseCallbackURL(test.url)
+
+		if (err == nil) != test.accepted {
+			t.Errorf("%q: Expected accepted to be %t. Got: %v.", test.url, test.accepted, err)
+		}
+	}
+}
+
+func TestRestrictCallbackAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"203.0.113.7:443", true},
+		{"[2001:db8::1]:443", true},
+		{"127.0.0.1:80", false},
+		{"10.1.2.3:80", false},
+		{"192.168.0.10:80", false},
+		{"169.254.169.254:80", false},
+		{"[::1]:80", false},
+		{"[fe80::1]:80", false},
+		{"no-port", false},
+	}
+
+	for _, test := range tests {
+		err := restrictCallbackAddress("tcp", test.address, nil)
+
+		if (err == nil) != test.allowed {
+			t.Errorf("%s: Expected allowed to be %t. Got: %v.", test.address, test.allowed, err)
+		}
+	}
+}
+
+func TestRestrictCallbackAddress_allowedHostsMayBePrivate(t *testing.T) {
+	callbackHosts = []string{"hooks.internal"}
+	defer func() { callbackHosts = nil }()
+
+	if err := restrictCallbackAddress("tcp", "10.1.2.3:80", nil); err != nil {
+		t.Errorf("Expected a private address to be allowed. Got: %v.", err)
+	}
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.0.0.1", false},
+		{"172.31.255.255", false},
+		{"192.168.100.1", false},
+		{"169.254.1.1", false},
+		{"224.0.0.1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::1", false},
+		{"fe80::1", false},
+		{"fc00::1", false},
+		{"ff02::1", false},
+	}
+
+	for _, test := range tests {
+		if public := isPublicAddress(net.ParseIP(test.ip)); public != test.public {
+			t.Errorf("%s: Expected public to be %t. Got: %t.", test.ip, test.public, public)
+		}
+	}
+}
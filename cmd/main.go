@@ -1,77 +1,596 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"io/ioutil"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"../pkg/api"
-	"github.com/codingsince1985/geo-golang/openstreetmap"
+	"../pkg/cache"
+	"../pkg/metrics"
+	"github.com/codingsince1985/geo-golang"
+	"google.golang.org/grpc"
 )
 
 // AttractionsResponse demonstrates the components involved for API responses.
 type AttractionsResponse struct {
 	SuccessfulAttractions []api.Attraction `json:"successful_attractions"`
 	FailedAttractions     []api.Attraction `json:"failed_attractions"`
-	ClosestNeighborhood   api.Neighborhood `json:"closest_neighborhood"`
+	// Successfully geocoded attractions, by where they lie relative to their city's neighborhoods.
+	// Only inside and near city attractions contribute to the closest neighborhood.
+	InsideCityAttractions  []api.Attraction `json:"inside_city_attractions"`
+	NearCityAttractions    []api.Attraction `json:"near_city_attractions"`
+	OutsideCityAttractions []api.Attraction `json:"outside_city_attractions"`
+	ClosestNeighborhood    api.Neighborhood `json:"closest_neighborhood"`
+	// Region is every city the attractions lie in; neighborhoods of any of them may be matched.
+	Region api.Region           `json:"region"`
+	Cities []api.CityStatistics `json:"cities"`
+	// Datasets are the boundary dataset versions the recommendation was made from.
+	Datasets []api.Dataset `json:"datasets"`
 }
 
-func server() {
-    log.Println("Running on http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+// PlanningPreferences tune how attractions are matched to neighborhoods.
+type PlanningPreferences struct {
+	// NearCityBufferInMeters is how far outside its city's neighborhoods an attraction may lie and still
+	// be snapped to the nearest one.
+	NearCityBufferInMeters float64 `json:"near_city_buffer_in_meters"`
+	// ScoringStrategy selects how distances are measured between tied neighborhoods.
+	ScoringStrategy api.ScoringStrategy `json:"scoring_strategy"`
+	// Granularity is the level of area (i.e, district or neighborhood) the best area is picked from.
+	Granularity api.AreaLevel `json:"granularity"`
+}
+
+// ValidationErrorResponse lists every row of the submitted attractions which could not be used.
+type ValidationErrorResponse struct {
+	Errors []ValidationError `json:"errors"`
+}
+
+// ValidationError describes a single invalid row. Row is omitted for JSON requests.
+type ValidationError struct {
+	Row     int    `json:"row,omitempty"`
+	Message string `json:"message"`
+}
+
+// planningGeocoder locates attractions for every request; main configures it from the server config.
+var planningGeocoder geo.Geocoder = api.NewNominatimGeocoder(api.DefaultNominatimURL)
+
+// Defaults may be overridden by the environment, and then per request by query parameters.
+func defaultPlanningPreferences() PlanningPreferences {
+	preferences := PlanningPreferences{
+		NearCityBufferInMeters: api.DefaultNearCityBufferInMeters,
+		ScoringStrategy:        api.DefaultScoringStrategy,
+		Granularity:            api.DefaultAreaLevel,
+	}
+	if buffer, err := strconv.ParseFloat(os.Getenv("NEAR_CITY_BUFFER_METERS"), 64); err == nil {
+		preferences.NearCityBufferInMeters = buffer
+	}
+
+	return preferences
+}
+
+// Reads preferences from the request's query string, i.e,
+// ?near_city_buffer_in_meters=250&strategy=edge_distance&granularity=district.
+func parsePlanningPreferences(query url.Values) (PlanningPreferences, error) {
+	preferences := defaultPlanningPreferences()
+	if buffer := query.Get("near_city_buffer_in_meters"); buffer != "" {
+		parsedBuffer, err := strconv.ParseFloat(buffer, 64)
+		if err != nil || parsedBuffer < 0 {
+			return PlanningPreferences{}, fmt.Errorf("near_city_buffer_in_meters must be a non-negative number, got %q", buffer)
+		}
+		preferences.NearCityBufferInMeters = parsedBuffer
+	}
+
+	if strategy := query.Get("strategy"); strategy != "" {
+		parsedStrategy, err := api.ParseScoringStrategy(strategy)
+		if err != nil {
+			return PlanningPreferences{}, err
+		}
+		preferences.ScoringStrategy = parsedStrategy
+	}
+
+	if granularity := query.Get("granularity"); granularity != "" {
+		parsedGranularity, err := api.ParseAreaLevel(granularity)
+		if err != nil {
+			return PlanningPreferences{}, err
+		}
+		preferences.Granularity = parsedGranularity
+	}
+
+	return preferences, nil
+}
+
+// Builds preferences from values rather than query parameters (i.e, over gRPC or GraphQL). Unset values
+// take their defaults.
+func newPlanningPreferences(nearCityBufferInMeters *float64, strategy string, granularity string) (PlanningPreferences, error) {
+	preferences := defaultPlanningPreferences()
+	if nearCityBufferInMeters != nil {
+		if *nearCityBufferInMeters < 0 {
+			return PlanningPreferences{}, fmt.Errorf("near city buffer must be non-negative, got %v", *nearCityBufferInMeters)
+		}
+		preferences.NearCityBufferInMeters = *nearCityBufferInMeters
+	}
+
+	if strategy != "" {
+		parsedStrategy, err := api.ParseScoringStrategy(strategy)
+		if err != nil {
+			return PlanningPreferences{}, err
+		}
+		preferences.ScoringStrategy = parsedStrategy
+	}
+
+	if granularity != "" {
+		parsedGranularity, err := api.ParseAreaLevel(granularity)
+		if err != nil {
+			return PlanningPreferences{}, err
+		}
+		preferences.Granularity = parsedGranularity
+	}
+
+	return preferences, nil
+}
+
+// Serves the HTTP API and the gRPC service until SIGINT or SIGTERM. New requests are then refused while
+// in-flight requests and planning jobs are given up to the shutdown timeout to finish.
+func serve(config serverConfig) error {
+	// Cancelled when shutdown runs out of time, stopping requests still planning.
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	httpServer := &http.Server{
+		Addr:         config.ListenAddress,
+		Handler:      newRouter(config.AdminToken, config.MaxRequestBodyBytes),
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return requestsCtx },
+	}
+
+	grpcServer, err := startGRPCServer(config)
+	if err != nil {
+		return err
+	}
+
+	serveErrors := make(chan error, 1)
+	go func() {
+		if config.tlsEnabled() {
+			log.Printf("Running on https://%s", config.ListenAddress)
+			serveErrors <- httpServer.ListenAndServeTLS(config.TLSCertFile, config.TLSKeyFile)
+		} else {
+			log.Printf("Running on http://%s", config.ListenAddress)
+			serveErrors <- httpServer.ListenAndServe()
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case err := <-serveErrors:
+		grpcServer.Stop()
+		return err
+	case received := <-signals:
+		log.Printf("Received %v; finishing in-flight planning before shutting down", received)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	return shutdown(ctx, httpServer, grpcServer, cancelRequests)
+}
+
+// Stops starting queued planning jobs, then waits for in-flight HTTP and gRPC requests and running jobs
+// until the context is done. Whatever is still planning by then is cancelled.
+func shutdown(ctx context.Context, httpServer *http.Server, grpcServer *grpc.Server, cancelRequests context.CancelFunc) error {
+	planningJobs.stop()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	httpErr := httpServer.Shutdown(ctx)
+	if httpErr != nil {
+		cancelRequests()
+	}
+
+	jobsErr := planningJobs.wait(ctx)
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
+	if httpErr != nil || jobsErr != nil {
+		return errors.New("shutdown timed out before in-flight planning finished")
+	}
+
+	return nil
 }
 
 func main() {
-	http.HandleFunc("/attractions", handler)
-	server()
+	attractionsFile := flag.String("attractions", "", "plan from a CSV or JSON file of attractions and print the result instead of serving HTTP")
+	refreshDistances := flag.Bool("refresh-distances", false, "rebuild the precomputed neighborhood distance table and exit")
+	activateDataset := flag.Int64("activate-dataset", 0, "make the boundary dataset with this id the active version of its name and exit")
+	configFlags := registerConfigFlags(flag.CommandLine)
+	flag.Parse()
+
+	config, err := configFlags.load(os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	planningGeocoder = api.NewNominatimGeocoderWithTimeout(config.NominatimURL, config.GeocoderTimeout)
+	callbackHosts = config.CallbackHosts
+
+	if *activateDataset != 0 {
+		if err := api.ActivateDataset(*activateDataset); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Dataset %d activated", *activateDataset)
+		return
+	}
 
+	if *refreshDistances {
+		if err := api.RefreshNeighborhoodDistances(); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Neighborhood distances refreshed")
+		return
+	}
+
+	if *attractionsFile != "" {
+		if err := planFromFile(*attractionsFile); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var distanceCache cache.DistanceCache = cache.NewLRUDistanceCache(cache.DefaultLRUCapacity)
+	if config.RedisAddress != "" {
+		distanceCache = cache.NewRedisDistanceCache(config.RedisAddress, "neighborhood-distances:", cache.DefaultRedisTTL)
+	}
+	api.SetDistanceCache(metrics.InstrumentDistanceCache(distanceCache))
+
+	if config.OSRMURL != "" {
+		api.SetTravelTimeEstimator(api.NewOSRMRouter(config.OSRMURL, config.OSRMProfile))
+	}
+
+	if config.AdminToken == "" {
+		log.Println("No admin token is set; admin endpoints are disabled")
+	}
+
+	if config.RefreshDistancesOnStart {
+		go func() {
+			refreshed, err := api.RefreshNeighborhoodDistancesUnlessRunning()
+			if err != nil {
+				log.Printf("Unable to refresh neighborhood distances; having error: %v", err)
+				return
+			}
+			if !refreshed {
+				log.Println("Neighborhood distances are already being refreshed by another process")
+				return
+			}
+			log.Println("Neighborhood distances refreshed")
+		}()
+	}
+
+	if err := serve(config); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Shut down")
+}
+
+// Runs the planner once against a file and writes the response to stdout. Files ending in .csv are read
+// as CSV; anything else is expected to be a JSON array of attractions.
+func planFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	contentType := "application/json"
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		contentType = "text/csv"
+	}
+
+	attractions, err := decodeAttractions(f, contentType)
+	if err != nil {
+		return err
+	}
+
+	responseAttractions, err := planAttractions(context.Background(), attractions, planningGeocoder, defaultPlanningPreferences(), nil)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(responseAttractions)
 }
 
 func handler(w http.ResponseWriter, r *http.Request) {
-	jsn, err := ioutil.ReadAll(r.Body)
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	preferences, err := parsePlanningPreferences(r.URL.Query())
 	if err != nil {
-		log.Fatal("Error reading body", err)
+		writeDecodeError(w, err)
+		return
+	}
+
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	responseAttractions, err := planAttractions(r.Context(), attractions, planningGeocoder, preferences, nil)
+	if err != nil {
+		writePlanningError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseAttractions)
+}
+
+// Writes why attractions could not be planned: 422 Unprocessable Entity when none could be matched to a
+// neighborhood, or 500 Internal Server Error when planning itself failed (i.e, the database is down).
+// Nothing is written when planning stopped because the client went away.
+func writePlanningError(w http.ResponseWriter, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	var notFound *api.NoNeighborhoodFoundError
+	if errors.As(err, &notFound) {
+		writeErrorResponse(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	log.Printf("Unable to plan attractions; having error: %v", err)
+	writeErrorResponse(w, http.StatusInternalServerError, err)
+}
+
+// Reads attractions from either a JSON array or, for text/csv, a spreadsheet export with a header row.
+func decodeAttractions(body io.Reader, contentType string) ([]api.Attraction, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "text/csv" {
+		return api.ParseAttractionsCSV(body)
 	}
 
 	var attractions []api.Attraction
-	err = json.Unmarshal(jsn, &attractions)
+	if err := decodeStrictJSON(body, &attractions); err != nil {
+		return nil, err
+	}
+
+	return attractions, nil
+}
+
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if limit, tooLarge := requestBodyTooLarge(err); tooLarge {
+		writeErrorResponse(w, http.StatusRequestEntityTooLarge, fmt.Errorf("Request body must not exceed %d bytes.", limit))
+		return
+	}
+
+	var response ValidationErrorResponse
+	var rowErrors api.AttractionRowErrors
+	if errors.As(err, &rowErrors) {
+		for _, rowError := range rowErrors {
+			response.Errors = append(response.Errors, ValidationError{rowError.Row, rowError.Err.Error()})
+		}
+	} else {
+		response.Errors = []ValidationError{{Message: err.Error()}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(response)
+}
+
+func writeErrorResponse(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ValidationErrorResponse{[]ValidationError{{Message: err.Error()}}})
+}
+
+// planningObserver is told of planAttractions' progress, i.e, to report it to a client while planning.
+// Methods are called from the planning goroutine, in order.
+type planningObserver interface {
+	// attractionLocated is called once per attraction, with the error if it could not be located.
+	attractionLocated(attraction api.Attraction, err error)
+	// attractionClassified is called once per located attraction, after it is matched to a neighborhood.
+	attractionClassified(attraction api.Attraction, classification api.CityBoundaryClassification)
+}
+
+// candidateObserver is a planningObserver also told every candidate's score before the best neighborhood
+// is picked. Scoring candidates costs further queries, so only observers wanting them implement it.
+type candidateObserver interface {
+	candidatesScored(candidates []api.NeighborhoodCandidate)
+}
+
+type noPlanningObserver struct{}
+
+func (noPlanningObserver) attractionLocated(api.Attraction, error) {}
+
+func (noPlanningObserver) attractionClassified(api.Attraction, api.CityBoundaryClassification) {}
+
+// Locates each attraction (geocoding those without coordinates), maps it to a neighborhood of any city
+// in the trip's region and picks the best neighborhood overall. Attractions outside the region are
+// reported but not used. The observer, if any, is told of each step.
+func planAttractions(
+	ctx context.Context,
+	attractions []api.Attraction,
+	geocoder geo.Geocoder,
+	preferences PlanningPreferences,
+	observer planningObserver) (AttractionsResponse, error) {
+	if observer == nil {
+		observer = noPlanningObserver{}
+	}
+
+	attractions = append([]api.Attraction(nil), attractions...)
+	locateErrors, err := locateAttractions(ctx, attractions, geocoder, observer)
 	if err != nil {
-		log.Fatal("Decoding error", err)
+		return AttractionsResponse{}, err
+	}
+
+	var locatedAttractions, failedAttractions []api.Attraction
+	for i, attraction := range attractions {
+		if locateErrors[i] != nil {
+			failedAttractions = append(failedAttractions, attraction)
+		} else {
+			locatedAttractions = append(locatedAttractions, attraction)
+		}
 	}
 
+	return planLocatedAttractions(locatedAttractions, failedAttractions, preferences, observer)
+}
+
+// Locates the attractions in place, returning the error for each attraction which could not be located.
+// Once the context is done (i.e, the client went away), the rest are not geocoded and its error is
+// returned instead.
+func locateAttractions(
+	ctx context.Context,
+	attractions []api.Attraction,
+	geocoder geo.Geocoder,
+	observer planningObserver) ([]error, error) {
+	locateErrors := make([]error, len(attractions))
+	for i := range attractions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		attractions[i].NeighborhoodMatch = nil
+		locateErrors[i] = attractions[i].LocateAttraction(geocoder)
+		observer.attractionLocated(attractions[i], locateErrors[i])
+	}
+
+	return locateErrors, nil
+}
+
+// Plans already located attractions as planAttractions does. Failed attractions are only reported.
+func planLocatedAttractions(
+	locatedAttractions []api.Attraction,
+	failedAttractions []api.Attraction,
+	preferences PlanningPreferences,
+	observer planningObserver) (AttractionsResponse, error) {
 	var responseAttractions AttractionsResponse
+	var statistics api.RegionStatistics
+
+	responseAttractions.FailedAttractions = failedAttractions
+	for _, attraction := range failedAttractions {
+		statistics.AddFailed(attraction)
+	}
+
+	// Located attractions have their cities filled in, even those given only coordinates.
+	region := api.NewRegion(locatedAttractions)
+	responseAttractions.Region = region
+
+	datasets, err := api.FindActiveDatasets(region)
+	if err != nil {
+		return responseAttractions, err
+	}
+	responseAttractions.Datasets = datasets
+
+	// The region's extent spans every city with known neighborhoods.
+	var regionExtent api.BoundingBox
+	cityExtents := make(map[string]*api.BoundingBox)
+	for _, attraction := range locatedAttractions {
+		if extent := findCityExtent(cityExtents, attraction); extent != nil {
+			attraction.Geocoding.CheckAgainstExtent(attraction.City, *extent)
+			regionExtent = regionExtent.Union(*extent)
+		}
+	}
 
 	var neighborhoods []api.Neighborhood
-	geocoder := openstreetmap.Geocoder()
-	for _, attraction := range attractions {
-		attractionLocation, _ := attraction.GeocodeAttraction(geocoder)
+	var matchedAttractions []api.Attraction
+	for _, attraction := range locatedAttractions {
+		classification := api.OutsideCity
+		if regionExtent != (api.BoundingBox{}) {
+			var match api.NeighborhoodMatch
+			var err error
+			classification, match, err = api.ClassifyAttractionByRegionBoundary(
+				attraction,
+				region,
+				regionExtent,
+				preferences.NearCityBufferInMeters)
+			if err != nil {
+				return responseAttractions, err
+			}
 
-		if attractionLocation == nil {
-			responseAttractions.FailedAttractions = append(responseAttractions.FailedAttractions, attraction)
-			continue
+			if classification != api.OutsideCity {
+				match.Neighborhood = match.Neighborhood.AtLevel(preferences.Granularity)
+				attraction.NeighborhoodMatch = &match
+				neighborhoods = append(neighborhoods, match.Neighborhood)
+				matchedAttractions = append(matchedAttractions, attraction)
+			}
 		}
 
-		attraction.Latitude = attractionLocation.Lat
-		attraction.Longitude = attractionLocation.Lng
+		statistics.Add(attraction, classification)
+		observer.attractionClassified(attraction, classification)
 		responseAttractions.SuccessfulAttractions = append(responseAttractions.SuccessfulAttractions, attraction)
-		neighborhood, err := api.FindNeighborhoodContainingAttraction(attraction)
-		if err != nil {
-			log.Fatal(err)
-			continue
+		switch classification {
+		case api.InsideCity:
+			responseAttractions.InsideCityAttractions = append(responseAttractions.InsideCityAttractions, attraction)
+		case api.NearCity:
+			responseAttractions.NearCityAttractions = append(responseAttractions.NearCityAttractions, attraction)
+		default:
+			responseAttractions.OutsideCityAttractions = append(responseAttractions.OutsideCityAttractions, attraction)
+		}
+	}
+
+	if candidates, ok := observer.(candidateObserver); ok {
+		// Failures are left to FindBestNeighborhoodWithStrategy to report.
+		if scored, err := api.ScoreNeighborhoodCandidates(neighborhoods, matchedAttractions, preferences.ScoringStrategy); err == nil {
+			candidates.candidatesScored(scored)
 		}
+	}
 
-		neighborhoods = append(neighborhoods, neighborhood)
+	closestNeighborhood, err := api.FindBestNeighborhoodWithStrategy(
+		neighborhoods,
+		matchedAttractions,
+		preferences.ScoringStrategy)
+	responseAttractions.Cities = statistics.Cities(closestNeighborhood)
+	if err != nil {
+		return responseAttractions, err
 	}
 
-	closestNeighborhood, err := api.FindBestNeighborhood(neighborhoods)
+	responseAttractions.ClosestNeighborhood = closestNeighborhood
+	return responseAttractions, nil
+}
+
+// Looks up the extent of the attraction's city once per request. Returns nil when the city is unknown.
+func findCityExtent(cityExtents map[string]*api.BoundingBox, attraction api.Attraction) *api.BoundingBox {
+	key := strings.ToLower(attraction.City + "\x00" + attraction.StateOrProvinceName)
+	if extent, ok := cityExtents[key]; ok {
+		return extent
+	}
+
+	extent, err := api.FindCityExtent(attraction.City, attraction.StateOrProvinceName)
 	if err != nil {
-		log.Fatal(err)
-	} else {
-		responseAttractions.ClosestNeighborhood = closestNeighborhood
+		log.Printf("Unable to resolve extent of %s; having error: %v", attraction.City, err)
+		cityExtents[key] = nil
+		return nil
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(responseAttractions)
+	cityExtents[key] = &extent
+	return &extent
 }
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"../pkg/api"
+)
+
+// openAPIOperation describes one route of the /v1 API for the OpenAPI document. Request and response
+// schemas are generated from the Go types the handlers encode, so they follow their JSON tags.
+type openAPIOperation struct {
+	method     string
+	path       string
+	summary    string
+	parameters []openAPIParameter
+	// requestBody is nil for operations without a body. Attraction lists are also accepted as CSV.
+	requestBody     reflect.Type
+	acceptsCSV      bool
+	responseStatus  int
+	responseBody    reflect.Type
+	responseContent string
+	// admin operations require the admin bearer token.
+	admin bool
+}
+
+type openAPIParameter struct {
+	name        string
+	in          string
+	description string
+	required    bool
+	schema      map[string]interface{}
+}
+
+var (
+	attractionsType = reflect.TypeOf([]api.Attraction{})
+	stringSchema    = map[string]interface{}{"type": "string"}
+	numberSchema    = map[string]interface{}{"type": "number"}
+	integerSchema   = map[string]interface{}{"type": "integer", "format": "int64"}
+	idPathParameter = openAPIParameter{name: "id", in: "path", required: true, schema: integerSchema}
+	cityParameters  = []openAPIParameter{
+		{name: "city", in: "query", required: true, schema: stringSchema},
+		{name: "state", in: "query", schema: stringSchema},
+	}
+)
+
+// Values of the string types with a fixed set of values.
+var openAPIEnums = map[reflect.Type][]string{
+	reflect.TypeOf(api.ScoringStrategy("")): {
+		string(api.CentroidScoring),
+		string(api.PointOnSurfaceScoring),
+		string(api.PopulationWeightedScoring),
+		string(api.ListingDensityWeightedScoring),
+		string(api.EdgeDistanceScoring),
+	},
+	reflect.TypeOf(api.AreaLevel("")): {
+		string(api.CityLevel),
+		string(api.DistrictLevel),
+		string(api.NeighborhoodLevel),
+		string(api.SubNeighborhoodLevel),
+	},
+	reflect.TypeOf(api.CityBoundaryClassification("")): {
+		string(api.InsideCity),
+		string(api.NearCity),
+		string(api.OutsideCity),
+	},
+	reflect.TypeOf(api.NeighborhoodMatchType("")): {
+		string(api.CoveringNeighborhoodMatch),
+		string(api.NearestNeighborhoodMatch),
+	},
+	reflect.TypeOf(JobStatus("")): {
+		string(JobQueued),
+		string(JobRunning),
+		string(JobSucceeded),
+		string(JobFailed),
+	},
+}
+
+func planningParameters() []openAPIParameter {
+	return []openAPIParameter{
+		{name: "near_city_buffer_in_meters", in: "query", description: "How far outside every neighborhood an attraction may lie and still be matched.", schema: numberSchema},
+		{name: "strategy", in: "query", description: "How distances between tied neighborhoods are measured.", schema: enumSchema(reflect.TypeOf(api.ScoringStrategy("")))},
+		{name: "granularity", in: "query", description: "The level of area the best area is picked from.", schema: enumSchema(reflect.TypeOf(api.AreaLevel("")))},
+	}
+}
+
+func openAPIOperations() []openAPIOperation {
+	idPath := []openAPIParameter{idPathParameter}
+	return []openAPIOperation{
+		{method: http.MethodPost, path: "/attractions", summary: "Find the best neighborhood for the attractions.",
+			parameters: planningParameters(), requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(AttractionsResponse{})},
+		{method: http.MethodPost, path: "/attractions/stream", summary: "Plan the attractions, streaming progress as server-sent events.",
+			parameters: planningParameters(), requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusOK, responseContent: "text/event-stream"},
+		{method: http.MethodPost, path: "/attractions/sensitivity", summary: "Show how the best neighborhood changes with each attraction left out.",
+			parameters: planningParameters(), requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(SensitivityResponse{})},
+		{method: http.MethodGet, path: "/cities", summary: "List every city with known neighborhoods.",
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf([]api.CitySummary{})},
+		{method: http.MethodGet, path: "/neighborhoods", summary: "List a city's neighborhoods.",
+			parameters: cityParameters, responseStatus: http.StatusOK, responseBody: reflect.TypeOf([]api.NeighborhoodSummary{})},
+		{method: http.MethodGet, path: "/neighborhoods/lookup", summary: "Find the neighborhood containing a point.",
+			parameters: []openAPIParameter{
+				{name: "latitude", in: "query", required: true, schema: numberSchema},
+				{name: "longitude", in: "query", required: true, schema: numberSchema},
+			},
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(api.Neighborhood{})},
+		{method: http.MethodGet, path: "/neighborhoods/search", summary: "Find neighborhoods by name, tolerating typos.",
+			parameters: []openAPIParameter{
+				{name: "q", in: "query", required: true, schema: stringSchema},
+				{name: "city", in: "query", schema: stringSchema},
+				{name: "state", in: "query", schema: stringSchema},
+				{name: "limit", in: "query", schema: map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 100}},
+			},
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf([]api.NeighborhoodSearchResult{})},
+		{method: http.MethodPost, path: "/neighborhoods/compare", summary: "Compare shortlisted neighborhoods against the attractions.",
+			parameters: append(planningParameters(), openAPIParameter{
+				name: "neighborhood", in: "query", required: true, description: "Repeated for each neighborhood; at least two.",
+				schema: map[string]interface{}{"type": "array", "items": stringSchema, "minItems": 2}}),
+			requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(ComparisonResponse{})},
+		{method: http.MethodPost, path: "/trips", summary: "Save a trip.",
+			parameters:  append(planningParameters(), openAPIParameter{name: "name", in: "query", schema: stringSchema}),
+			requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusCreated, responseBody: reflect.TypeOf(api.Trip{})},
+		{method: http.MethodGet, path: "/trips/{id}", summary: "Get a trip with its latest plan.",
+			parameters: idPath, responseStatus: http.StatusOK, responseBody: reflect.TypeOf(api.Trip{})},
+		{method: http.MethodDelete, path: "/trips/{id}", summary: "Delete a trip.",
+			parameters: idPath, responseStatus: http.StatusNoContent},
+		{method: http.MethodPost, path: "/trips/{id}/attractions", summary: "Add attractions to a trip.",
+			parameters: idPath, requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusCreated, responseBody: reflect.TypeOf(api.Trip{})},
+		{method: http.MethodDelete, path: "/trips/{id}/attractions/{attraction_id}", summary: "Remove an attraction from a trip.",
+			parameters:     []openAPIParameter{idPathParameter, {name: "attraction_id", in: "path", required: true, schema: integerSchema}},
+			responseStatus: http.StatusNoContent},
+		{method: http.MethodPost, path: "/trips/{id}/evaluate", summary: "Plan a trip, geocoding only attractions not yet located.",
+			parameters: idPath, responseStatus: http.StatusOK, responseBody: reflect.TypeOf(AttractionsResponse{})},
+		{method: http.MethodPost, path: "/jobs", summary: "Plan the attractions in the background.",
+			parameters:  append(planningParameters(), openAPIParameter{name: "callback_url", in: "query", schema: map[string]interface{}{"type": "string", "format": "uri"}}),
+			requestBody: attractionsType, acceptsCSV: true,
+			responseStatus: http.StatusAccepted, responseBody: reflect.TypeOf(PlanningJob{})},
+		{method: http.MethodGet, path: "/jobs/{id}", summary: "Get a planning job's progress and result.",
+			parameters:     []openAPIParameter{{name: "id", in: "path", required: true, schema: stringSchema}},
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(PlanningJob{})},
+		{method: http.MethodPost, path: "/graphql", summary: "Run a GraphQL query against the cities, neighborhoods and plans.",
+			requestBody: reflect.TypeOf(GraphQLRequest{}), responseStatus: http.StatusOK, responseContent: "application/json"},
+		{method: http.MethodGet, path: adminNeighborhoodsPath, summary: "List a city's neighborhoods, active or not.", admin: true,
+			parameters: cityParameters, responseStatus: http.StatusOK, responseBody: reflect.TypeOf([]api.Neighborhood{})},
+		{method: http.MethodPost, path: adminNeighborhoodsPath, summary: "Create a neighborhood from a GeoJSON Feature.", admin: true,
+			requestBody:    reflect.TypeOf(api.NeighborhoodFeature{}),
+			responseStatus: http.StatusCreated, responseBody: reflect.TypeOf(api.NeighborhoodFeature{})},
+		{method: http.MethodGet, path: adminNeighborhoodsPath + "/{id}", summary: "Get a neighborhood as a GeoJSON Feature.", admin: true,
+			parameters: idPath, responseStatus: http.StatusOK, responseBody: reflect.TypeOf(api.NeighborhoodFeature{})},
+		{method: http.MethodPatch, path: adminNeighborhoodsPath + "/{id}", summary: "Rename a neighborhood.", admin: true,
+			parameters: idPath, requestBody: reflect.TypeOf(struct {
+				Name string `json:"name"`
+			}{}),
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(api.NeighborhoodFeature{})},
+		{method: http.MethodDelete, path: adminNeighborhoodsPath + "/{id}", summary: "Delete a neighborhood.", admin: true,
+			parameters: idPath, responseStatus: http.StatusNoContent},
+		{method: http.MethodPut, path: adminNeighborhoodsPath + "/{id}/geometry", summary: "Replace a neighborhood's boundary with a GeoJSON geometry.", admin: true,
+			parameters: idPath, requestBody: reflect.TypeOf(json.RawMessage{}),
+			responseStatus: http.StatusOK, responseBody: reflect.TypeOf(api.NeighborhoodFeature{})},
+	}
+}
+
+// openAPIDocument builds the OpenAPI 3 document describing the /v1 API.
+func openAPIDocument() map[string]interface{} {
+	schemas := openAPISchemas{components: make(map[string]interface{})}
+	errorResponse := schemas.schemaFor(reflect.TypeOf(ValidationErrorResponse{}))
+
+	paths := make(map[string]map[string]interface{})
+	for _, operation := range openAPIOperations() {
+		if paths[operation.path] == nil {
+			paths[operation.path] = make(map[string]interface{})
+		}
+
+		var parameters []interface{}
+		for _, parameter := range operation.parameters {
+			described := map[string]interface{}{
+				"name":     parameter.name,
+				"in":       parameter.in,
+				"required": parameter.required,
+				"schema":   parameter.schema,
+			}
+			if parameter.description != "" {
+				described["description"] = parameter.description
+			}
+			parameters = append(parameters, described)
+		}
+
+		response := map[string]interface{}{"description": http.StatusText(operation.responseStatus)}
+		switch {
+		case operation.responseContent != "":
+			response["content"] = map[string]interface{}{operation.responseContent: map[string]interface{}{}}
+		case operation.responseBody != nil:
+			response["content"] = jsonContent(schemas.schemaFor(operation.responseBody))
+		}
+
+		described := map[string]interface{}{
+			"summary": operation.summary,
+			"responses": map[string]interface{}{
+				strconv.Itoa(operation.responseStatus): response,
+				"default": map[string]interface{}{
+					"description": "The request could not be served.",
+					"content":     jsonContent(errorResponse),
+				},
+			},
+		}
+		if len(parameters) > 0 {
+			described["parameters"] = parameters
+		}
+
+		if operation.requestBody != nil {
+			content := jsonContent(schemas.schemaFor(operation.requestBody))
+			if operation.acceptsCSV {
+				content["text/csv"] = map[string]interface{}{"schema": stringSchema}
+			}
+			described["requestBody"] = map[string]interface{}{"required": true, "content": content}
+		}
+
+		if operation.admin {
+			described["security"] = []interface{}{map[string]interface{}{"adminToken": []string{}}}
+		}
+
+		paths[operation.path][strings.ToLower(operation.method)] = described
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Closest Airbnb to attractions finder",
+			"version": strings.TrimPrefix(apiVersionPrefix, "/"),
+		},
+		"servers": []interface{}{map[string]interface{}{"url": apiVersionPrefix}},
+		"paths":   paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]interface{}{
+				"adminToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+var (
+	openAPIDocumentOnce sync.Once
+	openAPIDocumentJSON []byte
+)
+
+// GET /v1/openapi.json serves the OpenAPI document, generated once.
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	openAPIDocumentOnce.Do(func() {
+		var err error
+		openAPIDocumentJSON, err = json.MarshalIndent(openAPIDocument(), "", "    ")
+		if err != nil {
+			log.Printf("Unable to encode OpenAPI document; having error: %v", err)
+		}
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIDocumentJSON)
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+func enumSchema(t reflect.Type) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "enum": openAPIEnums[t]}
+}
+
+// openAPISchemas generates JSON schemas from Go types as encoding/json would encode them. Named structs
+// become components, referenced by name.
+type openAPISchemas struct {
+	components map[string]interface{}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (schemas *openAPISchemas) schemaFor(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		// Any JSON value, i.e, a GeoJSON geometry.
+		return map[string]interface{}{}
+	}
+
+	if _, ok := openAPIEnums[t]; ok {
+		return enumSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := schemas.schemaFor(t.Elem())
+		if _, isReference := schema["$ref"]; isReference {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Struct:
+		if t.Name() == "" {
+			return schemas.structSchema(t)
+		}
+		if _, ok := schemas.components[t.Name()]; !ok {
+			// Registered before generating, so self-referencing types (i.e, ancestors) terminate.
+			schemas.components[t.Name()] = nil
+			schemas.components[t.Name()] = schemas.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemas.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemas.schemaFor(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// Describes the struct's JSON object. Fields of embedded structs without a JSON name are promoted, as
+// encoding/json does. No field is marked required: the same schemas describe requests, where most fields
+// are optional, and responses.
+func (schemas *openAPISchemas) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	schemas.addStructFields(t, properties)
+
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (schemas *openAPISchemas) addStructFields(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			schemas.addStructFields(field.Type, properties)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = schemas.schemaFor(field.Type)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"../pkg/metrics"
+)
+
+const apiVersionPrefix = "/v1"
+
+// Unless configured otherwise, request bodies larger than this are rejected with 413 Request Entity Too
+// Large. A CSV export of a few thousand attractions is well within it.
+const defaultMaxRequestBodyBytes = 4 << 20
+
+// Builds the routes served under /v1. The unversioned paths predating /v1 remain as deprecated aliases.
+// Admin routes are only registered when adminToken is set. Each route's latency is recorded under its
+// unversioned pattern.
+func newRouter(adminToken string, maxRequestBodyBytes int64) http.Handler {
+	routes := http.NewServeMux()
+	handle := func(pattern string, handler http.HandlerFunc) {
+		routes.Handle(pattern, metrics.InstrumentHandler(pattern, handler))
+	}
+	handle("/attractions", handler)
+	handle("/attractions/stream", streamHandler)
+	handle("/attractions/sensitivity", sensitivityHandler)
+	handle("/cities", citiesHandler)
+	handle("/neighborhoods", neighborhoodsHandler)
+	handle("/neighborhoods/lookup", neighborhoodLookupHandler)
+	handle("/neighborhoods/search", neighborhoodSearchHandler)
+	handle("/neighborhoods/compare", compareHandler)
+	handle(tripsPath, tripsHandler)
+	handle(tripsPath+"/", tripsHandler)
+	handle(jobsPath, jobsHandler)
+	handle(jobsPath+"/", jobsHandler)
+	handle("/graphql", graphqlHandler)
+	if adminToken != "" {
+		adminHandler := requireAdminToken(adminToken, adminNeighborhoodsHandler)
+		handle(adminNeighborhoodsPath, adminHandler)
+		handle(adminNeighborhoodsPath+"/", adminHandler)
+	}
+
+	versioned := http.NewServeMux()
+	versioned.HandleFunc(apiVersionPrefix+"/openapi.json", openAPIHandler)
+	versioned.Handle(apiVersionPrefix+"/", http.StripPrefix(apiVersionPrefix, routes))
+	versioned.Handle("/", deprecatedRoute(routes))
+	registerOperationalRoutes(versioned)
+
+	return limitRequestBody(versioned, maxRequestBodyBytes)
+}
+
+// Marks responses of unversioned paths as deprecated, pointing at their /v1 equivalent.
+func deprecatedRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+apiVersionPrefix+r.URL.Path+`>; rel="successor-version"`)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func limitRequestBody(next http.Handler, maxRequestBodyBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Decodes a single JSON value, rejecting fields the value does not have and anything following it.
+func decodeStrictJSON(body io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	if decoder.More() {
+		return errors.New("request body must contain a single JSON value")
+	}
+
+	return nil
+}
+
+// Returns the exceeded limit when reading the request body failed for being too large.
+func requestBodyTooLarge(err error) (int64, bool) {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return 0, false
+	}
+
+	return tooLarge.Limit, true
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"../pkg/api"
+)
+
+// SensitivityResponse is the sensitivity analysis along with the attractions left out of it: those which
+// could not be located, and those outside every city of the region.
+type SensitivityResponse struct {
+	api.SensitivityAnalysis
+	FailedAttractions      []api.Attraction `json:"failed_attractions"`
+	OutsideCityAttractions []api.Attraction `json:"outside_city_attractions"`
+}
+
+// POST /attractions/sensitivity plans the attractions as /attractions does, then reports how the best
+// neighborhood changes with each matched attraction left out.
+func sensitivityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	preferences, err := parsePlanningPreferences(r.URL.Query())
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	responseAttractions, err := planAttractions(r.Context(), attractions, planningGeocoder, preferences, nil)
+	if err != nil {
+		writePlanningError(w, err)
+		return
+	}
+
+	response := SensitivityResponse{
+		FailedAttractions:      responseAttractions.FailedAttractions,
+		OutsideCityAttractions: responseAttractions.OutsideCityAttractions,
+	}
+
+	var notFound *api.NoNeighborhoodFoundError
+	response.SensitivityAnalysis, err = api.AnalyzeSensitivity(responseAttractions.SuccessfulAttractions, preferences.ScoringStrategy)
+	if errors.As(err, &notFound) {
+		// Nothing was matched, so there is nothing to analyze.
+		err = nil
+	}
+	writeBrowseResult(w, response, err)
+}
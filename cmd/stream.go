@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"../pkg/api"
+)
+
+// Server-sent event names, in the order they are sent.
+const (
+	attractionLocatedEvent    = "attraction_located"
+	attractionClassifiedEvent = "attraction_classified"
+	candidatesEvent           = "candidates"
+	resultEvent               = "result"
+	errorEvent                = "error"
+)
+
+// AttractionEvent reports an attraction as it is located or matched to a neighborhood.
+type AttractionEvent struct {
+	Attraction api.Attraction `json:"attraction"`
+	// Error is set when the attraction could not be located.
+	Error          string                         `json:"error,omitempty"`
+	Classification api.CityBoundaryClassification `json:"classification,omitempty"`
+}
+
+// POST /attractions/stream plans attractions as /attractions does, streaming progress as server-sent
+// events: attraction_located and attraction_classified per attraction, the scored candidates, then the
+// result (or an error). Planning stops when the client disconnects.
+func streamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeErrorResponse(w, http.StatusInternalServerError, errors.New("Streaming is not supported."))
+		return
+	}
+
+	preferences, err := parsePlanningPreferences(r.URL.Query())
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	stream := &eventStream{w, flusher}
+	responseAttractions, err := planAttractions(
+		r.Context(),
+		attractions,
+		planningGeocoder,
+		preferences,
+		stream)
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		stream.send(errorEvent, ValidationErrorResponse{[]ValidationError{{Message: err.Error()}}})
+		return
+	}
+
+	stream.send(resultEvent, responseAttractions)
+}
+
+// eventStream writes planning progress as server-sent events.
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (stream *eventStream) attractionLocated(attraction api.Attraction, err error) {
+	event := AttractionEvent{Attraction: attraction}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	stream.send(attractionLocatedEvent, event)
+}
+
+func (stream *eventStream) attractionClassified(attraction api.Attraction, classification api.CityBoundaryClassification) {
+	stream.send(attractionClassifiedEvent, AttractionEvent{Attraction: attraction, Classification: classification})
+}
+
+func (stream *eventStream) candidatesScored(candidates []api.NeighborhoodCandidate) {
+	stream.send(candidatesEvent, candidates)
+}
+
+// Sends the data as a single line of JSON. Failed writes (i.e, the client went away) are ignored; planning
+// stops once the request's context is cancelled.
+func (stream *eventStream) send(event string, data interface{}) {
+	jsn, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Unable to encode %s event; having error: %v", event, err)
+		return
+	}
+
+	fmt.Fprintf(stream.w, "event: %s\ndata: %s\n\n", event, jsn)
+	stream.flusher.Flush()
+}
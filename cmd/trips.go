@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"../pkg/api"
+	"github.com/codingsince1985/geo-golang"
+)
+
+const tripsPath = "/trips"
+
+// Routes:
+//
+//	POST   /trips?name=<name>                   save a trip from the same body and parameters as /attractions
+//	GET    /trips/<id>                          get the trip with its latest plan
+//	DELETE /trips/<id>                          delete
+//	POST   /trips/<id>/attractions              add attractions, from a JSON array or CSV
+//	DELETE /trips/<id>/attractions/<attraction> remove an attraction
+//	POST   /trips/<id>/evaluate                 plan the trip, locating only attractions not yet located
+func tripsHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, tripsPath), "/")
+	if path == "" {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		createTrip(w, r)
+		return
+	}
+
+	segments := strings.Split(path, "/")
+	tripID, err := strconv.ParseInt(segments[0], 10, 64)
+	if err != nil {
+		writeErrorResponse(w, http.StatusNotFound, errors.New("Not found."))
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			trip, err := api.FindTrip(tripID)
+			writeTripResult(w, http.StatusOK, trip, err)
+		case http.MethodDelete:
+			if err := api.DeleteTrip(tripID); err != nil {
+				writeTripResult(w, http.StatusNoContent, nil, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeMethodNotAllowed(w, http.MethodGet, http.MethodDelete)
+		}
+	case len(segments) == 2 && segments[1] == "attractions":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		addTripAttractions(w, r, tripID)
+	case len(segments) == 3 && segments[1] == "attractions":
+		attractionID, err := strconv.ParseInt(segments[2], 10, 64)
+		if err != nil {
+			writeErrorResponse(w, http.StatusNotFound, errors.New("Not found."))
+			return
+		}
+		if r.Method != http.MethodDelete {
+			writeMethodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		if err := api.RemoveTripAttraction(tripID, attractionID); err != nil {
+			writeTripResult(w, http.StatusNoContent, nil, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(segments) == 2 && segments[1] == "evaluate":
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w, http.MethodPost)
+			return
+		}
+		evaluateTripHandler(w, r, tripID)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, errors.New("Not found."))
+	}
+}
+
+func createTrip(w http.ResponseWriter, r *http.Request) {
+	preferences, err := parsePlanningPreferences(r.URL.Query())
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	jsn, err := json.Marshal(preferences)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	trip, err := api.CreateTrip(r.URL.Query().Get("name"), attractions, jsn)
+	if err == nil {
+		w.Header().Set("Location", apiVersionPrefix+tripsPath+"/"+strconv.FormatInt(trip.ID, 10))
+	}
+	writeTripResult(w, http.StatusCreated, trip, err)
+}
+
+func addTripAttractions(w http.ResponseWriter, r *http.Request, tripID int64) {
+	attractions, err := decodeAttractions(r.Body, r.Header.Get("Content-Type"))
+	if err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	trip, err := api.AddTripAttractions(tripID, attractions)
+	writeTripResult(w, http.StatusCreated, trip, err)
+}
+
+func evaluateTripHandler(w http.ResponseWriter, r *http.Request, tripID int64) {
+	trip, err := api.FindTrip(tripID)
+	if err != nil {
+		writeTripResult(w, http.StatusOK, nil, err)
+		return
+	}
+
+	responseAttractions, err := evaluateTrip(r.Context(), trip, planningGeocoder)
+	writeTripResult(w, http.StatusOK, responseAttractions, err)
+}
+
+// Plans the trip with its saved preferences and saves the plan. Only attractions not yet located are
+// geocoded; those which cannot be are tried again on the next evaluation. When planning fails, newly
+// located attractions are still saved but the plan is not.
+func evaluateTrip(ctx context.Context, trip api.Trip, geocoder geo.Geocoder) (AttractionsResponse, error) {
+	preferences := defaultPlanningPreferences()
+	if err := json.Unmarshal(trip.Preferences, &preferences); err != nil {
+		return AttractionsResponse{}, err
+	}
+
+	var pendingAttractions []api.Attraction
+	var pendingIndexes []int
+	for i, attraction := range trip.Attractions {
+		if !attraction.Located {
+			pendingAttractions = append(pendingAttractions, attraction.Attraction)
+			pendingIndexes = append(pendingIndexes, i)
+		}
+	}
+
+	locateErrors, err := locateAttractions(ctx, pendingAttractions, geocoder, noPlanningObserver{})
+	if err != nil {
+		return AttractionsResponse{}, err
+	}
+
+	var newlyLocated []api.TripAttraction
+	for i, tripIndex := range pendingIndexes {
+		trip.Attractions[tripIndex].Attraction = pendingAttractions[i]
+		if locateErrors[i] == nil {
+			trip.Attractions[tripIndex].Located = true
+			newlyLocated = append(newlyLocated, trip.Attractions[tripIndex])
+		}
+	}
+
+	// Saved before planning, which flags suspicious geocodings against the boundaries of the day.
+	if err := api.SaveLocatedTripAttractions(trip.ID, newlyLocated); err != nil {
+		return AttractionsResponse{}, err
+	}
+
+	var locatedAttractions, failedAttractions []api.Attraction
+	for _, attraction := range trip.Attractions {
+		if attraction.Located {
+			locatedAttractions = append(locatedAttractions, attraction.Attraction)
+		} else {
+			failedAttractions = append(failedAttractions, attraction.Attraction)
+		}
+	}
+
+	responseAttractions, err := planLocatedAttractions(
+		locatedAttractions,
+		failedAttractions,
+		preferences,
+		noPlanningObserver{})
+	if err != nil {
+		return responseAttractions, err
+	}
+
+	jsn, err := json.Marshal(responseAttractions)
+	if err != nil {
+		return responseAttractions, err
+	}
+
+	return responseAttractions, api.SaveTripResult(trip.ID, jsn)
+}
+
+// Writes the result, or the error with a status matching its type.
+func writeTripResult(w http.ResponseWriter, status int, result interface{}, err error) {
+	var notFound *api.NoTripFoundError
+	var noNeighborhood *api.NoNeighborhoodFoundError
+	switch {
+	case err == nil:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(result)
+	case errors.As(err, &notFound):
+		writeErrorResponse(w, http.StatusNotFound, err)
+	case errors.As(err, &noNeighborhood):
+		writeErrorResponse(w, http.StatusUnprocessableEntity, err)
+	default:
+		writeErrorResponse(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+
+	"../connections"
+)
+
+// AreaLevel is how coarse an area is. Cities publishing nested boundaries store each level in the
+// neighborhoods table, linked to the area containing it by parent_gid.
+type AreaLevel string
+
+const (
+	// CityLevel areas outline a whole city.
+	CityLevel AreaLevel = "city"
+	// DistrictLevel areas group several neighborhoods (i.e, a borough or ward).
+	DistrictLevel AreaLevel = "district"
+	// NeighborhoodLevel areas are the localised communities of a city (i.e, 'Downtown').
+	NeighborhoodLevel AreaLevel = "neighborhood"
+	// SubNeighborhoodLevel areas divide a neighborhood (i.e, 'Yaletown' within Downtown).
+	SubNeighborhoodLevel AreaLevel = "sub_neighborhood"
+)
+
+// DefaultAreaLevel is the granularity at which the best area is picked when none is requested. Areas
+// without a level are neighborhoods.
+const DefaultAreaLevel = NeighborhoodLevel
+
+// Levels from coarsest to finest.
+var areaLevels = []AreaLevel{CityLevel, DistrictLevel, NeighborhoodLevel, SubNeighborhoodLevel}
+
+// ParseAreaLevel validates a level name, returning DefaultAreaLevel for an empty one.
+func ParseAreaLevel(name string) (AreaLevel, error) {
+	if name == "" {
+		return DefaultAreaLevel, nil
+	}
+
+	for _, level := range areaLevels {
+		if AreaLevel(name) == level {
+			return level, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown area level %q", name)
+}
+
+// Higher ranks are finer. Unknown levels rank as neighborhoods.
+func (level AreaLevel) rank() int {
+	for i, areaLevel := range areaLevels {
+		if level == areaLevel {
+			return i
+		}
+	}
+
+	return NeighborhoodLevel.rank()
+}
+
+// AtLevel returns the finest area of the containment chain (the area and its Ancestors) which is no finer
+// than the given level; i.e, the district containing a sub-neighborhood. The area itself is returned when
+// every area of the chain is finer.
+func (neighborhood Neighborhood) AtLevel(level AreaLevel) Neighborhood {
+	chain := append(append([]Neighborhood{}, neighborhood.Ancestors...), neighborhood)
+	chain[len(chain)-1].Ancestors = nil
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].Level.rank() <= level.rank() {
+			area := chain[i]
+			area.Ancestors = append([]Neighborhood(nil), chain[:i]...)
+			return area
+		}
+	}
+
+	return neighborhood
+}
+
+// Loads the areas containing the given one by following parent_gid, broadest first. Ancestors are
+// returned with their centroids as coordinates.
+func findAncestorAreas(neighborhood Neighborhood) ([]Neighborhood, error) {
+	if neighborhood.ParentID == 0 {
+		return nil, nil
+	}
+
+	ancestorsQuery := `
+    WITH RECURSIVE ancestors AS (
+        SELECT gid, parent_gid, name, city, state, country, level, geom, 0 as depth
+        FROM neighborhood_geocoding.neighborhoods
+        WHERE gid = $1
+        UNION ALL
+        SELECT parent.gid, parent.parent_gid, parent.name, parent.city, parent.state, parent.country, parent.level, parent.geom, ancestors.depth + 1
+        FROM neighborhood_geocoding.neighborhoods as parent
+        JOIN ancestors ON parent.gid = ancestors.parent_gid
+        WHERE ancestors.depth < 16
+    )
+    SELECT gid, coalesce(parent_gid, 0), name, city, state, country, coalesce(level, 'neighborhood'),
+        ST_X(ST_Centroid(geom)), ST_Y(ST_Centroid(geom))
+    FROM ancestors
+    ORDER BY depth DESC
+    `
+
+	rows, err := connections.Init().Query(ancestorsQuery, neighborhood.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ancestors []Neighborhood
+	for rows.Next() {
+		var ancestor Neighborhood
+		if err := rows.Scan(
+			&ancestor.ID,
+			&ancestor.ParentID,
+			&ancestor.Name,
+			&ancestor.City,
+			&ancestor.StateOrProvinceName,
+			&ancestor.Country,
+			&ancestor.Level,
+			&ancestor.Longitude,
+			&ancestor.Latitude); err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, ancestor)
+	}
+
+	return ancestors, rows.Err()
+}
@@ -0,0 +1,77 @@
+package api
+
+import "testing"
+
+var (
+	kitsilanoDistrict = Neighborhood{ID: 1, Name: "West Side", Level: DistrictLevel}
+	kitsilano         = Neighborhood{ID: 2, Name: "Kitsilano", Level: NeighborhoodLevel, ParentID: 1}
+	kitsPoint         = Neighborhood{
+		ID:        3,
+		Name:      "Kits Point",
+		Level:     SubNeighborhoodLevel,
+		ParentID:  2,
+		Ancestors: []Neighborhood{kitsilanoDistrict, kitsilano},
+	}
+)
+
+func TestParseAreaLevel_emptyNameUsesDefault(t *testing.T) {
+	level, err := ParseAreaLevel("")
+
+	if err != nil || level != DefaultAreaLevel {
+		t.Errorf("Expected the default level. Got: %s, %v.", level, err)
+	}
+}
+
+func TestParseAreaLevel_unknownLevel(t *testing.T) {
+	_, err := ParseAreaLevel("block")
+
+	if err == nil {
+		t.Errorf("An unknown level should have been rejected.")
+	}
+}
+
+func TestAtLevel_subNeighborhoodProjectedToNeighborhood(t *testing.T) {
+	area := kitsPoint.AtLevel(NeighborhoodLevel)
+
+	if area.ID != kitsilano.ID {
+		t.Errorf("Expected %s. Got: %s.", kitsilano.Name, area.Name)
+	}
+
+	if len(area.Ancestors) != 1 || area.Ancestors[0].ID != kitsilanoDistrict.ID {
+		t.Errorf("Expected only %s as an ancestor. Got: %+v.", kitsilanoDistrict.Name, area.Ancestors)
+	}
+}
+
+func TestAtLevel_subNeighborhoodProjectedToDistrict(t *testing.T) {
+	area := kitsPoint.AtLevel(DistrictLevel)
+
+	if area.ID != kitsilanoDistrict.ID || area.Ancestors != nil {
+		t.Errorf("Expected %s without ancestors. Got: %+v.", kitsilanoDistrict.Name, area)
+	}
+}
+
+func TestAtLevel_finestLevelKeepsArea(t *testing.T) {
+	area := kitsPoint.AtLevel(SubNeighborhoodLevel)
+
+	if area.ID != kitsPoint.ID || len(area.Ancestors) != 2 {
+		t.Errorf("Expected %s with both ancestors. Got: %+v.", kitsPoint.Name, area)
+	}
+}
+
+func TestAtLevel_noAreaCoarseEnough(t *testing.T) {
+	area := kitsPoint.AtLevel(CityLevel)
+
+	if area.ID != kitsPoint.ID {
+		t.Errorf("Expected %s when no area is as coarse as a city. Got: %s.", kitsPoint.Name, area.Name)
+	}
+}
+
+func TestAtLevel_areaWithoutLevelIsNeighborhood(t *testing.T) {
+	downtown := Neighborhood{ID: 4, Name: "Downtown"}
+
+	area := downtown.AtLevel(NeighborhoodLevel)
+
+	if area.ID != downtown.ID {
+		t.Errorf("Expected %s. Got: %s.", downtown.Name, area.Name)
+	}
+}
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"strings"
+	"time"
 
+	"../metrics"
 	"github.com/codingsince1985/geo-golang"
 )
 
@@ -13,10 +17,23 @@ type Attraction struct {
 	Name                string  `json:"name"`
 	City                string  `json:"city"`
 	StateOrProvinceName string  `json:"state_or_province_name"`
+	Country             string  `json:"country,omitempty"`
 	Latitude            float64 `json:"latitude"`
 	Longitude           float64 `json:"longitude"`
+	Weight              float64 `json:"weight,omitempty"`
+	Notes               string  `json:"notes,omitempty"`
+	// CoordinatePrecision is the number of decimal places client-supplied coordinates are rounded to.
+	// Zero leaves them untouched.
+	CoordinatePrecision int `json:"coordinate_precision,omitempty"`
+	// Geocoding describes how the coordinates were determined. It is replaced when the attraction is located.
+	Geocoding *GeocodingResult `json:"geocoding,omitempty"`
+	// NeighborhoodMatch is the neighborhood the attraction was matched to, if any. It is ignored on input.
+	NeighborhoodMatch *NeighborhoodMatch `json:"neighborhood_match,omitempty"`
 }
 
+// maxCoordinatePrecision is roughly the precision of a float64 coordinate (~0.1 nanometers).
+const maxCoordinatePrecision = 15
+
 // MissingAttractionKeyIdentifierError indicates a key identifying piece for the attractio is missing.
 type MissingAttractionKeyIdentifierError struct {
 	message string
@@ -26,23 +43,241 @@ func (e *MissingAttractionKeyIdentifierError) Error() string {
 	return e.message
 }
 
-// MergeAttractionNameCityAndState combines the name of the attraction, city, and state separated by a comma and space.
+// InvalidAttractionCoordinatesError indicates client-supplied coordinates are out of range.
+type InvalidAttractionCoordinatesError struct {
+	message string
+}
+
+func (e *InvalidAttractionCoordinatesError) Error() string {
+	return e.message
+}
+
+// UnresolvedAttractionLocationError indicates the geocoder could not find the attraction.
+type UnresolvedAttractionLocationError struct {
+	message string
+}
+
+func (e *UnresolvedAttractionLocationError) Error() string {
+	return e.message
+}
+
+// HasCoordinates reports whether the client supplied a location for the attraction. (0, 0) lies in the
+// Gulf of Guinea, so it is treated as "no coordinates given".
+func (attraction *Attraction) HasCoordinates() bool {
+	return attraction.Latitude != 0.0 || attraction.Longitude != 0.0
+}
+
+// ValidateCoordinates ensures supplied coordinates and their precision are within range.
+func (attraction *Attraction) ValidateCoordinates() error {
+	if attraction.Latitude < -90.0 || attraction.Latitude > 90.0 {
+		return &InvalidAttractionCoordinatesError{fmt.Sprintf("Latitude %f is not between -90 and 90.", attraction.Latitude)}
+	}
+
+	if attraction.Longitude < -180.0 || attraction.Longitude > 180.0 {
+		return &InvalidAttractionCoordinatesError{fmt.Sprintf("Longitude %f is not between -180 and 180.", attraction.Longitude)}
+	}
+
+	if attraction.CoordinatePrecision < 0 || attraction.CoordinatePrecision > maxCoordinatePrecision {
+		return &InvalidAttractionCoordinatesError{
+			fmt.Sprintf("Coordinate precision %d is not between 0 and %d.", attraction.CoordinatePrecision, maxCoordinatePrecision)}
+	}
+
+	return nil
+}
+
+// RoundCoordinates rounds the coordinates to CoordinatePrecision decimal places, if one was given.
+func (attraction *Attraction) RoundCoordinates() {
+	if attraction.CoordinatePrecision == 0 {
+		return
+	}
+
+	scale := math.Pow(10, float64(attraction.CoordinatePrecision))
+	attraction.Latitude = math.Round(attraction.Latitude*scale) / scale
+	attraction.Longitude = math.Round(attraction.Longitude*scale) / scale
+}
+
+// LocateAttraction ensures the attraction has coordinates. Client-supplied coordinates are validated and
+// kept as-is (filling in a display name via reverse geocoding when the attraction is unnamed); otherwise
+// the attraction is geocoded from its name, city and state.
+func (attraction *Attraction) LocateAttraction(geocoder geo.Geocoder) error {
+	attraction.Geocoding = nil
+	if attraction.HasCoordinates() {
+		if err := attraction.ValidateCoordinates(); err != nil {
+			return err
+		}
+
+		attraction.RoundCoordinates()
+		if attraction.Name == "" {
+			if err := attraction.ReverseGeocodeAttraction(geocoder); err != nil {
+				// The coordinates are still usable, the attraction just remains unnamed.
+				log.Printf("Unable to reverse geocode attraction; having error: %v", err)
+			}
+		}
+
+		attraction.NormalizeAddress()
+		attraction.Geocoding = newGeocodingResult(ProviderClient, []GeocodingCandidate{
+			{DisplayName: attraction.Name, Latitude: attraction.Latitude, Longitude: attraction.Longitude, Confidence: 1.0}})
+		return nil
+	}
+
+	attraction.NormalizeAddress()
+	result, err := attraction.GeocodeAttractionWithResult(geocoder)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return &UnresolvedAttractionLocationError{"Unable to geocode attraction."}
+	}
+
+	attraction.Latitude = result.Latitude
+	attraction.Longitude = result.Longitude
+	attraction.Geocoding = result
+
+	return nil
+}
+
+// ReverseGeocodeAttraction fills in the attraction's name, city and state from its coordinates, leaving
+// any values the client already supplied.
+func (attraction *Attraction) ReverseGeocodeAttraction(geocoder geo.Geocoder) error {
+	started := time.Now()
+	address, err := geocoder.ReverseGeocode(attraction.Latitude, attraction.Longitude)
+	metrics.ObserveGeocoderRequest(geocoderProvider(geocoder), started, err)
+	if err != nil {
+		return err
+	}
+
+	if address == nil {
+		return &UnresolvedAttractionLocationError{"No address found for the attraction's coordinates."}
+	}
+
+	if attraction.Name == "" {
+		attraction.Name = address.FormattedAddress
+	}
+
+	if attraction.City == "" {
+		attraction.City = address.City
+	}
+
+	if attraction.StateOrProvinceName == "" {
+		attraction.StateOrProvinceName = address.State
+	}
+
+	if attraction.Country == "" {
+		attraction.Country = address.CountryCode
+	}
+
+	return nil
+}
+
+// NormalizeAddress rewrites the country as its ISO 3166-1 alpha-2 code and the state or province as its
+// ISO 3166-2 subdivision code without the country prefix (i.e, "British Columbia, Canada" => "BC, CA").
+// When no country is given it is inferred from the subdivision if only one country has it. Unrecognised
+// values are left as given.
+func (attraction *Attraction) NormalizeAddress() {
+	countryCode, ok := NormalizeCountry(attraction.Country)
+	if ok {
+		attraction.Country = countryCode
+	} else if attraction.Country == "" {
+		countryCode, _, ok = findCountryOfSubdivision(attraction.StateOrProvinceName)
+		if ok {
+			attraction.Country = countryCode
+		}
+	}
+
+	if subdivisionCode, ok := NormalizeSubdivision(attraction.Country, attraction.StateOrProvinceName); ok {
+		attraction.StateOrProvinceName = subdivisionShortCode(subdivisionCode)
+	}
+}
+
+// StructuredGeocodingQuery identifies an attraction by its individual address components, so that a
+// geocoder can match each against the right field rather than guessing from a comma-joined string.
+type StructuredGeocodingQuery struct {
+	Name                string
+	City                string
+	StateOrProvinceName string
+	// CountryCode is an ISO 3166-1 alpha-2 code, if known.
+	CountryCode string
+	Country     string
+}
+
+// StructuredGeocoder is implemented by geocoders able to search by address component.
+type StructuredGeocoder interface {
+	GeocodeStructured(query StructuredGeocodingQuery) (*geo.Location, error)
+}
+
+// GeocodingQuery builds the structured query for the attraction, expanding ISO codes to their names as
+// geocoders match names more reliably than codes.
+func (attraction *Attraction) GeocodingQuery() StructuredGeocodingQuery {
+	query := StructuredGeocodingQuery{
+		Name:                strings.TrimSpace(attraction.Name),
+		City:                strings.TrimSpace(attraction.City),
+		StateOrProvinceName: strings.TrimSpace(attraction.StateOrProvinceName),
+		Country:             strings.TrimSpace(attraction.Country),
+	}
+
+	if countryCode, ok := NormalizeCountry(attraction.Country); ok {
+		query.CountryCode = countryCode
+		query.Country = CountryName(countryCode)
+		if subdivisionCode, ok := NormalizeSubdivision(countryCode, attraction.StateOrProvinceName); ok {
+			query.StateOrProvinceName = SubdivisionName(subdivisionCode)
+		}
+	}
+
+	return query
+}
+
+// Ensures the attraction can be identified by name, city and state. Country is optional.
+func (attraction *Attraction) validateKeyIdentifiers() error {
+	var missing []string
+	if strings.TrimSpace(attraction.Name) == "" {
+		missing = append(missing, "attraction name")
+	}
+
+	if strings.TrimSpace(attraction.City) == "" {
+		missing = append(missing, "city name")
+	}
+
+	if strings.TrimSpace(attraction.StateOrProvinceName) == "" {
+		missing = append(missing, "state or province name")
+	}
+
+	if len(missing) > 0 {
+		return &MissingAttractionKeyIdentifierError{fmt.Sprintf("Missing %s.", strings.Join(missing, ", "))}
+	}
+
+	return nil
+}
+
+// MergeAttractionNameCityAndState combines the name of the attraction, city, state and (if given) country
+// separated by a comma and space.
 func (attraction *Attraction) MergeAttractionNameCityAndState() (string, error) {
-	joinedString := strings.Join([]string{attraction.Name, attraction.City, attraction.StateOrProvinceName}, ", ")
-	if strings.Contains(joinedString, ", , ") {
-		return "", &MissingAttractionKeyIdentifierError{"Missing one of: attraction name, city name, or city name."}
-	} else if strings.HasPrefix(joinedString, ", ") {
-		return "", &MissingAttractionKeyIdentifierError{"Missing city name."}
-	} else if strings.HasSuffix(joinedString, ", ") {
-		return "", &MissingAttractionKeyIdentifierError{"Missing all of: attraction name, or city name, or state name."}
-	} else {
-		return joinedString, nil
+	if err := attraction.validateKeyIdentifiers(); err != nil {
+		return "", err
 	}
+
+	query := attraction.GeocodingQuery()
+	components := []string{query.Name, query.City, query.StateOrProvinceName}
+	if query.Country != "" {
+		components = append(components, query.Country)
+	}
+
+	return strings.Join(components, ", "), nil
 }
 
-// GeocodeAttraction takes the conjoined attraction's name and obtains the lat/lng coordinates for it.
-// A conjoined address is ATTRACTION_NAME, CITY, STATE. Country is omitted for now.
+// GeocodeAttraction obtains the lat/lng coordinates for the attraction. Geocoders implementing
+// StructuredGeocoder are queried by address component; others are given the conjoined address
+// ATTRACTION_NAME, CITY, STATE[, COUNTRY].
 func (attraction *Attraction) GeocodeAttraction(geocoder geo.Geocoder) (*geo.Location, error) {
+	if err := attraction.validateKeyIdentifiers(); err != nil {
+		log.Printf("Unable to identify attraction; having error: %v", err)
+		return nil, err
+	}
+
+	if structuredGeocoder, ok := geocoder.(StructuredGeocoder); ok {
+		return structuredGeocoder.GeocodeStructured(attraction.GeocodingQuery())
+	}
+
 	mergedAttraction, err := attraction.MergeAttractionNameCityAndState()
 	if err != nil {
 		log.Printf("Unable to merge attraction location identifiers; having error: %v", err)
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Spreadsheet exports rarely agree on column names, so each attraction field accepts a handful of
+// aliases. Header names are compared after lower-casing and trimming.
+var attractionCSVHeaderAliases = map[string][]string{
+	"name":      {"name", "attraction", "attraction_name", "attraction name"},
+	"city":      {"city", "city_name", "city name"},
+	"state":     {"state", "province", "state_or_province", "state_or_province_name", "state/province", "region"},
+	"latitude":  {"latitude", "lat"},
+	"longitude": {"longitude", "lng", "lon", "long"},
+	"weight":    {"weight", "priority"},
+	"notes":     {"notes", "note", "comments"},
+}
+
+var requiredAttractionCSVHeaders = []string{"name", "city", "state"}
+
+// InvalidAttractionValueError indicates a value for the attraction could not be interpreted (i.e, a latitude of "abc").
+type InvalidAttractionValueError struct {
+	message string
+}
+
+func (e *InvalidAttractionValueError) Error() string {
+	return e.message
+}
+
+// AttractionRowError ties a validation error to the spreadsheet row it was found on. Row 1 is the header.
+type AttractionRowError struct {
+	Row int
+	Err error
+}
+
+func (e *AttractionRowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+// Unwrap exposes the underlying validation error (i.e, a MissingAttractionKeyIdentifierError).
+func (e *AttractionRowError) Unwrap() error {
+	return e.Err
+}
+
+// AttractionRowErrors collects every row that failed validation so they can all be fixed in one pass.
+type AttractionRowErrors []*AttractionRowError
+
+func (e AttractionRowErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, rowError := range e {
+		messages[i] = rowError.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// ParseAttractionsCSV reads attractions from CSV data whose first row is a header. The name, city and
+// state/province columns are required unless rows carry latitude and longitude; weight and notes are optional.
+// When any row fails validation, all row errors are returned as AttractionRowErrors.
+func ParseAttractionsCSV(r io.Reader) ([]Attraction, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	// Spreadsheets commonly drop trailing empty cells, so rows are allowed to vary in length.
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, AttractionRowErrors{{1, &MissingAttractionKeyIdentifierError{"Missing header row."}}}
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns, err := mapAttractionCSVHeader(header)
+	if err != nil {
+		return nil, AttractionRowErrors{{1, err}}
+	}
+
+	var attractions []Attraction
+	var rowErrors AttractionRowErrors
+	row := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			rowErrors = append(rowErrors, &AttractionRowError{row, err})
+			continue
+		}
+
+		if isBlankCSVRecord(record) {
+			continue
+		}
+
+		attraction, err := parseAttractionCSVRecord(record, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, &AttractionRowError{row, err})
+			continue
+		}
+
+		attractions = append(attractions, attraction)
+	}
+
+	if len(rowErrors) > 0 {
+		return attractions, rowErrors
+	}
+
+	return attractions, nil
+}
+
+// Resolves the column index of each known attraction field from the header row.
+func mapAttractionCSVHeader(header []string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		for field, aliases := range attractionCSVHeaderAliases {
+			for _, alias := range aliases {
+				if column == alias {
+					columns[field] = i
+				}
+			}
+		}
+	}
+
+	// Rows may be identified purely by coordinates, in which case name, city and state are optional.
+	_, hasLatitude := columns["latitude"]
+	_, hasLongitude := columns["longitude"]
+	if hasLatitude && hasLongitude {
+		return columns, nil
+	}
+
+	var missingColumns []string
+	for _, field := range requiredAttractionCSVHeaders {
+		if _, ok := columns[field]; !ok {
+			missingColumns = append(missingColumns, field)
+		}
+	}
+
+	if len(missingColumns) > 0 {
+		return nil, &MissingAttractionKeyIdentifierError{
+			fmt.Sprintf("Missing header column(s): %s.", strings.Join(missingColumns, ", "))}
+	}
+
+	return columns, nil
+}
+
+func parseAttractionCSVRecord(record []string, columns map[string]int) (Attraction, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	attraction := Attraction{
+		Name:                value("name"),
+		City:                value("city"),
+		StateOrProvinceName: value("state"),
+		Notes:               value("notes"),
+	}
+
+	latitude, longitude := value("latitude"), value("longitude")
+	if (latitude == "") != (longitude == "") {
+		return Attraction{}, &InvalidAttractionValueError{"Latitude and longitude must be given together."}
+	}
+
+	var err error
+	if latitude != "" {
+		if attraction.Latitude, err = parseAttractionCSVFloat("latitude", latitude); err != nil {
+			return Attraction{}, err
+		}
+		if attraction.Longitude, err = parseAttractionCSVFloat("longitude", longitude); err != nil {
+			return Attraction{}, err
+		}
+		if err := attraction.ValidateCoordinates(); err != nil {
+			return Attraction{}, err
+		}
+	} else if _, err := attraction.MergeAttractionNameCityAndState(); err != nil {
+		return Attraction{}, err
+	}
+
+	if weight := value("weight"); weight != "" {
+		if attraction.Weight, err = parseAttractionCSVFloat("weight", weight); err != nil {
+			return Attraction{}, err
+		}
+		if attraction.Weight < 0 {
+			return Attraction{}, &InvalidAttractionValueError{"Weight must not be negative."}
+		}
+	}
+
+	return attraction, nil
+}
+
+func parseAttractionCSVFloat(field string, value string) (float64, error) {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0.0, &InvalidAttractionValueError{fmt.Sprintf("Invalid %s: %q is not a number.", field, value)}
+	}
+
+	return parsed, nil
+}
+
+func isBlankCSVRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
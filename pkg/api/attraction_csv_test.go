@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAttractionsCSV_allColumnsMapped(t *testing.T) {
+	csvData := "Attraction,City,Province,Lat,Lng,Weight,Notes\n" +
+		"Science World,Vancouver,BC,49.2734,-123.1038,2,Bring the kids\n"
+
+	attractions, err := ParseAttractionsCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing CSV: %v", err)
+	}
+
+	expectedAttraction := Attraction{
+		Name:                "Science World",
+		City:                "Vancouver",
+		StateOrProvinceName: "BC",
+		Latitude:            49.2734,
+		Longitude:           -123.1038,
+		Weight:              2,
+		Notes:               "Bring the kids",
+	}
+	if len(attractions) != 1 || attractions[0] != expectedAttraction {
+		t.Errorf("Parsed attractions were incorrect. Got: %+v, expected: %+v.", attractions, expectedAttraction)
+	}
+}
+
+func TestParseAttractionsCSV_optionalColumnsOmitted(t *testing.T) {
+	csvData := "name,city,state_or_province_name\n" +
+		"Science World,Vancouver,BC\n" +
+		"\n" +
+		"Stanley Park,Vancouver,BC\n"
+
+	attractions, err := ParseAttractionsCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing CSV: %v", err)
+	}
+
+	expectedAttractionsCount := 2
+	if len(attractions) != expectedAttractionsCount {
+		t.Errorf("Number of attractions was incorrect. Got: %d, expected: %d.", len(attractions), expectedAttractionsCount)
+	}
+}
+
+func TestParseAttractionsCSV_missingRequiredHeader(t *testing.T) {
+	csvData := "name,city\nScience World,Vancouver\n"
+
+	_, err := ParseAttractionsCSV(strings.NewReader(csvData))
+
+	var rowErrors AttractionRowErrors
+	if !errors.As(err, &rowErrors) || len(rowErrors) != 1 {
+		t.Fatalf("Expected a single row error. Got: %v.", err)
+	}
+
+	if rowErrors[0].Row != 1 {
+		t.Errorf("Header error row was incorrect. Got: %d, expected: %d.", rowErrors[0].Row, 1)
+	}
+
+	var missingKeyError *MissingAttractionKeyIdentifierError
+	if !errors.As(rowErrors[0], &missingKeyError) {
+		t.Errorf("Expected a MissingAttractionKeyIdentifierError. Got: %v.", rowErrors[0].Err)
+	}
+}
+
+func TestParseAttractionsCSV_rowErrorsAreNumbered(t *testing.T) {
+	csvData := "name,city,state,lat,lng,weight\n" +
+		"Science World,Vancouver,BC,,,\n" +
+		",Vancouver,BC,,,\n" +
+		"Stanley Park,Vancouver,BC,abc,-123.1,\n" +
+		"Granville Island,Vancouver,BC,49.27,,\n"
+
+	attractions, err := ParseAttractionsCSV(strings.NewReader(csvData))
+
+	var rowErrors AttractionRowErrors
+	if !errors.As(err, &rowErrors) {
+		t.Fatalf("Expected row errors. Got: %v.", err)
+	}
+
+	expectedRows := []int{3, 4, 5}
+	if len(rowErrors) != len(expectedRows) {
+		t.Fatalf("Number of row errors was incorrect. Got: %d, expected: %d.", len(rowErrors), len(expectedRows))
+	}
+
+	for i, expectedRow := range expectedRows {
+		if rowErrors[i].Row != expectedRow {
+			t.Errorf("Row error number was incorrect. Got: %d, expected: %d.", rowErrors[i].Row, expectedRow)
+		}
+	}
+
+	var missingKeyError *MissingAttractionKeyIdentifierError
+	if !errors.As(rowErrors[0], &missingKeyError) {
+		t.Errorf("Expected a MissingAttractionKeyIdentifierError for row 3. Got: %v.", rowErrors[0].Err)
+	}
+
+	if len(attractions) != 1 {
+		t.Errorf("Valid rows should still be returned. Got: %d, expected: %d.", len(attractions), 1)
+	}
+}
+
+func TestParseAttractionsCSV_coordinatesOnlyRows(t *testing.T) {
+	csvData := "name,lat,lng\n" +
+		",49.3017,-123.1417\n" +
+		"Pier,95.0,-123.1\n"
+
+	attractions, err := ParseAttractionsCSV(strings.NewReader(csvData))
+
+	var rowErrors AttractionRowErrors
+	if !errors.As(err, &rowErrors) || len(rowErrors) != 1 || rowErrors[0].Row != 3 {
+		t.Fatalf("Expected a single error for row 3. Got: %v.", err)
+	}
+
+	if len(attractions) != 1 || attractions[0].Latitude != 49.3017 {
+		t.Errorf("Coordinates-only row was not accepted. Got: %+v.", attractions)
+	}
+}
@@ -37,7 +37,7 @@ func TestMergeAttractionNameCityAndState_allEmptyStrings(t *testing.T) {
 }
 
 func TestMergeAttractionNameCityAndState_attractionNameOmitted(t *testing.T) {
-	attraction := Attraction{"", "Foobar", "CA", 0.0, 0.0}
+	attraction := Attraction{Name: "", City: "Foobar", StateOrProvinceName: "CA", Latitude: 0.0, Longitude: 0.0}
 	mergedAttractionName, _ := attraction.MergeAttractionNameCityAndState()
 	expectedMergedAttractionName := ""
 
@@ -50,7 +50,7 @@ func TestMergeAttractionNameCityAndState_attractionNameOmitted(t *testing.T) {
 }
 
 func TestMergeAttractionNameCityAndState_cityNameOmitted(t *testing.T) {
-	attraction := Attraction{"Foobar Bridge", "", "CA", 0.0, 0.0}
+	attraction := Attraction{Name: "Foobar Bridge", City: "", StateOrProvinceName: "CA", Latitude: 0.0, Longitude: 0.0}
 	mergedAttractionName, _ := attraction.MergeAttractionNameCityAndState()
 	expectedMergedAttractionName := ""
 
@@ -63,7 +63,7 @@ func TestMergeAttractionNameCityAndState_cityNameOmitted(t *testing.T) {
 }
 
 func TestMergeAttractionNameCityAndState_StateNameOmitted(t *testing.T) {
-	attraction := Attraction{"Foobar Bridge", "Foobar City", "", 0.0, 0.0}
+	attraction := Attraction{Name: "Foobar Bridge", City: "Foobar City", StateOrProvinceName: "", Latitude: 0.0, Longitude: 0.0}
 	mergedAttractionName, _ := attraction.MergeAttractionNameCityAndState()
 	expectedMergedAttractionName := ""
 
@@ -76,7 +76,7 @@ func TestMergeAttractionNameCityAndState_StateNameOmitted(t *testing.T) {
 }
 
 func TestMergeAttractionNameCityAndState_allAttractionIdentifersPresent(t *testing.T) {
-	attraction := Attraction{"Foobar Bridge", "Foobar City", "CA", 0.0, 0.0}
+	attraction := Attraction{Name: "Foobar Bridge", City: "Foobar City", StateOrProvinceName: "CA", Latitude: 0.0, Longitude: 0.0}
 	mergedAttractionName, _ := attraction.MergeAttractionNameCityAndState()
 	expectedMergedAttractionName := "Foobar Bridge, Foobar City, CA"
 
@@ -90,7 +90,7 @@ func TestMergeAttractionNameCityAndState_allAttractionIdentifersPresent(t *testi
 
 func TestGeocodeAttraction_locationReturned(t *testing.T) {
 	geocoder := StubGeocoder{}
-	attraction := Attraction{"Fake Attraction", "Fake City", "CA", 0.0, 0.0}
+	attraction := Attraction{Name: "Fake Attraction", City: "Fake City", StateOrProvinceName: "CA", Latitude: 0.0, Longitude: 0.0}
 	location, _ := attraction.GeocodeAttraction(geocoder)
 
 	expectedLatitude := -64.07703
@@ -110,3 +110,125 @@ func TestGeocodeAttraction_locationReturned(t *testing.T) {
 			expectedLongitude)
 	}
 }
+
+// RecordingGeocoder counts forward geocoding calls and reverse geocodes to a fixed address.
+type RecordingGeocoder struct {
+	geocodeCalls int
+}
+
+func (rg *RecordingGeocoder) Geocode(address string) (*geo.Location, error) {
+	rg.geocodeCalls++
+	return &geo.Location{Lat: -64.07703, Lng: -3.76949}, nil
+}
+
+func (rg *RecordingGeocoder) ReverseGeocode(lat, lng float64) (*geo.Address, error) {
+	return &geo.Address{FormattedAddress: "1455 Quebec St, Vancouver", City: "Vancouver", State: "British Columbia"}, nil
+}
+
+func TestLocateAttraction_suppliedCoordinatesSkipGeocoding(t *testing.T) {
+	geocoder := &RecordingGeocoder{}
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 49.2734, Longitude: -123.1038}
+
+	err := attraction.LocateAttraction(geocoder)
+	if err != nil {
+		t.Fatalf("Unexpected error locating attraction: %v", err)
+	}
+
+	if geocoder.geocodeCalls != 0 {
+		t.Errorf("Geocoder should not have been called. Got: %d calls, expected: 0.", geocoder.geocodeCalls)
+	}
+
+	if attraction.Latitude != 49.2734 || attraction.Longitude != -123.1038 {
+		t.Errorf("Supplied coordinates were overwritten. Got: (%.6f, %.6f).", attraction.Latitude, attraction.Longitude)
+	}
+}
+
+func TestLocateAttraction_coordinatesOnlyAttractionIsNamed(t *testing.T) {
+	attraction := Attraction{Latitude: 49.2734, Longitude: -123.1038}
+
+	attraction.LocateAttraction(&RecordingGeocoder{})
+
+	expectedName := "1455 Quebec St, Vancouver"
+	if attraction.Name != expectedName {
+		t.Errorf("Attraction name was not filled in. Got: %s, expected: %s.", attraction.Name, expectedName)
+	}
+
+	if attraction.City != "Vancouver" {
+		t.Errorf("Attraction city was not filled in. Got: %s, expected: %s.", attraction.City, "Vancouver")
+	}
+}
+
+func TestLocateAttraction_outOfRangeCoordinatesRejected(t *testing.T) {
+	attraction := Attraction{Name: "Nowhere", Latitude: 91.0, Longitude: -123.1038}
+
+	err := attraction.LocateAttraction(&RecordingGeocoder{})
+
+	if _, ok := err.(*InvalidAttractionCoordinatesError); !ok {
+		t.Errorf("Expected an InvalidAttractionCoordinatesError. Got: %v.", err)
+	}
+}
+
+func TestLocateAttraction_missingCoordinatesGeocoded(t *testing.T) {
+	geocoder := &RecordingGeocoder{}
+	attraction := Attraction{Name: "Fake Attraction", City: "Fake City", StateOrProvinceName: "CA"}
+
+	attraction.LocateAttraction(geocoder)
+
+	if geocoder.geocodeCalls != 1 {
+		t.Errorf("Geocoder was not called. Got: %d calls, expected: 1.", geocoder.geocodeCalls)
+	}
+
+	if attraction.Latitude != -64.07703 {
+		t.Errorf("Geocoded latitude was not set. Got: %.6f, expected: %.6f.", attraction.Latitude, -64.07703)
+	}
+}
+
+func TestRoundCoordinates_precisionApplied(t *testing.T) {
+	attraction := Attraction{Latitude: 49.273456, Longitude: -123.103812, CoordinatePrecision: 3}
+
+	attraction.RoundCoordinates()
+
+	if attraction.Latitude != 49.273 || attraction.Longitude != -123.104 {
+		t.Errorf("Coordinates were not rounded. Got: (%.6f, %.6f), expected: (49.273, -123.104).", attraction.Latitude, attraction.Longitude)
+	}
+}
+
+func TestMergeAttractionNameCityAndState_nameContainingCommas(t *testing.T) {
+	attraction := Attraction{Name: "Fish, Chips, & Co", City: "Vancouver", StateOrProvinceName: "BC"}
+	mergedAttractionName, err := attraction.MergeAttractionNameCityAndState()
+	expectedMergedAttractionName := "Fish, Chips, & Co, Vancouver, BC"
+
+	if err != nil || mergedAttractionName != expectedMergedAttractionName {
+		t.Errorf(
+			"Merged attraction name was not expected. Got: %s (%v), expected: %s.",
+			mergedAttractionName,
+			err,
+			expectedMergedAttractionName)
+	}
+}
+
+func TestMergeAttractionNameCityAndState_countryIncluded(t *testing.T) {
+	attraction := Attraction{Name: "Eiffel Tower", City: "Paris", StateOrProvinceName: "IDF", Country: "FR"}
+	mergedAttractionName, _ := attraction.MergeAttractionNameCityAndState()
+	expectedMergedAttractionName := "Eiffel Tower, Paris, IDF, France"
+
+	if mergedAttractionName != expectedMergedAttractionName {
+		t.Errorf(
+			"Merged attraction name was not expected. Got: %s, expected: %s.",
+			mergedAttractionName,
+			expectedMergedAttractionName)
+	}
+}
+
+func TestMergeAttractionNameCityAndState_subdivisionCodeExpanded(t *testing.T) {
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Country: "CA"}
+	mergedAttractionName, _ := attraction.MergeAttractionNameCityAndState()
+	expectedMergedAttractionName := "Science World, Vancouver, British Columbia, Canada"
+
+	if mergedAttractionName != expectedMergedAttractionName {
+		t.Errorf(
+			"Merged attraction name was not expected. Got: %s, expected: %s.",
+			mergedAttractionName,
+			expectedMergedAttractionName)
+	}
+}
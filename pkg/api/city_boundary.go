@@ -0,0 +1,85 @@
+package api
+
+import (
+	"math"
+)
+
+// CityBoundaryClassification describes where an attraction lies relative to its city's neighborhoods.
+type CityBoundaryClassification string
+
+const (
+	// InsideCity attractions lie within one of the city's neighborhoods.
+	InsideCity CityBoundaryClassification = "inside_city"
+	// NearCity attractions lie outside every neighborhood but within the buffer of one (i.e, just
+	// offshore); they are snapped to the nearest neighborhood.
+	NearCity CityBoundaryClassification = "near_city"
+	// OutsideCity attractions are too far from the city to be used, most likely a bad geocode.
+	OutsideCity CityBoundaryClassification = "outside_city"
+)
+
+// DefaultNearCityBufferInMeters is how far outside a city's neighborhoods an attraction may lie and still
+// be considered part of the city.
+const DefaultNearCityBufferInMeters = 500.0
+
+const metersPerDegreeOfLatitude = 111320.0
+
+// ExpandByMeters grows the box by roughly the given distance in each direction.
+func (box BoundingBox) ExpandByMeters(meters float64) BoundingBox {
+	latitudeDelta := meters / metersPerDegreeOfLatitude
+	// Degrees of longitude shrink towards the poles; use the latitude nearest a pole for the widest margin.
+	widestLatitude := math.Max(math.Abs(box.MinLatitude), math.Abs(box.MaxLatitude))
+	longitudeDelta := 180.0
+	if cosine := math.Cos(widestLatitude * math.Pi / 180.0); cosine > 0 {
+		longitudeDelta = math.Min(meters/(metersPerDegreeOfLatitude*cosine), 180.0)
+	}
+
+	return BoundingBox{
+		MinLatitude:  box.MinLatitude - latitudeDelta,
+		MinLongitude: box.MinLongitude - longitudeDelta,
+		MaxLatitude:  box.MaxLatitude + latitudeDelta,
+		MaxLongitude: box.MaxLongitude + longitudeDelta,
+	}
+}
+
+// ClassifyAttractionByCityBoundary determines whether the attraction is inside, near or outside its city,
+// given the extent of the city's neighborhoods (see FindCityExtent). Inside and near attractions are
+// returned with the neighborhood they were matched to.
+func ClassifyAttractionByCityBoundary(
+	attraction Attraction,
+	cityExtent BoundingBox,
+	nearCityBufferInMeters float64) (CityBoundaryClassification, NeighborhoodMatch, error) {
+	return ClassifyAttractionByRegionBoundary(
+		attraction,
+		NewRegion([]Attraction{attraction}),
+		cityExtent,
+		nearCityBufferInMeters)
+}
+
+// ClassifyAttractionByRegionBoundary classifies the attraction as ClassifyAttractionByCityBoundary does,
+// against the neighborhoods of every city in the region. regionExtent is the union of the cities' extents.
+// An attraction is inside or near whichever city of the region its neighborhood belongs to.
+func ClassifyAttractionByRegionBoundary(
+	attraction Attraction,
+	region Region,
+	regionExtent BoundingBox,
+	nearCityBufferInMeters float64) (CityBoundaryClassification, NeighborhoodMatch, error) {
+	// Attractions on another continent need not be measured against every neighborhood.
+	if !regionExtent.ExpandByMeters(nearCityBufferInMeters).Contains(attraction.Latitude, attraction.Longitude) {
+		return OutsideCity, NeighborhoodMatch{}, nil
+	}
+
+	match, err := FindNeighborhoodMatchInRegion(attraction, region, nearCityBufferInMeters)
+	if _, ok := err.(*NoNeighborhoodFoundError); ok {
+		return OutsideCity, NeighborhoodMatch{}, nil
+	}
+
+	if err != nil {
+		return OutsideCity, NeighborhoodMatch{}, err
+	}
+
+	if match.MatchType == CoveringNeighborhoodMatch {
+		return InsideCity, match, nil
+	}
+
+	return NearCity, match, nil
+}
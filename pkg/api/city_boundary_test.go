@@ -0,0 +1,42 @@
+package api
+
+import "testing"
+
+var vancouverExtent = BoundingBox{49.198, -123.225, 49.314, -123.023}
+
+func TestExpandByMeters_boxGrowsInEveryDirection(t *testing.T) {
+	expanded := vancouverExtent.ExpandByMeters(1000.0)
+
+	if !expanded.Contains(49.320, -123.100) || !expanded.Contains(49.250, -123.236) {
+		t.Errorf("Points within 1km of the extent should be contained. Got: %+v.", expanded)
+	}
+
+	if expanded.Contains(49.350, -123.100) {
+		t.Errorf("Points 4km from the extent should not be contained. Got: %+v.", expanded)
+	}
+}
+
+func TestClassifyAttractionByCityBoundary_farAwayAttractionIsOutside(t *testing.T) {
+	attraction := Attraction{Name: "Eiffel Tower", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 48.8584, Longitude: 2.2945}
+
+	classification, match, _ := ClassifyAttractionByCityBoundary(attraction, vancouverExtent, DefaultNearCityBufferInMeters)
+
+	if classification != OutsideCity || match.Neighborhood.Name != "" {
+		t.Errorf("Attraction should be outside the city. Got: %s, %s.", classification, match.Neighborhood.Name)
+	}
+}
+
+func TestClassifyAttractionByCityBoundary_attractionInNeighborhoodIsInside(t *testing.T) {
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 49.2820, Longitude: -123.1171}
+
+	classification, match, _ := ClassifyAttractionByCityBoundary(attraction, vancouverExtent, DefaultNearCityBufferInMeters)
+
+	expectedNeighborhoodName := "Downtown"
+	if classification != InsideCity || match.Neighborhood.Name != expectedNeighborhoodName {
+		t.Errorf(
+			"Attraction should be inside %s. Got: %s, %s.",
+			expectedNeighborhoodName,
+			classification,
+			match.Neighborhood.Name)
+	}
+}
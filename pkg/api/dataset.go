@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"../connections"
+	"github.com/lib/pq"
+)
+
+// Dataset describes where a set of boundaries came from. Several versions of a dataset (i.e, a city's
+// boundaries before and after a re-draw) may be imported side by side; only the active version's
+// neighborhoods are used, so past versions can be reactivated to reproduce older recommendations.
+type Dataset struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Version    string    `json:"version"`
+	Source     string    `json:"source"`
+	License    string    `json:"license"`
+	ImportedAt time.Time `json:"imported_at"`
+	Active     bool      `json:"active"`
+}
+
+// NoDatasetFoundError indicates a dataset was not resolved
+type NoDatasetFoundError struct {
+	message string
+}
+
+func (e *NoDatasetFoundError) Error() string {
+	return e.message
+}
+
+// ActivateDataset makes the given dataset the active version of its name, deactivating the others, and
+// rebuilds the neighborhood distances for the newly active boundaries in the same transaction, so that
+// neither is stored without the other.
+func ActivateDataset(datasetID int64) error {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return err
+	}
+
+	activateDatasetQuery := `
+    UPDATE neighborhood_geocoding.datasets
+    SET active = (id = $1)
+    WHERE name = (SELECT name FROM neighborhood_geocoding.datasets WHERE id = $1)
+    `
+
+	result, err := tx.Exec(activateDatasetQuery, datasetID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if updated, err := result.RowsAffected(); err != nil || updated == 0 {
+		tx.Rollback()
+		if err != nil {
+			return err
+		}
+		return &NoDatasetFoundError{fmt.Sprintf("No dataset with id %d.", datasetID)}
+	}
+
+	if err := rebuildNeighborhoodDistances(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	forgetNeighborhoodDistanceMatrix()
+	return nil
+}
+
+// FindActiveDatasets resolves the active datasets providing neighborhoods for the region's cities.
+// Neighborhoods imported without a dataset are not reported.
+func FindActiveDatasets(region Region) ([]Dataset, error) {
+	activeDatasetsQuery := `
+    SELECT DISTINCT datasets.id, datasets.name, datasets.version, datasets.source, datasets.license,
+        datasets.imported_at, datasets.active
+    FROM neighborhood_geocoding.datasets as datasets
+    JOIN neighborhood_geocoding.active_neighborhoods as neighborhoods
+        ON neighborhoods.dataset_id = datasets.id
+    WHERE ` + regionCondition("neighborhoods", 1, 2) + `
+    ORDER BY datasets.name, datasets.id
+    `
+
+	cities, states := region.citiesAndStates()
+	rows, err := connections.Init().Query(activeDatasetsQuery, pq.Array(cities), pq.Array(states))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var datasets []Dataset
+	for rows.Next() {
+		var dataset Dataset
+		if err := rows.Scan(
+			&dataset.ID,
+			&dataset.Name,
+			&dataset.Version,
+			&dataset.Source,
+			&dataset.License,
+			&dataset.ImportedAt,
+			&dataset.Active); err != nil {
+			return nil, err
+		}
+		datasets = append(datasets, dataset)
+	}
+
+	return datasets, rows.Err()
+}
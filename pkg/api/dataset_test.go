@@ -0,0 +1,22 @@
+package api
+
+import "testing"
+
+func TestActivateDataset_unknownDataset(t *testing.T) {
+	err := ActivateDataset(-1)
+
+	if _, ok := err.(*NoDatasetFoundError); !ok {
+		t.Errorf("Expected a NoDatasetFoundError for an unknown dataset. Got: %v.", err)
+	}
+}
+
+func TestFindActiveDatasets_unknownCityHasNoDatasets(t *testing.T) {
+	datasets, err := FindActiveDatasets(Region{Cities: []RegionCity{{"Atlantis", "ZZ"}}})
+	if err != nil {
+		t.Fatalf("Unexpected error finding datasets: %v", err)
+	}
+
+	if len(datasets) != 0 {
+		t.Errorf("An unknown city should have no datasets. Got: %+v.", datasets)
+	}
+}
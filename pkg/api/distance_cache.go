@@ -0,0 +1,28 @@
+package api
+
+import (
+	"sync"
+
+	"../cache"
+)
+
+var (
+	distanceCacheMutex sync.RWMutex
+	distanceCache      cache.DistanceCache = cache.NewLRUDistanceCache(cache.DefaultLRUCapacity)
+)
+
+// SetDistanceCache replaces the cache of neighborhood distances and centers shared by all requests. An
+// in-memory LRU cache is used by default.
+func SetDistanceCache(c cache.DistanceCache) {
+	distanceCacheMutex.Lock()
+	defer distanceCacheMutex.Unlock()
+
+	distanceCache = c
+}
+
+func getDistanceCache() cache.DistanceCache {
+	distanceCacheMutex.RLock()
+	defer distanceCacheMutex.RUnlock()
+
+	return distanceCache
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"../metrics"
+	"github.com/codingsince1985/geo-golang"
+)
+
+// Geocoding providers reported in GeocodingResult.
+const (
+	// ProviderClient means the client supplied the attraction's coordinates.
+	ProviderClient    = "client"
+	ProviderNominatim = "nominatim"
+	// ProviderUnknown is any geo.Geocoder which does not report its own results.
+	ProviderUnknown = "unknown"
+)
+
+// Two candidates whose confidence differ by no more than this are considered equally likely matches.
+const ambiguityConfidenceMargin = 0.05
+
+// BoundingBox is an axis-aligned box of WGS84 coordinates.
+type BoundingBox struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// Contains reports whether the coordinates lie within (or on the edge of) the box.
+func (box BoundingBox) Contains(latitude float64, longitude float64) bool {
+	return latitude >= box.MinLatitude && latitude <= box.MaxLatitude &&
+		longitude >= box.MinLongitude && longitude <= box.MaxLongitude
+}
+
+// GeocodingCandidate is a single place the geocoder matched an attraction to.
+type GeocodingCandidate struct {
+	DisplayName string  `json:"display_name,omitempty"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	// Confidence ranges from 0 (a guess) to 1 (certain). For Nominatim this is the place's importance.
+	Confidence  float64      `json:"confidence"`
+	BoundingBox *BoundingBox `json:"bounding_box,omitempty"`
+}
+
+// GeocodingResult describes how an attraction's location was determined: the chosen candidate, who
+// provided it, and which other places the geocoder considered.
+type GeocodingResult struct {
+	Provider string `json:"provider"`
+	GeocodingCandidate
+	Alternatives []GeocodingCandidate `json:"alternatives,omitempty"`
+	// Ambiguous is set when an alternative is about as likely as the chosen candidate.
+	Ambiguous bool `json:"ambiguous"`
+	// Suspicious is set when the result is unlikely to be the intended place, see SuspiciousReason.
+	Suspicious       bool   `json:"suspicious"`
+	SuspiciousReason string `json:"suspicious_reason,omitempty"`
+}
+
+// Names the provider a geocoder's requests are recorded under.
+func geocoderProvider(geocoder geo.Geocoder) string {
+	if _, ok := geocoder.(*NominatimGeocoder); ok {
+		return ProviderNominatim
+	}
+
+	return ProviderUnknown
+}
+
+// ResultGeocoder is implemented by geocoders able to describe their matches in detail.
+type ResultGeocoder interface {
+	GeocodeWithResult(query StructuredGeocodingQuery) (*GeocodingResult, error)
+}
+
+// Builds a result from candidates ordered best first. Returns nil when there are none.
+func newGeocodingResult(provider string, candidates []GeocodingCandidate) *GeocodingResult {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	result := &GeocodingResult{
+		Provider:           provider,
+		GeocodingCandidate: candidates[0],
+		Alternatives:       candidates[1:],
+	}
+
+	for _, alternative := range result.Alternatives {
+		if math.Abs(result.Confidence-alternative.Confidence) <= ambiguityConfidenceMargin {
+			result.Ambiguous = true
+		}
+	}
+
+	return result
+}
+
+// Location returns the chosen candidate's coordinates.
+func (result *GeocodingResult) Location() *geo.Location {
+	return &geo.Location{Lat: result.Latitude, Lng: result.Longitude}
+}
+
+// CheckAgainstExtent flags the result as suspicious when it lies outside the extent of the city the
+// attraction is meant to be in.
+func (result *GeocodingResult) CheckAgainstExtent(city string, extent BoundingBox) {
+	if extent.Contains(result.Latitude, result.Longitude) {
+		return
+	}
+
+	result.Suspicious = true
+	result.SuspiciousReason = fmt.Sprintf("Location lies outside the known neighborhoods of %s.", city)
+}
+
+// GeocodeAttractionWithResult geocodes the attraction, describing the match in as much detail as the
+// geocoder allows. A nil result is returned when nothing matched.
+func (attraction *Attraction) GeocodeAttractionWithResult(geocoder geo.Geocoder) (*GeocodingResult, error) {
+	if resultGeocoder, ok := geocoder.(ResultGeocoder); ok {
+		if err := attraction.validateKeyIdentifiers(); err != nil {
+			return nil, err
+		}
+
+		started := time.Now()
+		result, err := resultGeocoder.GeocodeWithResult(attraction.GeocodingQuery())
+		metrics.ObserveGeocoderRequest(geocoderProvider(geocoder), started, err)
+		return result, err
+	}
+
+	started := time.Now()
+	location, err := attraction.GeocodeAttraction(geocoder)
+	metrics.ObserveGeocoderRequest(geocoderProvider(geocoder), started, err)
+	if err != nil || location == nil {
+		return nil, err
+	}
+
+	return newGeocodingResult(ProviderUnknown, []GeocodingCandidate{{Latitude: location.Lat, Longitude: location.Lng}}), nil
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGeocodeWithResult_alternativesAndConfidenceReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"lat": "49.2734", "lon": "-123.1038", "display_name": "Science World, Vancouver", "importance": 0.62,
+			 "boundingbox": ["49.2728", "49.2740", "-123.1045", "-123.1031"]},
+			{"lat": "49.2600", "lon": "-123.1100", "display_name": "Science World Parking, Vancouver", "importance": 0.21}
+		]`))
+	}))
+	defer server.Close()
+
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Country: "CA"}
+	err := attraction.LocateAttraction(NewNominatimGeocoder(server.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error locating attraction: %v", err)
+	}
+
+	result := attraction.Geocoding
+	if result == nil {
+		t.Fatalf("Geocoding result was not recorded on the attraction.")
+	}
+
+	if result.Provider != ProviderNominatim || result.Confidence != 0.62 || result.DisplayName != "Science World, Vancouver" {
+		t.Errorf("Chosen candidate was incorrect. Got: %+v.", result)
+	}
+
+	expectedBoundingBox := BoundingBox{49.2728, -123.1045, 49.2740, -123.1031}
+	if result.BoundingBox == nil || *result.BoundingBox != expectedBoundingBox {
+		t.Errorf("Bounding box was incorrect. Got: %v, expected: %v.", result.BoundingBox, expectedBoundingBox)
+	}
+
+	if len(result.Alternatives) != 1 || result.Ambiguous {
+		t.Errorf("Expected one unambiguous alternative. Got: %d alternatives, ambiguous: %t.", len(result.Alternatives), result.Ambiguous)
+	}
+}
+
+func TestNewGeocodingResult_closeCandidatesAreAmbiguous(t *testing.T) {
+	result := newGeocodingResult(ProviderNominatim, []GeocodingCandidate{
+		{DisplayName: "Paris, Texas", Confidence: 0.60},
+		{DisplayName: "Paris, Tennessee", Confidence: 0.58}})
+
+	if !result.Ambiguous {
+		t.Errorf("Candidates with near-equal confidence should be ambiguous.")
+	}
+}
+
+func TestNewGeocodingResult_noCandidates(t *testing.T) {
+	result := newGeocodingResult(ProviderNominatim, nil)
+
+	if result != nil {
+		t.Errorf("Expected no result when there are no candidates. Got: %+v.", result)
+	}
+}
+
+func TestCheckAgainstExtent_outsideExtentIsSuspicious(t *testing.T) {
+	vancouver := BoundingBox{49.198, -123.225, 49.314, -123.023}
+	result := newGeocodingResult(ProviderNominatim, []GeocodingCandidate{{Latitude: 48.8584, Longitude: 2.2945}})
+
+	result.CheckAgainstExtent("Vancouver", vancouver)
+
+	if !result.Suspicious || result.SuspiciousReason == "" {
+		t.Errorf("A result outside the city extent should be suspicious. Got: %+v.", result)
+	}
+}
+
+func TestCheckAgainstExtent_insideExtentIsAccepted(t *testing.T) {
+	vancouver := BoundingBox{49.198, -123.225, 49.314, -123.023}
+	result := newGeocodingResult(ProviderNominatim, []GeocodingCandidate{{Latitude: 49.2734, Longitude: -123.1038}})
+
+	result.CheckAgainstExtent("Vancouver", vancouver)
+
+	if result.Suspicious {
+		t.Errorf("A result inside the city extent should not be suspicious.")
+	}
+}
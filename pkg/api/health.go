@@ -0,0 +1,53 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+
+	"../connections"
+)
+
+// Readiness describes the database the planner depends on, once it is able to plan.
+type Readiness struct {
+	PostGISVersion   string `json:"postgis_version"`
+	BoundariesLoaded bool   `json:"boundaries_loaded"`
+}
+
+// NotReadyError indicates the database cannot be planned against yet.
+type NotReadyError struct {
+	message string
+}
+
+func (e *NotReadyError) Error() string {
+	return e.message
+}
+
+// CheckReadiness reports whether the database is reachable, has the PostGIS extension and has
+// neighborhood boundaries loaded, returning a NotReadyError when it does not.
+func CheckReadiness() (*Readiness, error) {
+	db := connections.Init()
+	if err := db.Ping(); err != nil {
+		return nil, &NotReadyError{fmt.Sprintf("The database is unreachable: %v", err)}
+	}
+
+	var readiness Readiness
+	err := db.QueryRow(`SELECT extversion FROM pg_extension WHERE extname = 'postgis'`).Scan(&readiness.PostGISVersion)
+	if err == sql.ErrNoRows {
+		return nil, &NotReadyError{"The PostGIS extension is not installed."}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	boundariesQuery := `
+    SELECT EXISTS (SELECT 1 FROM neighborhood_geocoding.active_neighborhoods)
+    `
+	if err := db.QueryRow(boundariesQuery).Scan(&readiness.BoundariesLoaded); err != nil {
+		return nil, &NotReadyError{fmt.Sprintf("The neighborhood boundaries cannot be read: %v", err)}
+	}
+	if !readiness.BoundariesLoaded {
+		return nil, &NotReadyError{"No neighborhood boundaries are loaded."}
+	}
+
+	return &readiness, nil
+}
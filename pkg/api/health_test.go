@@ -0,0 +1,14 @@
+package api
+
+import "testing"
+
+func TestCheckReadiness_loadedDatabaseIsReady(t *testing.T) {
+	readiness, err := CheckReadiness()
+	if err != nil {
+		t.Fatalf("Unexpected error checking readiness: %v", err)
+	}
+
+	if readiness.PostGISVersion == "" || !readiness.BoundariesLoaded {
+		t.Errorf("A loaded database should report its PostGIS version and boundaries. Got: %+v.", readiness)
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"strings"
+)
+
+type iso3166Country struct {
+	alpha2  string
+	alpha3  string
+	name    string
+	aliases []string
+}
+
+type iso3166Subdivision struct {
+	code string // i.e, "CA-BC"
+	name string
+}
+
+// Informal names people commonly type which are not part of the standard.
+var countryAliases = map[string]string{
+	"uk":            "GB",
+	"great britain": "GB",
+	"u.k.":          "GB",
+	"u.s.":          "US",
+	"u.s.a.":        "US",
+	"america":       "US",
+}
+
+// NormalizeCountry resolves a country's name, ISO 3166-1 alpha-2 or alpha-3 code to its alpha-2 code
+// (i.e, "Canada", "CAN" and "ca" all become "CA").
+func NormalizeCountry(country string) (string, bool) {
+	key := normalizeISO3166Key(country)
+	if key == "" {
+		return "", false
+	}
+
+	if alpha2, ok := countryAliases[key]; ok {
+		return alpha2, true
+	}
+
+	for _, c := range iso3166Countries {
+		if key == strings.ToLower(c.alpha2) || key == strings.ToLower(c.alpha3) || key == strings.ToLower(c.name) {
+			return c.alpha2, true
+		}
+
+		for _, alias := range c.aliases {
+			if key == strings.ToLower(alias) {
+				return c.alpha2, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// CountryName returns the English short name of an ISO 3166-1 alpha-2 code, or the code itself when unknown.
+func CountryName(alpha2 string) string {
+	for _, c := range iso3166Countries {
+		if strings.EqualFold(c.alpha2, alpha2) {
+			return c.name
+		}
+	}
+
+	return alpha2
+}
+
+// NormalizeSubdivision resolves a state or province name or code to its ISO 3166-2 code within the given
+// country (i.e, "British Columbia", "BC" and "CA-BC" all become "CA-BC" for "CA").
+func NormalizeSubdivision(countryCode string, subdivision string) (string, bool) {
+	key := normalizeISO3166Key(subdivision)
+	if key == "" {
+		return "", false
+	}
+
+	for _, s := range iso3166Subdivisions[strings.ToUpper(countryCode)] {
+		if key == strings.ToLower(s.code) || key == strings.ToLower(subdivisionShortCode(s.code)) || key == strings.ToLower(s.name) {
+			return s.code, true
+		}
+	}
+
+	return "", false
+}
+
+// SubdivisionName returns the name of an ISO 3166-2 code, or the code itself when unknown.
+func SubdivisionName(code string) string {
+	countryCode := strings.SplitN(code, "-", 2)[0]
+	for _, s := range iso3166Subdivisions[strings.ToUpper(countryCode)] {
+		if strings.EqualFold(s.code, code) {
+			return s.name
+		}
+	}
+
+	return code
+}
+
+// Finds the only country having the given subdivision. "WA" is both Washington and Western Australia, so
+// ambiguous subdivisions resolve to nothing.
+func findCountryOfSubdivision(subdivision string) (string, string, bool) {
+	var matchedCountry, matchedCode string
+	for countryCode := range iso3166Subdivisions {
+		if code, ok := NormalizeSubdivision(countryCode, subdivision); ok {
+			if matchedCountry != "" {
+				return "", "", false
+			}
+			matchedCountry, matchedCode = countryCode, code
+		}
+	}
+
+	return matchedCountry, matchedCode, matchedCountry != ""
+}
+
+// Strips the country prefix from an ISO 3166-2 code (i.e, "CA-BC" => "BC").
+func subdivisionShortCode(code string) string {
+	parts := strings.SplitN(code, "-", 2)
+	if len(parts) != 2 {
+		return code
+	}
+
+	return parts[1]
+}
+
+func normalizeISO3166Key(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,378 @@
+package api
+
+// ISO 3166-1 countries and ISO 3166-2 subdivisions, taken from the Debian iso-codes package.
+// Subdivisions are only listed for countries we currently hold neighborhood boundaries for, or
+// expect to soon; attractions elsewhere keep their state/province as given.
+
+var iso3166Countries = []iso3166Country{
+	{"AD", "AND", "Andorra", []string{"Principality of Andorra"}},
+	{"AE", "ARE", "United Arab Emirates", nil},
+	{"AF", "AFG", "Afghanistan", []string{"Islamic Republic of Afghanistan"}},
+	{"AG", "ATG", "Antigua and Barbuda", nil},
+	{"AI", "AIA", "Anguilla", nil},
+	{"AL", "ALB", "Albania", []string{"Republic of Albania"}},
+	{"AM", "ARM", "Armenia", []string{"Republic of Armenia"}},
+	{"AO", "AGO", "Angola", []string{"Republic of Angola"}},
+	{"AQ", "ATA", "Antarctica", nil},
+	{"AR", "ARG", "Argentina", []string{"Argentine Republic"}},
+	{"AS", "ASM", "American Samoa", nil},
+	{"AT", "AUT", "Austria", []string{"Republic of Austria"}},
+	{"AU", "AUS", "Australia", nil},
+	{"AW", "ABW", "Aruba", nil},
+	{"AX", "ALA", "Åland Islands", nil},
+	{"AZ", "AZE", "Azerbaijan", []string{"Republic of Azerbaijan"}},
+	{"BA", "BIH", "Bosnia and Herzegovina", []string{"Republic of Bosnia and Herzegovina"}},
+	{"BB", "BRB", "Barbados", nil},
+	{"BD", "BGD", "Bangladesh", []string{"People's Republic of Bangladesh"}},
+	{"BE", "BEL", "Belgium", []string{"Kingdom of Belgium"}},
+	{"BF", "BFA", "Burkina Faso", nil},
+	{"BG", "BGR", "Bulgaria", []string{"Republic of Bulgaria"}},
+	{"BH", "BHR", "Bahrain", []string{"Kingdom of Bahrain"}},
+	{"BI", "BDI", "Burundi", []string{"Republic of Burundi"}},
+	{"BJ", "BEN", "Benin", []string{"Republic of Benin"}},
+	{"BL", "BLM", "Saint Barthélemy", nil},
+	{"BM", "BMU", "Bermuda", nil},
+	{"BN", "BRN", "Brunei Darussalam", nil},
+	{"BO", "BOL", "Bolivia, Plurinational State of", []string{"Bolivia", "Plurinational State of Bolivia"}},
+	{"BQ", "BES", "Bonaire, Sint Eustatius and Saba", nil},
+	{"BR", "BRA", "Brazil", []string{"Federative Republic of Brazil"}},
+	{"BS", "BHS", "Bahamas", []string{"Commonwealth of the Bahamas"}},
+	{"BT", "BTN", "Bhutan", []string{"Kingdom of Bhutan"}},
+	{"BV", "BVT", "Bouvet Island", nil},
+	{"BW", "BWA", "Botswana", []string{"Republic of Botswana"}},
+	{"BY", "BLR", "Belarus", []string{"Republic of Belarus"}},
+	{"BZ", "BLZ", "Belize", nil},
+	{"CA", "CAN", "Canada", nil},
+	{"CC", "CCK", "Cocos (Keeling) Islands", nil},
+	{"CD", "COD", "Congo, The Democratic Republic of the", nil},
+	{"CF", "CAF", "Central African Republic", nil},
+	{"CG", "COG", "Congo", []string{"Republic of the Congo"}},
+	{"CH", "CHE", "Switzerland", []string{"Swiss Confederation"}},
+	{"CI", "CIV", "Côte d'Ivoire", []string{"Republic of Côte d'Ivoire"}},
+	{"CK", "COK", "Cook Islands", nil},
+	{"CL", "CHL", "Chile", []string{"Republic of Chile"}},
+	{"CM", "CMR", "Cameroon", []string{"Republic of Cameroon"}},
+	{"CN", "CHN", "China", []string{"People's Republic of China"}},
+	{"CO", "COL", "Colombia", []string{"Republic of Colombia"}},
+	{"CR", "CRI", "Costa Rica", []string{"Republic of Costa Rica"}},
+	{"CU", "CUB", "Cuba", []string{"Republic of Cuba"}},
+	{"CV", "CPV", "Cabo Verde", []string{"Republic of Cabo Verde"}},
+	{"CW", "CUW", "Curaçao", nil},
+	{"CX", "CXR", "Christmas Island", nil},
+	{"CY", "CYP", "Cyprus", []string{"Republic of Cyprus"}},
+	{"CZ", "CZE", "Czechia", []string{"Czech Republic"}},
+	{"DE", "DEU", "Germany", []string{"Federal Republic of Germany"}},
+	{"DJ", "DJI", "Djibouti", []string{"Republic of Djibouti"}},
+	{"DK", "DNK", "Denmark", []string{"Kingdom of Denmark"}},
+	{"DM", "DMA", "Dominica", []string{"Commonwealth of Dominica"}},
+	{"DO", "DOM", "Dominican Republic", nil},
+	{"DZ", "DZA", "Algeria", []string{"People's Democratic Republic of Algeria"}},
+	{"EC", "ECU", "Ecuador", []string{"Republic of Ecuador"}},
+	{"EE", "EST", "Estonia", []string{"Republic of Estonia"}},
+	{"EG", "EGY", "Egypt", []string{"Arab Republic of Egypt"}},
+	{"EH", "ESH", "Western Sahara", nil},
+	{"ER", "ERI", "Eritrea", []string{"the State of Eritrea"}},
+	{"ES", "ESP", "Spain", []string{"Kingdom of Spain"}},
+	{"ET", "ETH", "Ethiopia", []string{"Federal Democratic Republic of Ethiopia"}},
+	{"FI", "FIN", "Finland", []string{"Republic of Finland"}},
+	{"FJ", "FJI", "Fiji", []string{"Republic of Fiji"}},
+	{"FK", "FLK", "Falkland Islands (Malvinas)", nil},
+	{"FM", "FSM", "Micronesia, Federated States of", []string{"Federated States of Micronesia"}},
+	{"FO", "FRO", "Faroe Islands", nil},
+	{"FR", "FRA", "France", []string{"French Republic"}},
+	{"GA", "GAB", "Gabon", []string{"Gabonese Republic"}},
+	{"GB", "GBR", "United Kingdom", []string{"United Kingdom of Great Britain and Northern Ireland"}},
+	{"GD", "GRD", "Grenada", nil},
+	{"GE", "GEO", "Georgia", nil},
+	{"GF", "GUF", "French Guiana", nil},
+	{"GG", "GGY", "Guernsey", nil},
+	{"GH", "GHA", "Ghana", []string{"Republic of Ghana"}},
+	{"GI", "GIB", "Gibraltar", nil},
+	{"GL", "GRL", "Greenland", nil},
+	{"GM", "GMB", "Gambia", []string{"Republic of the Gambia"}},
+	{"GN", "GIN", "Guinea", []string{"Republic of Guinea"}},
+	{"GP", "GLP", "Guadeloupe", nil},
+	{"GQ", "GNQ", "Equatorial Guinea", []string{"Republic of Equatorial Guinea"}},
+	{"GR", "GRC", "Greece", []string{"Hellenic Republic"}},
+	{"GS", "SGS", "South Georgia and the South Sandwich Islands", nil},
+	{"GT", "GTM", "Guatemala", []string{"Republic of Guatemala"}},
+	{"GU", "GUM", "Guam", nil},
+	{"GW", "GNB", "Guinea-Bissau", []string{"Republic of Guinea-Bissau"}},
+	{"GY", "GUY", "Guyana", []string{"Republic of Guyana"}},
+	{"HK", "HKG", "Hong Kong", []string{"Hong Kong Special Administrative Region of China"}},
+	{"HM", "HMD", "Heard Island and McDonald Islands", nil},
+	{"HN", "HND", "Honduras", []string{"Republic of Honduras"}},
+	{"HR", "HRV", "Croatia", []string{"Republic of Croatia"}},
+	{"HT", "HTI", "Haiti", []string{"Republic of Haiti"}},
+	{"HU", "HUN", "Hungary", nil},
+	{"ID", "IDN", "Indonesia", []string{"Republic of Indonesia"}},
+	{"IE", "IRL", "Ireland", nil},
+	{"IL", "ISR", "Israel", []string{"State of Israel"}},
+	{"IM", "IMN", "Isle of Man", nil},
+	{"IN", "IND", "India", []string{"Republic of India"}},
+	{"IO", "IOT", "British Indian Ocean Territory", nil},
+	{"IQ", "IRQ", "Iraq", []string{"Republic of Iraq"}},
+	{"IR", "IRN", "Iran, Islamic Republic of", []string{"Iran", "Islamic Republic of Iran"}},
+	{"IS", "ISL", "Iceland", []string{"Republic of Iceland"}},
+	{"IT", "ITA", "Italy", []string{"Italian Republic"}},
+	{"JE", "JEY", "Jersey", nil},
+	{"JM", "JAM", "Jamaica", nil},
+	{"JO", "JOR", "Jordan", []string{"Hashemite Kingdom of Jordan"}},
+	{"JP", "JPN", "Japan", nil},
+	{"KE", "KEN", "Kenya", []string{"Republic of Kenya"}},
+	{"KG", "KGZ", "Kyrgyzstan", []string{"Kyrgyz Republic"}},
+	{"KH", "KHM", "Cambodia", []string{"Kingdom of Cambodia"}},
+	{"KI", "KIR", "Kiribati", []string{"Republic of Kiribati"}},
+	{"KM", "COM", "Comoros", []string{"Union of the Comoros"}},
+	{"KN", "KNA", "Saint Kitts and Nevis", nil},
+	{"KP", "PRK", "Korea, Democratic People's Republic of", []string{"North Korea", "Democratic People's Republic of Korea"}},
+	{"KR", "KOR", "Korea, Republic of", []string{"South Korea"}},
+	{"KW", "KWT", "Kuwait", []string{"State of Kuwait"}},
+	{"KY", "CYM", "Cayman Islands", nil},
+	{"KZ", "KAZ", "Kazakhstan", []string{"Republic of Kazakhstan"}},
+	{"LA", "LAO", "Lao People's Democratic Republic", []string{"Laos"}},
+	{"LB", "LBN", "Lebanon", []string{"Lebanese Republic"}},
+	{"LC", "LCA", "Saint Lucia", nil},
+	{"LI", "LIE", "Liechtenstein", []string{"Principality of Liechtenstein"}},
+	{"LK", "LKA", "Sri Lanka", []string{"Democratic Socialist Republic of Sri Lanka"}},
+	{"LR", "LBR", "Liberia", []string{"Republic of Liberia"}},
+	{"LS", "LSO", "Lesotho", []string{"Kingdom of Lesotho"}},
+	{"LT", "LTU", "Lithuania", []string{"Republic of Lithuania"}},
+	{"LU", "LUX", "Luxembourg", []string{"Grand Duchy of Luxembourg"}},
+	{"LV", "LVA", "Latvia", []string{"Republic of Latvia"}},
+	{"LY", "LBY", "Libya", nil},
+	{"MA", "MAR", "Morocco", []string{"Kingdom of Morocco"}},
+	{"MC", "MCO", "Monaco", []string{"Principality of Monaco"}},
+	{"MD", "MDA", "Moldova, Republic of", []string{"Moldova", "Republic of Moldova"}},
+	{"ME", "MNE", "Montenegro", nil},
+	{"MF", "MAF", "Saint Martin (French part)", nil},
+	{"MG", "MDG", "Madagascar", []string{"Republic of Madagascar"}},
+	{"MH", "MHL", "Marshall Islands", []string{"Republic of the Marshall Islands"}},
+	{"MK", "MKD", "North Macedonia", []string{"Republic of North Macedonia"}},
+	{"ML", "MLI", "Mali", []string{"Republic of Mali"}},
+	{"MM", "MMR", "Myanmar", []string{"Republic of Myanmar"}},
+	{"MN", "MNG", "Mongolia", nil},
+	{"MO", "MAC", "Macao", []string{"Macao Special Administrative Region of China"}},
+	{"MP", "MNP", "Northern Mariana Islands", []string{"Commonwealth of the Northern Mariana Islands"}},
+	{"MQ", "MTQ", "Martinique", nil},
+	{"MR", "MRT", "Mauritania", []string{"Islamic Republic of Mauritania"}},
+	{"MS", "MSR", "Montserrat", nil},
+	{"MT", "MLT", "Malta", []string{"Republic of Malta"}},
+	{"MU", "MUS", "Mauritius", []string{"Republic of Mauritius"}},
+	{"MV", "MDV", "Maldives", []string{"Republic of Maldives"}},
+	{"MW", "MWI", "Malawi", []string{"Republic of Malawi"}},
+	{"MX", "MEX", "Mexico", []string{"United Mexican States"}},
+	{"MY", "MYS", "Malaysia", nil},
+	{"MZ", "MOZ", "Mozambique", []string{"Republic of Mozambique"}},
+	{"NA", "NAM", "Namibia", []string{"Republic of Namibia"}},
+	{"NC", "NCL", "New Caledonia", nil},
+	{"NE", "NER", "Niger", []string{"Republic of the Niger"}},
+	{"NF", "NFK", "Norfolk Island", nil},
+	{"NG", "NGA", "Nigeria", []string{"Federal Republic of Nigeria"}},
+	{"NI", "NIC", "Nicaragua", []string{"Republic of Nicaragua"}},
+	{"NL", "NLD", "Netherlands", []string{"Kingdom of the Netherlands"}},
+	{"NO", "NOR", "Norway", []string{"Kingdom of Norway"}},
+	{"NP", "NPL", "Nepal", []string{"Federal Democratic Republic of Nepal"}},
+	{"NR", "NRU", "Nauru", []string{"Republic of Nauru"}},
+	{"NU", "NIU", "Niue", nil},
+	{"NZ", "NZL", "New Zealand", nil},
+	{"OM", "OMN", "Oman", []string{"Sultanate of Oman"}},
+	{"PA", "PAN", "Panama", []string{"Republic of Panama"}},
+	{"PE", "PER", "Peru", []string{"Republic of Peru"}},
+	{"PF", "PYF", "French Polynesia", nil},
+	{"PG", "PNG", "Papua New Guinea", []string{"Independent State of Papua New Guinea"}},
+	{"PH", "PHL", "Philippines", []string{"Republic of the Philippines"}},
+	{"PK", "PAK", "Pakistan", []string{"Islamic Republic of Pakistan"}},
+	{"PL", "POL", "Poland", []string{"Republic of Poland"}},
+	{"PM", "SPM", "Saint Pierre and Miquelon", nil},
+	{"PN", "PCN", "Pitcairn", nil},
+	{"PR", "PRI", "Puerto Rico", nil},
+	{"PS", "PSE", "Palestine, State of", []string{"the State of Palestine"}},
+	{"PT", "PRT", "Portugal", []string{"Portuguese Republic"}},
+	{"PW", "PLW", "Palau", []string{"Republic of Palau"}},
+	{"PY", "PRY", "Paraguay", []string{"Republic of Paraguay"}},
+	{"QA", "QAT", "Qatar", []string{"State of Qatar"}},
+	{"RE", "REU", "Réunion", nil},
+	{"RO", "ROU", "Romania", nil},
+	{"RS", "SRB", "Serbia", []string{"Republic of Serbia"}},
+	{"RU", "RUS", "Russian Federation", nil},
+	{"RW", "RWA", "Rwanda", []string{"Rwandese Republic"}},
+	{"SA", "SAU", "Saudi Arabia", []string{"Kingdom of Saudi Arabia"}},
+	{"SB", "SLB", "Solomon Islands", nil},
+	{"SC", "SYC", "Seychelles", []string{"Republic of Seychelles"}},
+	{"SD", "SDN", "Sudan", []string{"Republic of the Sudan"}},
+	{"SE", "SWE", "Sweden", []string{"Kingdom of Sweden"}},
+	{"SG", "SGP", "Singapore", []string{"Republic of Singapore"}},
+	{"SH", "SHN", "Saint Helena, Ascension and Tristan da Cunha", nil},
+	{"SI", "SVN", "Slovenia", []string{"Republic of Slovenia"}},
+	{"SJ", "SJM", "Svalbard and Jan Mayen", nil},
+	{"SK", "SVK", "Slovakia", []string{"Slovak Republic"}},
+	{"SL", "SLE", "Sierra Leone", []string{"Republic of Sierra Leone"}},
+	{"SM", "SMR", "San Marino", []string{"Republic of San Marino"}},
+	{"SN", "SEN", "Senegal", []string{"Republic of Senegal"}},
+	{"SO", "SOM", "Somalia", []string{"Federal Republic of Somalia"}},
+	{"SR", "SUR", "Suriname", []string{"Republic of Suriname"}},
+	{"SS", "SSD", "South Sudan", []string{"Republic of South Sudan"}},
+	{"ST", "STP", "Sao Tome and Principe", []string{"Democratic Republic of Sao Tome and Principe"}},
+	{"SV", "SLV", "El Salvador", []string{"Republic of El Salvador"}},
+	{"SX", "SXM", "Sint Maarten (Dutch part)", nil},
+	{"SY", "SYR", "Syrian Arab Republic", []string{"Syria"}},
+	{"SZ", "SWZ", "Eswatini", []string{"Kingdom of Eswatini"}},
+	{"TC", "TCA", "Turks and Caicos Islands", nil},
+	{"TD", "TCD", "Chad", []string{"Republic of Chad"}},
+	{"TF", "ATF", "French Southern Territories", nil},
+	{"TG", "TGO", "Togo", []string{"Togolese Republic"}},
+	{"TH", "THA", "Thailand", []string{"Kingdom of Thailand"}},
+	{"TJ", "TJK", "Tajikistan", []string{"Republic of Tajikistan"}},
+	{"TK", "TKL", "Tokelau", nil},
+	{"TL", "TLS", "Timor-Leste", []string{"Democratic Republic of Timor-Leste"}},
+	{"TM", "TKM", "Turkmenistan", nil},
+	{"TN", "TUN", "Tunisia", []string{"Republic of Tunisia"}},
+	{"TO", "TON", "Tonga", []string{"Kingdom of Tonga"}},
+	{"TR", "TUR", "Türkiye", []string{"Republic of Türkiye"}},
+	{"TT", "TTO", "Trinidad and Tobago", []string{"Republic of Trinidad and Tobago"}},
+	{"TV", "TUV", "Tuvalu", nil},
+	{"TW", "TWN", "Taiwan, Province of China", []string{"Taiwan"}},
+	{"TZ", "TZA", "Tanzania, United Republic of", []string{"Tanzania", "United Republic of Tanzania"}},
+	{"UA", "UKR", "Ukraine", nil},
+	{"UG", "UGA", "Uganda", []string{"Republic of Uganda"}},
+	{"UM", "UMI", "United States Minor Outlying Islands", nil},
+	{"US", "USA", "United States", []string{"United States of America"}},
+	{"UY", "URY", "Uruguay", []string{"Eastern Republic of Uruguay"}},
+	{"UZ", "UZB", "Uzbekistan", []string{"Republic of Uzbekistan"}},
+	{"VA", "VAT", "Holy See (Vatican City State)", nil},
+	{"VC", "VCT", "Saint Vincent and the Grenadines", nil},
+	{"VE", "VEN", "Venezuela, Bolivarian Republic of", []string{"Venezuela", "Bolivarian Republic of Venezuela"}},
+	{"VG", "VGB", "Virgin Islands, British", []string{"British Virgin Islands"}},
+	{"VI", "VIR", "Virgin Islands, U.S.", []string{"Virgin Islands of the United States"}},
+	{"VN", "VNM", "Viet Nam", []string{"Vietnam", "Socialist Republic of Viet Nam"}},
+	{"VU", "VUT", "Vanuatu", []string{"Republic of Vanuatu"}},
+	{"WF", "WLF", "Wallis and Futuna", nil},
+	{"WS", "WSM", "Samoa", []string{"Independent State of Samoa"}},
+	{"YE", "YEM", "Yemen", []string{"Republic of Yemen"}},
+	{"YT", "MYT", "Mayotte", nil},
+	{"ZA", "ZAF", "South Africa", []string{"Republic of South Africa"}},
+	{"ZM", "ZMB", "Zambia", []string{"Republic of Zambia"}},
+	{"ZW", "ZWE", "Zimbabwe", []string{"Republic of Zimbabwe"}},
+}
+
+var iso3166Subdivisions = map[string][]iso3166Subdivision{
+	"AU": {
+		{"AU-ACT", "Australian Capital Territory"},
+		{"AU-NSW", "New South Wales"},
+		{"AU-NT", "Northern Territory"},
+		{"AU-QLD", "Queensland"},
+		{"AU-SA", "South Australia"},
+		{"AU-TAS", "Tasmania"},
+		{"AU-VIC", "Victoria"},
+		{"AU-WA", "Western Australia"},
+	},
+	"CA": {
+		{"CA-AB", "Alberta"},
+		{"CA-BC", "British Columbia"},
+		{"CA-MB", "Manitoba"},
+		{"CA-NB", "New Brunswick"},
+		{"CA-NL", "Newfoundland and Labrador"},
+		{"CA-NS", "Nova Scotia"},
+		{"CA-NT", "Northwest Territories"},
+		{"CA-NU", "Nunavut"},
+		{"CA-ON", "Ontario"},
+		{"CA-PE", "Prince Edward Island"},
+		{"CA-QC", "Quebec"},
+		{"CA-SK", "Saskatchewan"},
+		{"CA-YT", "Yukon"},
+	},
+	"MX": {
+		{"MX-AGU", "Aguascalientes"},
+		{"MX-BCN", "Baja California"},
+		{"MX-BCS", "Baja California Sur"},
+		{"MX-CAM", "Campeche"},
+		{"MX-CHH", "Chihuahua"},
+		{"MX-CHP", "Chiapas"},
+		{"MX-CMX", "Ciudad de México"},
+		{"MX-COA", "Coahuila de Zaragoza"},
+		{"MX-COL", "Colima"},
+		{"MX-DUR", "Durango"},
+		{"MX-GRO", "Guerrero"},
+		{"MX-GUA", "Guanajuato"},
+		{"MX-HID", "Hidalgo"},
+		{"MX-JAL", "Jalisco"},
+		{"MX-MEX", "México"},
+		{"MX-MIC", "Michoacán de Ocampo"},
+		{"MX-MOR", "Morelos"},
+		{"MX-NAY", "Nayarit"},
+		{"MX-NLE", "Nuevo León"},
+		{"MX-OAX", "Oaxaca"},
+		{"MX-PUE", "Puebla"},
+		{"MX-QUE", "Querétaro"},
+		{"MX-ROO", "Quintana Roo"},
+		{"MX-SIN", "Sinaloa"},
+		{"MX-SLP", "San Luis Potosí"},
+		{"MX-SON", "Sonora"},
+		{"MX-TAB", "Tabasco"},
+		{"MX-TAM", "Tamaulipas"},
+		{"MX-TLA", "Tlaxcala"},
+		{"MX-VER", "Veracruz de Ignacio de la Llave"},
+		{"MX-YUC", "Yucatán"},
+		{"MX-ZAC", "Zacatecas"},
+	},
+	"US": {
+		{"US-AK", "Alaska"},
+		{"US-AL", "Alabama"},
+		{"US-AR", "Arkansas"},
+		{"US-AS", "American Samoa"},
+		{"US-AZ", "Arizona"},
+		{"US-CA", "California"},
+		{"US-CO", "Colorado"},
+		{"US-CT", "Connecticut"},
+		{"US-DC", "District of Columbia"},
+		{"US-DE", "Delaware"},
+		{"US-FL", "Florida"},
+		{"US-GA", "Georgia"},
+		{"US-GU", "Guam"},
+		{"US-HI", "Hawaii"},
+		{"US-IA", "Iowa"},
+		{"US-ID", "Idaho"},
+		{"US-IL", "Illinois"},
+		{"US-IN", "Indiana"},
+		{"US-KS", "Kansas"},
+		{"US-KY", "Kentucky"},
+		{"US-LA", "Louisiana"},
+		{"US-MA", "Massachusetts"},
+		{"US-MD", "Maryland"},
+		{"US-ME", "Maine"},
+		{"US-MI", "Michigan"},
+		{"US-MN", "Minnesota"},
+		{"US-MO", "Missouri"},
+		{"US-MP", "Northern Mariana Islands"},
+		{"US-MS", "Mississippi"},
+		{"US-MT", "Montana"},
+		{"US-NC", "North Carolina"},
+		{"US-ND", "North Dakota"},
+		{"US-NE", "Nebraska"},
+		{"US-NH", "New Hampshire"},
+		{"US-NJ", "New Jersey"},
+		{"US-NM", "New Mexico"},
+		{"US-NV", "Nevada"},
+		{"US-NY", "New York"},
+		{"US-OH", "Ohio"},
+		{"US-OK", "Oklahoma"},
+		{"US-OR", "Oregon"},
+		{"US-PA", "Pennsylvania"},
+		{"US-PR", "Puerto Rico"},
+		{"US-RI", "Rhode Island"},
+		{"US-SC", "South Carolina"},
+		{"US-SD", "South Dakota"},
+		{"US-TN", "Tennessee"},
+		{"US-TX", "Texas"},
+		{"US-UM", "United States Minor Outlying Islands"},
+		{"US-UT", "Utah"},
+		{"US-VA", "Virginia"},
+		{"US-VI", "Virgin Islands, U.S."},
+		{"US-VT", "Vermont"},
+		{"US-WA", "Washington"},
+		{"US-WI", "Wisconsin"},
+		{"US-WV", "West Virginia"},
+		{"US-WY", "Wyoming"},
+	},
+}
@@ -0,0 +1,51 @@
+package api
+
+import "testing"
+
+func TestNormalizeCountry_namesAndCodesResolved(t *testing.T) {
+	for _, country := range []string{"Canada", "CAN", "ca", " canada "} {
+		countryCode, ok := NormalizeCountry(country)
+		if !ok || countryCode != "CA" {
+			t.Errorf("Country %q was not normalized. Got: %s, expected: %s.", country, countryCode, "CA")
+		}
+	}
+}
+
+func TestNormalizeCountry_unknownCountry(t *testing.T) {
+	_, ok := NormalizeCountry("Atlantis")
+
+	if ok {
+		t.Errorf("An unknown country should not have been normalized.")
+	}
+}
+
+func TestNormalizeSubdivision_namesAndCodesResolved(t *testing.T) {
+	for _, subdivision := range []string{"British Columbia", "BC", "CA-BC", "british columbia"} {
+		subdivisionCode, ok := NormalizeSubdivision("CA", subdivision)
+		if !ok || subdivisionCode != "CA-BC" {
+			t.Errorf("Subdivision %q was not normalized. Got: %s, expected: %s.", subdivision, subdivisionCode, "CA-BC")
+		}
+	}
+}
+
+func TestFindCountryOfSubdivision_ambiguousSubdivision(t *testing.T) {
+	// Washington and Western Australia.
+	_, _, ok := findCountryOfSubdivision("WA")
+
+	if ok {
+		t.Errorf("An ambiguous subdivision should not resolve to a country.")
+	}
+}
+
+func TestNormalizeAddress_countryInferredFromSubdivision(t *testing.T) {
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "British Columbia"}
+
+	attraction.NormalizeAddress()
+
+	if attraction.Country != "CA" || attraction.StateOrProvinceName != "BC" {
+		t.Errorf(
+			"Address was not normalized. Got: %s, %s, expected: BC, CA.",
+			attraction.StateOrProvinceName,
+			attraction.Country)
+	}
+}
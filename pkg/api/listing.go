@@ -0,0 +1,119 @@
+package api
+
+import (
+	"database/sql"
+
+	"../connections"
+)
+
+// DefaultNeighborhoodListingsLimit is how many listings are returned unless asked otherwise.
+const DefaultNeighborhoodListingsLimit = 20
+
+// Listing is a rental listing, from the optional listings table (see README).
+type Listing struct {
+	ID           int64    `json:"id"`
+	Price        *float64 `json:"price,omitempty"`
+	RoomType     string   `json:"room_type,omitempty"`
+	PropertyType string   `json:"property_type,omitempty"`
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+}
+
+// ListingSummary describes the listings within a neighborhood. The median price is omitted for
+// neighborhoods without listings.
+type ListingSummary struct {
+	Count       int      `json:"count"`
+	MedianPrice *float64 `json:"median_price,omitempty"`
+}
+
+// FindNeighborhoodListings returns up to limit listings within the neighborhood, cheapest first. Nothing
+// is returned when the listings table does not exist.
+func FindNeighborhoodListings(neighborhoodID int64, limit int) ([]Listing, error) {
+	listingsQuery := `
+    SELECT listings.id, listings.price, coalesce(listings.room_type, ''), coalesce(listings.property_type, ''),
+        ST_Y(listings.geom), ST_X(listings.geom)
+    FROM neighborhood_geocoding.neighborhoods as neighborhoods
+    JOIN neighborhood_geocoding.listings as listings
+        ON ST_Covers(neighborhoods.geom, listings.geom)
+    WHERE neighborhoods.gid = $1
+    ORDER BY listings.price NULLS LAST, listings.id
+    LIMIT $2
+    `
+
+	exists, err := listingsTableExists()
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := connections.Init().Query(listingsQuery, neighborhoodID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var listings []Listing
+	for rows.Next() {
+		var listing Listing
+		var price sql.NullFloat64
+		if err := rows.Scan(
+			&listing.ID,
+			&price,
+			&listing.RoomType,
+			&listing.PropertyType,
+			&listing.Latitude,
+			&listing.Longitude); err != nil {
+			return nil, err
+		}
+		if price.Valid {
+			listing.Price = &price.Float64
+		}
+		listings = append(listings, listing)
+	}
+
+	return listings, rows.Err()
+}
+
+// SummarizeNeighborhoodListings counts the listings within the neighborhood, with their median price. It
+// returns nil when the listings table does not exist.
+func SummarizeNeighborhoodListings(neighborhoodID int64) (*ListingSummary, error) {
+	exists, err := listingsTableExists()
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	summary, err := summarizeListings(neighborhoodID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+func summarizeListings(neighborhoodID int64) (ListingSummary, error) {
+	listingsQuery := `
+    SELECT count(listings.id), percentile_cont(0.5) WITHIN GROUP (ORDER BY listings.price)
+    FROM neighborhood_geocoding.neighborhoods as neighborhoods
+    JOIN neighborhood_geocoding.listings as listings
+        ON ST_Covers(neighborhoods.geom, listings.geom)
+    WHERE neighborhoods.gid = $1
+    `
+
+	var summary ListingSummary
+	var medianPrice sql.NullFloat64
+	if err := connections.Init().QueryRow(listingsQuery, neighborhoodID).Scan(&summary.Count, &medianPrice); err != nil {
+		return ListingSummary{}, err
+	}
+
+	if medianPrice.Valid {
+		summary.MedianPrice = &medianPrice.Float64
+	}
+
+	return summary, nil
+}
+
+// The listings table is optional (see README).
+func listingsTableExists() (bool, error) {
+	var exists bool
+	err := connections.Init().QueryRow("SELECT to_regclass('neighborhood_geocoding.listings') IS NOT NULL").Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,45 @@
+package api
+
+import "testing"
+
+func TestFindNeighborhoodListings_cheapestFirstWithinLimit(t *testing.T) {
+	downtown := findNeighborhoodAt(t, 49.2820, -123.1171)
+
+	listings, err := FindNeighborhoodListings(downtown.ID, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error finding listings: %v", err)
+	}
+
+	if len(listings) > 5 {
+		t.Errorf("At most 5 listings should be returned. Got: %d.", len(listings))
+	}
+
+	for i := 1; i < len(listings); i++ {
+		previous, current := listings[i-1].Price, listings[i].Price
+		if previous == nil && current != nil || previous != nil && current != nil && *previous > *current {
+			t.Errorf("Listings should be cheapest first, unpriced last. Got: %+v.", listings)
+			break
+		}
+	}
+}
+
+func TestSummarizeNeighborhoodListings_countsEveryListing(t *testing.T) {
+	downtown := findNeighborhoodAt(t, 49.2820, -123.1171)
+
+	summary, err := SummarizeNeighborhoodListings(downtown.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error summarizing listings: %v", err)
+	}
+	if summary == nil {
+		t.Skip("The listings table does not exist.")
+	}
+
+	listings, err := FindNeighborhoodListings(downtown.ID, summary.Count+1)
+	if err != nil {
+		t.Fatalf("Unexpected error finding listings: %v", err)
+	}
+
+	if len(listings) != summary.Count {
+		t.Errorf("The summary should count every listing. Got: %d, expected: %d.", summary.Count, len(listings))
+	}
+}
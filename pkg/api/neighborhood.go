@@ -2,43 +2,71 @@ package api
 
 import (
 	"container/heap"
-	"crypto/md5"
-	"encoding/hex"
+	"database/sql"
+	"fmt"
 	"log"
 	"sort"
-	"strings"
+	"strconv"
+	"time"
 
+	"../cache"
 	"../connections"
+	"../metrics"
 
-	_ "github.com/lib/pq" // Used to interact with PostgreSQL/PostGIS
+	"github.com/lib/pq" // Used to interact with PostgreSQL/PostGIS
 )
 
 // Neighborhood is defined as a localised community within a larger city (i.e, 'Downtown')
 // TODO: make lat/lng a struct
 type Neighborhood struct {
+	// ID is the neighborhood's gid. Names are only unique within a city, so neighborhoods are compared by ID.
+	ID                  int64   `json:"id"`
 	Name                string  `json:"name"`
 	City                string  `json:"city_name"`
 	StateOrProvinceName string  `json:"state_or_province_name"`
 	Country             string  `json:"country"`
 	Latitude            float64 `json:"latitude"`
 	Longitude           float64 `json:"longitude"`
+	// ParentID is the gid of the area containing this one (i.e, a neighborhood's district), or zero.
+	ParentID int64     `json:"parent_id,omitempty"`
+	Level    AreaLevel `json:"level,omitempty"`
+	// Ancestors are the areas containing this one, broadest first, when resolved.
+	Ancestors []Neighborhood `json:"ancestors,omitempty"`
+	// DatasetID is the dataset the boundary was imported from, or zero when unknown.
+	DatasetID int64 `json:"dataset_id,omitempty"`
 }
 
 // FindNeighborhoodContainingAttraction resolves the neighborhood of the given attraction via geocoding.
+// Attractions lying exactly on a neighborhood's edge are considered to be within it. Where areas are
+// nested, the finest area covering the attraction is returned along with its Ancestors, giving the full
+// containment chain. An empty Neighborhood is returned when no neighborhood covers the attraction.
 func FindNeighborhoodContainingAttraction(attraction Attraction) (Neighborhood, error) {
+	return findNeighborhoodContainingAttractionInRegion(attraction, Region{})
+}
+
+// Finds the neighborhood covering the attraction as FindNeighborhoodContainingAttraction does, among the
+// neighborhoods of the region's cities only, unless the region is empty.
+func findNeighborhoodContainingAttractionInRegion(attraction Attraction, region Region) (Neighborhood, error) {
 	attractionInNeighborhoodQuery := `
-        SELECT ST_Contains(neighborhood_poly, attr_point) as in_neighborhood, name, city, state, country
+        SELECT ST_Covers(neighborhood_poly, attr_point) as in_neighborhood, gid, name, city, state, country, parent_gid, level
         FROM (
-            SELECT ST_SetSRID(ST_Point($1, $2),4326) as attr_point, geom as neighborhood_poly, name, city, state, country
-            FROM neighborhood_geocoding.neighborhoods
+            SELECT ST_SetSRID(ST_Point($1, $2),4326) as attr_point, geom as neighborhood_poly, gid, name, city, state, country,
+                coalesce(parent_gid, 0) as parent_gid, coalesce(level, 'neighborhood') as level
+            FROM neighborhood_geocoding.active_neighborhoods as neighborhoods
+            WHERE ` + regionCondition("neighborhoods", 3, 4) + `
         ) as foo
-        WHERE ST_Contains(neighborhood_poly, attr_point) is true
+        WHERE ST_Covers(neighborhood_poly, attr_point) is true
         `
 
+	cities, states := region.citiesAndStates()
+	started := time.Now()
 	rows, err := connections.Init().Query(
 		attractionInNeighborhoodQuery,
 		attraction.Longitude,
-		attraction.Latitude)
+		attraction.Latitude,
+		pq.Array(cities),
+		pq.Array(states))
+	metrics.ObserveQuery("neighborhood_containing_attraction", started)
 
 	if err != nil {
 		return Neighborhood{}, err
@@ -52,12 +80,15 @@ func FindNeighborhoodContainingAttraction(attraction Attraction) (Neighborhood,
 	bestNeighborhoodIdx := 0
 
 	for rows.Next() {
+		var id int64
 		var name string
 		var city string
 		var stateOrProvinceName string
 		var country string
+		var parentID int64
+		var level AreaLevel
 		var inNeighborhood bool
-		if err := rows.Scan(&inNeighborhood, &name, &city, &stateOrProvinceName, &country); err != nil {
+		if err := rows.Scan(&inNeighborhood, &id, &name, &city, &stateOrProvinceName, &country, &parentID, &level); err != nil {
 			return Neighborhood{}, err
 		}
 
@@ -65,7 +96,9 @@ func FindNeighborhoodContainingAttraction(attraction Attraction) (Neighborhood,
 			continue
 		}
 
-		coordinates, err := resolveNeighborhoodMultiPolygonsCentroidPoint(name, city, stateOrProvinceName)
+		coordinates, err := resolveNeighborhoodCenter(
+			Neighborhood{ID: id, Name: name, City: city, StateOrProvinceName: stateOrProvinceName},
+			CentroidScoring)
 
 		if err != nil {
 			log.Printf("Unable to resolve coordinates for %s", name)
@@ -73,8 +106,8 @@ func FindNeighborhoodContainingAttraction(attraction Attraction) (Neighborhood,
 		}
 
 		// TODO: make coordinates struct since there is so much re-use throughout the app
-		latitude := coordinates[0]
-		longitude := coordinates[1]
+		longitude := coordinates[0]
+		latitude := coordinates[1]
 		attractionsCoordinates := []float64{attraction.Longitude, attraction.Latitude}
 		distanceInMeters, err := getDistanceBetweenTwoCoordinates(coordinates, attractionsCoordinates)
 
@@ -83,9 +116,22 @@ func FindNeighborhoodContainingAttraction(attraction Attraction) (Neighborhood,
 			continue
 		}
 
-		neighborhood := Neighborhood{name, city, stateOrProvinceName, country, latitude, longitude}
+		neighborhood := Neighborhood{
+			ID:                  id,
+			Name:                name,
+			City:                city,
+			StateOrProvinceName: stateOrProvinceName,
+			Country:             country,
+			Latitude:            latitude,
+			Longitude:           longitude,
+			ParentID:            parentID,
+			Level:               level,
+		}
 		matchedNeighborhoods = append(matchedNeighborhoods, neighborhood)
-		if distanceInMeters < minDistanceInMeters {
+		// Prefer the finest area, then the one whose center is closest.
+		bestNeighborhood := matchedNeighborhoods[bestNeighborhoodIdx]
+		if i == 0 || level.rank() > bestNeighborhood.Level.rank() ||
+			(level.rank() == bestNeighborhood.Level.rank() && distanceInMeters < minDistanceInMeters) {
 			minDistanceInMeters = distanceInMeters
 			bestNeighborhoodIdx = i
 		}
@@ -96,14 +142,17 @@ func FindNeighborhoodContainingAttraction(attraction Attraction) (Neighborhood,
 		return Neighborhood{}, err
 	}
 
-	return matchedNeighborhoods[bestNeighborhoodIdx], err
+	bestNeighborhood := matchedNeighborhoods[bestNeighborhoodIdx]
+	bestNeighborhood.Ancestors, err = findAncestorAreas(bestNeighborhood)
+	if err != nil {
+		return Neighborhood{}, err
+	}
+
+	return bestNeighborhood, nil
 }
 
-// Returns the coordinates of a MultiPolygon's centroid (if found). idx 0 => latitude, idx 1 => longitude
-func resolveNeighborhoodMultiPolygonsCentroidPoint(
-	neighborhoodName string,
-	neighborhoodCity string,
-	neighborhoodState string) ([]float64, error) {
+// Returns the coordinates of a MultiPolygon's centroid (if found). idx 0 => longitude, idx 1 => latitude
+func resolveNeighborhoodMultiPolygonsCentroidPoint(neighborhoodID int64) ([]float64, error) {
 	centroidQueryStr := `
     SELECT ST_X(coordinates) as longitude, ST_Y(coordinates) as latitude
     FROM (
@@ -111,21 +160,17 @@ func resolveNeighborhoodMultiPolygonsCentroidPoint(
         FROM (
             SELECT geom as multi_poly
             FROM neighborhood_geocoding.neighborhoods
-            WHERE name ilike $1
-                AND city ilike $2
-                AND state ilike $3
+            WHERE gid = $1
             ) as coordinates
         ) as result
     `
 
-	row := connections.Init().QueryRow(
-		centroidQueryStr,
-		neighborhoodName,
-		neighborhoodCity,
-		neighborhoodState)
+	started := time.Now()
+	row := connections.Init().QueryRow(centroidQueryStr, neighborhoodID)
 
 	coordinates := make([]float64, 2)
 	err := row.Scan(&coordinates[0], &coordinates[1])
+	metrics.ObserveQuery("neighborhood_centroid", started)
 
 	if err != nil {
 		return []float64{}, err
@@ -134,6 +179,37 @@ func resolveNeighborhoodMultiPolygonsCentroidPoint(
 	return coordinates, err
 }
 
+// FindCityExtent resolves the bounding box around all known neighborhoods of a city. A
+// NoNeighborhoodFoundError is returned when the city has no neighborhoods.
+func FindCityExtent(city string, stateOrProvinceName string) (BoundingBox, error) {
+	cityExtentQuery := `
+    SELECT ST_YMin(extent), ST_XMin(extent), ST_YMax(extent), ST_XMax(extent)
+    FROM (
+        SELECT ST_Extent(geom) as extent
+        FROM neighborhood_geocoding.active_neighborhoods
+        WHERE city ilike $1
+            AND state ilike $2
+    ) as city_extent
+    WHERE extent is not null
+    `
+
+	started := time.Now()
+	row := connections.Init().QueryRow(cityExtentQuery, city, stateOrProvinceName)
+
+	var extent BoundingBox
+	err := row.Scan(&extent.MinLatitude, &extent.MinLongitude, &extent.MaxLatitude, &extent.MaxLongitude)
+	metrics.ObserveQuery("city_extent", started)
+	if err == sql.ErrNoRows {
+		return BoundingBox{}, &NoNeighborhoodFoundError{fmt.Sprintf("No neighborhoods known for %s, %s.", city, stateOrProvinceName)}
+	}
+
+	if err != nil {
+		return BoundingBox{}, err
+	}
+
+	return extent, nil
+}
+
 // Get distance between two coordinate in meters.
 // See: https://postgis.net/docs/manual-1.4/ST_Distance_Sphere.html
 func getDistanceBetweenTwoCoordinates(point1 []float64, point2 []float64) (float64, error) {
@@ -143,6 +219,7 @@ func getDistanceBetweenTwoCoordinates(point1 []float64, point2 []float64) (float
         ST_SetSRID(ST_Point($3, $4), 4326)
     ) as distance_in_meters`
 
+	started := time.Now()
 	row := connections.Init().QueryRow(
 		pointDistanceQueryStr,
 		point1[0],
@@ -152,6 +229,7 @@ func getDistanceBetweenTwoCoordinates(point1 []float64, point2 []float64) (float
 
 	var distanceInMeters float64
 	err := row.Scan(&distanceInMeters)
+	metrics.ObserveQuery("point_distance", started)
 
 	if err != nil {
 		log.Print(err)
@@ -166,28 +244,43 @@ func getDistanceBetweenTwoCoordinates(point1 []float64, point2 []float64) (float
 // 	a) Having the highest occurrence (frequency)
 //	b) Minimized distance between all other neighborhoods in the list
 func FindBestNeighborhood(neighborhoods []Neighborhood) (Neighborhood, error) {
-	neighborhoodNames, err := findNeighborhoodWithHighestOccurrence(neighborhoods)
+	highestOccurrenceNeighborhoods, err := findHighestOccurrenceNeighborhoods(neighborhoods)
 	if err != nil {
-		log.Printf("Unable to resolve neighborhoods with highest occurrence having error: %v\n", err)
 		return Neighborhood{}, err
 	}
 
+	optimalNeighborhoodName, err := findNeighborhoodWithLeastDistanceToAllOtherNeighborhoods(highestOccurrenceNeighborhoods, CentroidScoring)
+
+	if err == nil {
+		return optimalNeighborhoodName, nil
+	}
+
+	return Neighborhood{}, &NoNeighborhoodFoundError{"Unable to resolve neighborhood after attempting to find best match."}
+}
+
+// Filters the neighborhoods down to those tying for the highest occurrence, keeping duplicates.
+func findHighestOccurrenceNeighborhoods(neighborhoods []Neighborhood) ([]Neighborhood, error) {
+	neighborhoods = withoutEmptyNeighborhoods(neighborhoods)
+	if len(neighborhoods) == 0 {
+		return nil, &NoNeighborhoodFoundError{"No attractions were matched to a neighborhood."}
+	}
+
+	neighborhoodIDs, err := findNeighborhoodWithHighestOccurrence(neighborhoods)
+	if err != nil {
+		log.Printf("Unable to resolve neighborhoods with highest occurrence having error: %v\n", err)
+		return nil, err
+	}
+
 	var highestOccurrenceNeighborhoods []Neighborhood
-	for _, neighborhoodName := range neighborhoodNames {
+	for _, neighborhoodID := range neighborhoodIDs {
 		for _, neighborhood := range neighborhoods {
-			if neighborhoodName == neighborhood.Name {
+			if neighborhoodID == neighborhood.ID {
 				highestOccurrenceNeighborhoods = append(highestOccurrenceNeighborhoods, neighborhood)
 			}
 		}
 	}
 
-	optimalNeighborhoodName, err := findNeighborhoodWithLeastDistanceToAllOtherNeighborhoods(highestOccurrenceNeighborhoods)
-
-	if err == nil {
-		return optimalNeighborhoodName, nil
-	}
-
-	return Neighborhood{}, &NoNeighborhoodFoundError{"Unable to resolve neighborhood after attempting to find best match."}
+	return highestOccurrenceNeighborhoods, nil
 }
 
 // NoNeighborhoodFoundError indicates a neighborhood was not resolved
@@ -199,18 +292,31 @@ func (e *NoNeighborhoodFoundError) Error() string {
 	return e.message
 }
 
-// Finds the neighborhood which has the highest occurrence based on its name.
-func findNeighborhoodWithHighestOccurrence(neighborhoods []Neighborhood) ([]string, error) {
-	neighborhoodFrequency := make(map[string]int)
+// Drops the empty neighborhoods returned for attractions which are not within any neighborhood, so they
+// are not counted as a neighborhood of their own.
+func withoutEmptyNeighborhoods(neighborhoods []Neighborhood) []Neighborhood {
+	var nonEmptyNeighborhoods []Neighborhood
+	for _, neighborhood := range neighborhoods {
+		if neighborhood.ID != 0 {
+			nonEmptyNeighborhoods = append(nonEmptyNeighborhoods, neighborhood)
+		}
+	}
+
+	return nonEmptyNeighborhoods
+}
+
+// Finds the neighborhoods which have the highest occurrence, returning their IDs.
+func findNeighborhoodWithHighestOccurrence(neighborhoods []Neighborhood) ([]int64, error) {
+	neighborhoodFrequency := make(map[int64]int)
 
 	// Construct frequency table
 	for _, neighborhood := range neighborhoods {
-		_, keyExists := neighborhoodFrequency[neighborhood.Name]
+		_, keyExists := neighborhoodFrequency[neighborhood.ID]
 
 		if keyExists {
-			neighborhoodFrequency[neighborhood.Name]++
+			neighborhoodFrequency[neighborhood.ID]++
 		} else {
-			neighborhoodFrequency[neighborhood.Name] = 1
+			neighborhoodFrequency[neighborhood.ID] = 1
 		}
 	}
 
@@ -218,52 +324,60 @@ func findNeighborhoodWithHighestOccurrence(neighborhoods []Neighborhood) ([]stri
 	// occurrence.
 	h := getMaxHeap(neighborhoodFrequency)
 
-	neighborhoodNames, err := findNeighborhoodsWithSameFrequency(h)
+	neighborhoodIDs, err := findNeighborhoodsWithSameFrequency(h)
 	if err != nil {
 		log.Printf("Unable to resolve neighborhoods with the same frequency; having error: %v\n", err)
-		return []string{}, err
+		return []int64{}, err
 	}
 
-	return neighborhoodNames, nil
+	return neighborhoodIDs, nil
 }
 
-// findNeighborhoodsWithSameFrequency returns all neighborhoods that have the same number of entries.
-// Example: {"Downtown": 4, "Southside": 4, "East Bay": 4}
-func findNeighborhoodsWithSameFrequency(h *neighborhoodNameFrequencyMaxHeap) ([]string, error) {
+// findNeighborhoodsWithSameFrequency returns the IDs of all neighborhoods tying for the most entries.
+// Example: {12: 4, 7: 4, 31: 4}
+func findNeighborhoodsWithSameFrequency(h *neighborhoodFrequencyMaxHeap) ([]int64, error) {
 	if h.Len() == 0 {
-		return []string{}, nil
+		return []int64{}, nil
 	}
 
 	if h.Len() == 1 {
 		v := heap.Pop(h)
-		return []string{v.(neighorboodNameFrequency).name}, nil
+		return []int64{v.(neighborhoodFrequency).id}, nil
 	}
 
 	maxCount := 0
-	var neighborhoodNames []string
-	for i := 0; i < h.Len(); i++ {
-		v := heap.Pop(h).(neighorboodNameFrequency)
+	var neighborhoodIDs []int64
+	for h.Len() > 0 {
+		v := heap.Pop(h).(neighborhoodFrequency)
 		if v.count < maxCount {
 			break
 		} else {
 			maxCount = v.count
-			neighborhoodNames = append(neighborhoodNames, v.name)
+			neighborhoodIDs = append(neighborhoodIDs, v.id)
 		}
 	}
 
-	return neighborhoodNames, nil
+	return neighborhoodIDs, nil
 }
 
 // Caching PostGIS calculations on geometric objects is desired as they're computationally, and time expensive.
-func generateNeighborhoodCacheKey(neighborhoodName string, otherNeighborhoodName string) string {
-	// sort to ensure we always get the same hash value for the same two keys.
-	cacheKeyElements := []string{neighborhoodName, otherNeighborhoodName}
+// The cache is shared by every request, so keys identify neighborhoods by ID.
+func generateNeighborhoodCacheKey(
+	strategy ScoringStrategy,
+	neighborhood Neighborhood,
+	otherNeighborhood Neighborhood) string {
+	// sort to ensure we always get the same key for the same two neighborhoods.
+	cacheKeyElements := []string{neighborhoodKey(neighborhood), neighborhoodKey(otherNeighborhood)}
 	sort.Strings(cacheKeyElements)
-	cacheKey := strings.Join(cacheKeyElements, "")
 
-	hasher := md5.New()
-	hasher.Write([]byte(cacheKey))
-	hashedString := hex.EncodeToString(hasher.Sum(nil))
+	return cache.Key("distance", string(strategy), cacheKeyElements[0], cacheKeyElements[1])
+}
+
+// Keys a neighborhood's center, as resolved by the given strategy.
+func generateNeighborhoodCenterCacheKey(strategy ScoringStrategy, neighborhood Neighborhood) string {
+	return cache.Key("center", string(strategy), neighborhoodKey(neighborhood))
+}
 
-	return hashedString
+func neighborhoodKey(neighborhood Neighborhood) string {
+	return strconv.FormatInt(neighborhood.ID, 10)
 }
@@ -0,0 +1,363 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"../connections"
+)
+
+// NeighborhoodFeature is a neighborhood as a GeoJSON Feature (see https://tools.ietf.org/html/rfc7946).
+type NeighborhoodFeature struct {
+	Type       string          `json:"type"`
+	ID         int64           `json:"id,omitempty"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties Neighborhood    `json:"properties"`
+	// GeometryRepaired is set when a written geometry was invalid and has been repaired with ST_MakeValid.
+	GeometryRepaired bool `json:"geometry_repaired,omitempty"`
+}
+
+// InvalidGeometryError indicates a geometry cannot be stored as a neighborhood boundary.
+type InvalidGeometryError struct {
+	message string
+}
+
+func (e *InvalidGeometryError) Error() string {
+	return e.message
+}
+
+// InvalidNeighborhoodError indicates a neighborhood is missing a required property.
+type InvalidNeighborhoodError struct {
+	message string
+}
+
+func (e *InvalidNeighborhoodError) Error() string {
+	return e.message
+}
+
+// NeighborhoodHasChildrenError indicates a neighborhood cannot be deleted while areas within it refer to it
+// as their parent.
+type NeighborhoodHasChildrenError struct {
+	message string
+}
+
+func (e *NeighborhoodHasChildrenError) Error() string {
+	return e.message
+}
+
+// Boundaries are stored as WGS84 multipolygons. Invalid geometries (i.e, self-intersecting rings) are
+// repaired with ST_MakeValid, keeping only their polygons. %s is the GeoJSON query parameter.
+const neighborhoodGeometryFromGeoJSON = "ST_Multi(ST_CollectionExtract(ST_MakeValid(ST_SetSRID(ST_GeomFromGeoJSON(%s), 4326)), 3))"
+
+const neighborhoodFeatureColumns = `
+    gid, name, city, state, country, coalesce(parent_gid, 0), coalesce(level, 'neighborhood'),
+    coalesce(dataset_id, 0), ST_X(ST_Centroid(geom)), ST_Y(ST_Centroid(geom)), ST_AsGeoJSON(geom)
+    `
+
+// ListNeighborhoodsOfCity returns every neighborhood of the city, active or not, with its centroid as
+// coordinates.
+func ListNeighborhoodsOfCity(city string, stateOrProvinceName string) ([]Neighborhood, error) {
+	neighborhoodsQuery := `
+    SELECT gid, name, city, state, country, coalesce(parent_gid, 0), coalesce(level, 'neighborhood'),
+        coalesce(dataset_id, 0), ST_X(ST_Centroid(geom)), ST_Y(ST_Centroid(geom))
+    FROM neighborhood_geocoding.neighborhoods
+    WHERE city ilike $1
+        AND ($2 = '' OR state ilike $2)
+    ORDER BY name, gid
+    `
+
+	rows, err := connections.Init().Query(neighborhoodsQuery, city, stateOrProvinceName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var neighborhoods []Neighborhood
+	for rows.Next() {
+		var neighborhood Neighborhood
+		if err := rows.Scan(
+			&neighborhood.ID,
+			&neighborhood.Name,
+			&neighborhood.City,
+			&neighborhood.StateOrProvinceName,
+			&neighborhood.Country,
+			&neighborhood.ParentID,
+			&neighborhood.Level,
+			&neighborhood.DatasetID,
+			&neighborhood.Longitude,
+			&neighborhood.Latitude); err != nil {
+			return nil, err
+		}
+		neighborhoods = append(neighborhoods, neighborhood)
+	}
+
+	return neighborhoods, rows.Err()
+}
+
+// FindNeighborhoodFeature returns the neighborhood with its boundary. A NoNeighborhoodFoundError is
+// returned when there is no such neighborhood.
+func FindNeighborhoodFeature(neighborhoodID int64) (NeighborhoodFeature, error) {
+	row := connections.Init().QueryRow(
+		"SELECT "+neighborhoodFeatureColumns+" FROM neighborhood_geocoding.neighborhoods WHERE gid = $1",
+		neighborhoodID)
+
+	return scanNeighborhoodFeature(row, neighborhoodID)
+}
+
+// CreateNeighborhood stores a new neighborhood from the feature's properties and geometry, returning it as
+// stored. Precomputed distances are rebuilt to include it; the neighborhood is not stored if they cannot be.
+// An InvalidNeighborhoodError is returned when its parent does not exist.
+func CreateNeighborhood(feature NeighborhoodFeature) (NeighborhoodFeature, error) {
+	properties := feature.Properties
+	if strings.TrimSpace(properties.Name) == "" || strings.TrimSpace(properties.City) == "" {
+		return NeighborhoodFeature{}, &InvalidNeighborhoodError{"A neighborhood requires a name and city."}
+	}
+
+	level, err := ParseAreaLevel(string(properties.Level))
+	if err != nil {
+		return NeighborhoodFeature{}, &InvalidNeighborhoodError{err.Error()}
+	}
+
+	repaired, err := validateNeighborhoodGeometry(feature.Geometry)
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+
+	createNeighborhoodQuery := `
+    INSERT INTO neighborhood_geocoding.neighborhoods (name, city, state, country, parent_gid, level, dataset_id, geom)
+    VALUES ($1, $2, $3, $4, nullif($5, 0), $6, nullif($7, 0), ` + fmt.Sprintf(neighborhoodGeometryFromGeoJSON, "$8") + `)
+    RETURNING ` + neighborhoodFeatureColumns
+
+	created, err := changeBoundaries(false, func(tx *sql.Tx) (NeighborhoodFeature, error) {
+		if properties.ParentID != 0 {
+			// Locking the parent keeps it from being deleted before the new neighborhood refers to it.
+			var parentID int64
+			err := tx.QueryRow(
+				"SELECT gid FROM neighborhood_geocoding.neighborhoods WHERE gid = $1 FOR KEY SHARE",
+				properties.ParentID).Scan(&parentID)
+			if err == sql.ErrNoRows {
+				return NeighborhoodFeature{}, &InvalidNeighborhoodError{fmt.Sprintf("No parent neighborhood with id %d.", properties.ParentID)}
+			}
+			if err != nil {
+				return NeighborhoodFeature{}, err
+			}
+		}
+
+		row := tx.QueryRow(
+			createNeighborhoodQuery,
+			properties.Name,
+			properties.City,
+			properties.StateOrProvinceName,
+			properties.Country,
+			properties.ParentID,
+			string(level),
+			properties.DatasetID,
+			string(feature.Geometry))
+
+		return scanNeighborhoodFeature(row, 0)
+	})
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+	created.GeometryRepaired = repaired
+
+	return created, nil
+}
+
+// ReplaceNeighborhoodGeometry replaces the neighborhood's boundary, returning it as stored. Precomputed
+// distances are rebuilt and cached distances dropped; the boundary is kept if they cannot be rebuilt.
+func ReplaceNeighborhoodGeometry(neighborhoodID int64, geometry json.RawMessage) (NeighborhoodFeature, error) {
+	repaired, err := validateNeighborhoodGeometry(geometry)
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+
+	replaceGeometryQuery := `
+    UPDATE neighborhood_geocoding.neighborhoods
+    SET geom = ` + fmt.Sprintf(neighborhoodGeometryFromGeoJSON, "$2") + `
+    WHERE gid = $1
+    RETURNING ` + neighborhoodFeatureColumns
+
+	replaced, err := changeBoundaries(true, func(tx *sql.Tx) (NeighborhoodFeature, error) {
+		row := tx.QueryRow(replaceGeometryQuery, neighborhoodID, string(geometry))
+		return scanNeighborhoodFeature(row, neighborhoodID)
+	})
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+	replaced.GeometryRepaired = repaired
+
+	return replaced, nil
+}
+
+// RenameNeighborhood changes the neighborhood's name, returning it as stored. The in-memory distance matrix,
+// which names adjacent neighborhoods, is reloaded on next use.
+func RenameNeighborhood(neighborhoodID int64, name string) (NeighborhoodFeature, error) {
+	if strings.TrimSpace(name) == "" {
+		return NeighborhoodFeature{}, &InvalidNeighborhoodError{"A neighborhood requires a name."}
+	}
+
+	renameNeighborhoodQuery := `
+    UPDATE neighborhood_geocoding.neighborhoods
+    SET name = $2
+    WHERE gid = $1
+    RETURNING ` + neighborhoodFeatureColumns
+
+	row := connections.Init().QueryRow(renameNeighborhoodQuery, neighborhoodID, name)
+	renamed, err := scanNeighborhoodFeature(row, neighborhoodID)
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+
+	forgetNeighborhoodDistanceMatrix()
+	return renamed, nil
+}
+
+// DeleteNeighborhood removes the neighborhood along with its precomputed distances. A
+// NoNeighborhoodFoundError is returned when there is no such neighborhood, and a
+// NeighborhoodHasChildrenError when other areas have it as their parent.
+func DeleteNeighborhood(neighborhoodID int64) error {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return err
+	}
+
+	// Locking the neighborhood keeps children from being added to it before it is deleted.
+	var hasChildren bool
+	err = tx.QueryRow(`
+    SELECT EXISTS(SELECT 1 FROM neighborhood_geocoding.neighborhoods WHERE parent_gid = neighborhood.gid)
+    FROM neighborhood_geocoding.neighborhoods as neighborhood
+    WHERE neighborhood.gid = $1
+    FOR UPDATE OF neighborhood
+    `, neighborhoodID).Scan(&hasChildren)
+	if err == nil && hasChildren {
+		err = &NeighborhoodHasChildrenError{fmt.Sprintf("Neighborhood %d is the parent of other areas; delete or move them first.", neighborhoodID)}
+	}
+	if err == sql.ErrNoRows {
+		err = noNeighborhoodWithID(neighborhoodID)
+	}
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM neighborhood_geocoding.neighborhoods WHERE gid = $1", neighborhoodID)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	forgetNeighborhoodDistanceMatrix()
+	return nil
+}
+
+// Checks a GeoJSON geometry can be stored: it must be a polygon or multipolygon in WGS84 (SRID 4326, the
+// GeoJSON default) which is not empty once repaired. Reports whether the geometry needs repairing.
+func validateNeighborhoodGeometry(geometry json.RawMessage) (bool, error) {
+	if len(geometry) == 0 || !json.Valid(geometry) {
+		return false, &InvalidGeometryError{"A GeoJSON geometry is required."}
+	}
+
+	validateGeometryQuery := `
+    SELECT ST_SRID(geom), GeometryType(geom), ST_IsValid(geom), coalesce(ST_IsValidReason(geom), ''),
+        ST_IsEmpty(ST_CollectionExtract(ST_MakeValid(ST_SetSRID(geom, 4326)), 3))
+    FROM (SELECT ST_GeomFromGeoJSON($1) as geom) as input
+    `
+
+	var srid int
+	var geometryType string
+	var valid bool
+	var invalidReason string
+	var emptyOnceRepaired bool
+	err := connections.Init().QueryRow(validateGeometryQuery, string(geometry)).Scan(
+		&srid,
+		&geometryType,
+		&valid,
+		&invalidReason,
+		&emptyOnceRepaired)
+	if err != nil {
+		return false, &InvalidGeometryError{fmt.Sprintf("Unable to read GeoJSON geometry: %v", err)}
+	}
+
+	if srid != 0 && srid != 4326 {
+		return false, &InvalidGeometryError{fmt.Sprintf("Geometry must use SRID 4326 (WGS 84), got %d.", srid)}
+	}
+
+	if geometryType != "POLYGON" && geometryType != "MULTIPOLYGON" {
+		return false, &InvalidGeometryError{fmt.Sprintf("Geometry must be a Polygon or MultiPolygon, got %s.", geometryType)}
+	}
+
+	if emptyOnceRepaired {
+		return false, &InvalidGeometryError{fmt.Sprintf("Geometry is invalid and cannot be repaired: %s.", invalidReason)}
+	}
+
+	return !valid, nil
+}
+
+func scanNeighborhoodFeature(row *sql.Row, neighborhoodID int64) (NeighborhoodFeature, error) {
+	var feature NeighborhoodFeature
+	var geometry string
+	properties := &feature.Properties
+	err := row.Scan(
+		&properties.ID,
+		&properties.Name,
+		&properties.City,
+		&properties.StateOrProvinceName,
+		&properties.Country,
+		&properties.ParentID,
+		&properties.Level,
+		&properties.DatasetID,
+		&properties.Longitude,
+		&properties.Latitude,
+		&geometry)
+	if err == sql.ErrNoRows {
+		return NeighborhoodFeature{}, noNeighborhoodWithID(neighborhoodID)
+	}
+
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+
+	feature.Type = "Feature"
+	feature.ID = properties.ID
+	feature.Geometry = json.RawMessage(geometry)
+	return feature, nil
+}
+
+func noNeighborhoodWithID(neighborhoodID int64) error {
+	return &NoNeighborhoodFoundError{fmt.Sprintf("No neighborhood with id %d.", neighborhoodID)}
+}
+
+// Adds or changes boundaries in the same transaction as rebuilding the precomputed distances, so that
+// neither is stored without the other. Changed boundaries also invalidate every cached center and distance.
+func changeBoundaries(
+	geometryReplaced bool,
+	change func(tx *sql.Tx) (NeighborhoodFeature, error)) (NeighborhoodFeature, error) {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return NeighborhoodFeature{}, err
+	}
+
+	feature, err := change(tx)
+	if err == nil {
+		err = rebuildNeighborhoodDistances(tx)
+	}
+	if err != nil {
+		tx.Rollback()
+		return NeighborhoodFeature{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return NeighborhoodFeature{}, err
+	}
+
+	if geometryReplaced {
+		getDistanceCache().Clear()
+	}
+	forgetNeighborhoodDistanceMatrix()
+
+	return feature, nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const kitsilanoBeachGeometry = `{"type": "Polygon", "coordinates": [[[-123.160, 49.272], [-123.150, 49.272], [-123.150, 49.278], [-123.160, 49.278], [-123.160, 49.272]]]}`
+
+func TestValidateNeighborhoodGeometry_missingGeometry(t *testing.T) {
+	_, err := validateNeighborhoodGeometry(nil)
+
+	if _, ok := err.(*InvalidGeometryError); !ok {
+		t.Errorf("Expected an InvalidGeometryError for a missing geometry. Got: %v.", err)
+	}
+}
+
+func TestValidateNeighborhoodGeometry_pointRejected(t *testing.T) {
+	_, err := validateNeighborhoodGeometry(json.RawMessage(`{"type": "Point", "coordinates": [-123.1038, 49.2734]}`))
+
+	if _, ok := err.(*InvalidGeometryError); !ok {
+		t.Errorf("Expected an InvalidGeometryError for a point. Got: %v.", err)
+	}
+}
+
+func TestValidateNeighborhoodGeometry_otherSRIDRejected(t *testing.T) {
+	geometry := `{"type": "Polygon", "crs": {"type": "name", "properties": {"name": "EPSG:26910"}}, "coordinates": [[[491000, 5457000], [492000, 5457000], [492000, 5458000], [491000, 5457000]]]}`
+
+	_, err := validateNeighborhoodGeometry(json.RawMessage(geometry))
+
+	if _, ok := err.(*InvalidGeometryError); !ok {
+		t.Errorf("Expected an InvalidGeometryError for a projected geometry. Got: %v.", err)
+	}
+}
+
+func TestValidateNeighborhoodGeometry_selfIntersectingPolygonRepaired(t *testing.T) {
+	bowtie := `{"type": "Polygon", "coordinates": [[[-123.160, 49.272], [-123.150, 49.278], [-123.150, 49.272], [-123.160, 49.278], [-123.160, 49.272]]]}`
+
+	repaired, err := validateNeighborhoodGeometry(json.RawMessage(bowtie))
+	if err != nil {
+		t.Fatalf("Unexpected error validating geometry: %v", err)
+	}
+
+	if !repaired {
+		t.Errorf("A self-intersecting polygon should need repairing.")
+	}
+}
+
+func TestCreateNeighborhood_nameRequired(t *testing.T) {
+	feature := NeighborhoodFeature{
+		Geometry:   json.RawMessage(kitsilanoBeachGeometry),
+		Properties: Neighborhood{City: "Vancouver", StateOrProvinceName: "BC"},
+	}
+
+	_, err := CreateNeighborhood(feature)
+
+	if _, ok := err.(*InvalidNeighborhoodError); !ok {
+		t.Errorf("Expected an InvalidNeighborhoodError for a missing name. Got: %v.", err)
+	}
+}
+
+func TestNeighborhoodAdmin_createRenameAndDelete(t *testing.T) {
+	created, err := CreateNeighborhood(NeighborhoodFeature{
+		Geometry:   json.RawMessage(kitsilanoBeachGeometry),
+		Properties: Neighborhood{Name: "Kits Beach", City: "Test City", StateOrProvinceName: "BC", Country: "CA", Level: SubNeighborhoodLevel},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating neighborhood: %v", err)
+	}
+	defer DeleteNeighborhood(created.ID)
+
+	if created.Type != "Feature" || created.ID == 0 || created.Properties.Level != SubNeighborhoodLevel {
+		t.Errorf("Created neighborhood was incorrect. Got: %+v.", created)
+	}
+
+	renamed, err := RenameNeighborhood(created.ID, "Kitsilano Beach")
+	if err != nil || renamed.Properties.Name != "Kitsilano Beach" {
+		t.Errorf("Neighborhood should have been renamed. Got: %+v, %v.", renamed.Properties, err)
+	}
+
+	if err := DeleteNeighborhood(created.ID); err != nil {
+		t.Fatalf("Unexpected error deleting neighborhood: %v", err)
+	}
+
+	if _, err := FindNeighborhoodFeature(created.ID); err == nil {
+		t.Errorf("A deleted neighborhood should not be found.")
+	}
+}
+
+func TestDeleteNeighborhood_unknownNeighborhood(t *testing.T) {
+	err := DeleteNeighborhood(-1)
+
+	if _, ok := err.(*NoNeighborhoodFoundError); !ok {
+		t.Errorf("Expected a NoNeighborhoodFoundError for an unknown neighborhood. Got: %v.", err)
+	}
+}
+
+func TestCreateNeighborhood_unknownParentRejected(t *testing.T) {
+	_, err := CreateNeighborhood(NeighborhoodFeature{
+		Geometry:   json.RawMessage(kitsilanoBeachGeometry),
+		Properties: Neighborhood{Name: "Kits Beach", City: "Test City", StateOrProvinceName: "BC", ParentID: 999999999},
+	})
+
+	if _, ok := err.(*InvalidNeighborhoodError); !ok {
+		t.Errorf("Expected an InvalidNeighborhoodError for an unknown parent. Got: %v.", err)
+	}
+}
+
+func TestDeleteNeighborhood_parentWithChildrenRejected(t *testing.T) {
+	parent, err := CreateNeighborhood(NeighborhoodFeature{
+		Geometry:   json.RawMessage(kitsilanoBeachGeometry),
+		Properties: Neighborhood{Name: "Kitsilano", City: "Test City", StateOrProvinceName: "BC"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating neighborhood: %v", err)
+	}
+	defer DeleteNeighborhood(parent.ID)
+
+	child, err := CreateNeighborhood(NeighborhoodFeature{
+		Geometry:   json.RawMessage(kitsilanoBeachGeometry),
+		Properties: Neighborhood{Name: "Kits Beach", City: "Test City", StateOrProvinceName: "BC", Level: SubNeighborhoodLevel, ParentID: parent.ID},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating neighborhood: %v", err)
+	}
+
+	if err := DeleteNeighborhood(parent.ID); err == nil {
+		t.Fatalf("A parent should not be deleted while it has children.")
+	} else if _, ok := err.(*NeighborhoodHasChildrenError); !ok {
+		t.Errorf("Expected a NeighborhoodHasChildrenError. Got: %v.", err)
+	}
+
+	if err := DeleteNeighborhood(child.ID); err != nil {
+		t.Errorf("Unexpected error deleting child: %v", err)
+	}
+}
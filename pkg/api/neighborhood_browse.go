@@ -0,0 +1,173 @@
+package api
+
+import (
+	"strings"
+
+	"../connections"
+	"github.com/lib/pq"
+)
+
+// DefaultNeighborhoodSearchLimit is how many matches a search returns unless asked otherwise.
+const DefaultNeighborhoodSearchLimit = 10
+
+// Names at least this similar (see pg_trgm's similarity) to a search are considered matches.
+const neighborhoodSearchSimilarityThreshold = 0.2
+
+// CitySummary describes a city having known neighborhoods.
+type CitySummary struct {
+	City                string      `json:"city_name"`
+	StateOrProvinceName string      `json:"state_or_province_name"`
+	Country             string      `json:"country"`
+	NeighborhoodCount   int         `json:"neighborhood_count"`
+	Extent              BoundingBox `json:"extent"`
+}
+
+// NeighborhoodSummary is a neighborhood with its centroid as coordinates, and its area.
+type NeighborhoodSummary struct {
+	Neighborhood
+	AreaInSquareMeters float64 `json:"area_in_square_meters"`
+}
+
+// NeighborhoodSearchResult is a neighborhood whose name matched a search, with its centroid as coordinates.
+type NeighborhoodSearchResult struct {
+	Neighborhood
+	// Similarity ranges from 0 (nothing in common) to 1 (identical names).
+	Similarity float64 `json:"similarity"`
+}
+
+// ListCities returns every city having active neighborhoods, with the number of neighborhoods and their
+// extent.
+func ListCities() ([]CitySummary, error) {
+	citiesQuery := `
+    SELECT city, state, coalesce(max(country), ''), count(*),
+        ST_YMin(ST_Extent(geom)), ST_XMin(ST_Extent(geom)), ST_YMax(ST_Extent(geom)), ST_XMax(ST_Extent(geom))
+    FROM neighborhood_geocoding.active_neighborhoods
+    GROUP BY city, state
+    ORDER BY city, state
+    `
+
+	rows, err := connections.Init().Query(citiesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cities []CitySummary
+	for rows.Next() {
+		var city CitySummary
+		if err := rows.Scan(
+			&city.City,
+			&city.StateOrProvinceName,
+			&city.Country,
+			&city.NeighborhoodCount,
+			&city.Extent.MinLatitude,
+			&city.Extent.MinLongitude,
+			&city.Extent.MaxLatitude,
+			&city.Extent.MaxLongitude); err != nil {
+			return nil, err
+		}
+		cities = append(cities, city)
+	}
+
+	return cities, rows.Err()
+}
+
+// ListActiveNeighborhoods returns the active neighborhoods of a city with their centroids and areas. An
+// empty state matches the city in any state.
+func ListActiveNeighborhoods(city string, stateOrProvinceName string) ([]NeighborhoodSummary, error) {
+	neighborhoodsQuery := `
+    SELECT gid, name, city, state, country, coalesce(parent_gid, 0), coalesce(level, 'neighborhood'),
+        coalesce(dataset_id, 0), ST_X(ST_Centroid(geom)), ST_Y(ST_Centroid(geom)), ST_Area(geom::geography)
+    FROM neighborhood_geocoding.active_neighborhoods
+    WHERE city ilike $1
+        AND ($2 = '' OR state ilike $2)
+    ORDER BY name, gid
+    `
+
+	rows, err := connections.Init().Query(neighborhoodsQuery, city, stateOrProvinceName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var neighborhoods []NeighborhoodSummary
+	for rows.Next() {
+		var neighborhood NeighborhoodSummary
+		if err := rows.Scan(
+			&neighborhood.ID,
+			&neighborhood.Name,
+			&neighborhood.City,
+			&neighborhood.StateOrProvinceName,
+			&neighborhood.Country,
+			&neighborhood.ParentID,
+			&neighborhood.Level,
+			&neighborhood.DatasetID,
+			&neighborhood.Longitude,
+			&neighborhood.Latitude,
+			&neighborhood.AreaInSquareMeters); err != nil {
+			return nil, err
+		}
+		neighborhoods = append(neighborhoods, neighborhood)
+	}
+
+	return neighborhoods, rows.Err()
+}
+
+// SearchNeighborhoods finds active neighborhoods whose names resemble the search, tolerating typos (i.e,
+// "Kitsalano"), most similar first. The search may be restricted to a region's cities; an empty region
+// searches every city. Requires the pg_trgm extension.
+func SearchNeighborhoods(search string, region Region, limit int) ([]NeighborhoodSearchResult, error) {
+	search = strings.TrimSpace(search)
+	if search == "" {
+		return nil, &InvalidNeighborhoodError{"A search query is required."}
+	}
+
+	if limit < 1 {
+		limit = DefaultNeighborhoodSearchLimit
+	}
+
+	searchQuery := `
+    SELECT gid, name, city, state, country, coalesce(parent_gid, 0), coalesce(level, 'neighborhood'),
+        coalesce(dataset_id, 0), ST_X(ST_Centroid(geom)), ST_Y(ST_Centroid(geom)), similarity(name, $1) as name_similarity
+    FROM neighborhood_geocoding.active_neighborhoods as neighborhoods
+    WHERE (similarity(name, $1) >= $4 OR name ilike '%' || $1 || '%')
+        AND ` + regionCondition("neighborhoods", 2, 3) + `
+    ORDER BY name_similarity DESC, name, gid
+    LIMIT $5
+    `
+
+	cities, states := region.citiesAndStates()
+	rows, err := connections.Init().Query(
+		searchQuery,
+		search,
+		pq.Array(cities),
+		pq.Array(states),
+		neighborhoodSearchSimilarityThreshold,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []NeighborhoodSearchResult
+	for rows.Next() {
+		var result NeighborhoodSearchResult
+		if err := rows.Scan(
+			&result.ID,
+			&result.Name,
+			&result.City,
+			&result.StateOrProvinceName,
+			&result.Country,
+			&result.ParentID,
+			&result.Level,
+			&result.DatasetID,
+			&result.Longitude,
+			&result.Latitude,
+			&result.Similarity); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
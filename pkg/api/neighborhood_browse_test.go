@@ -0,0 +1,57 @@
+package api
+
+import "testing"
+
+func TestListCities_vancouverListed(t *testing.T) {
+	cities, err := ListCities()
+	if err != nil {
+		t.Fatalf("Unexpected error listing cities: %v", err)
+	}
+
+	for _, city := range cities {
+		if city.City == "Vancouver" {
+			if city.NeighborhoodCount == 0 || !city.Extent.Contains(49.2734, -123.1038) {
+				t.Errorf("Vancouver's summary was incorrect. Got: %+v.", city)
+			}
+			return
+		}
+	}
+
+	t.Errorf("Vancouver should have been listed. Got: %+v.", cities)
+}
+
+func TestListActiveNeighborhoods_areasResolved(t *testing.T) {
+	neighborhoods, err := ListActiveNeighborhoods("Vancouver", "BC")
+	if err != nil {
+		t.Fatalf("Unexpected error listing neighborhoods: %v", err)
+	}
+
+	if len(neighborhoods) == 0 {
+		t.Fatalf("Vancouver should have neighborhoods.")
+	}
+
+	for _, neighborhood := range neighborhoods {
+		if neighborhood.AreaInSquareMeters <= 0.0 {
+			t.Errorf("%s should have a positive area. Got: %.2f.", neighborhood.Name, neighborhood.AreaInSquareMeters)
+		}
+	}
+}
+
+func TestSearchNeighborhoods_misspelledNameFound(t *testing.T) {
+	results, err := SearchNeighborhoods("Dwntown", Region{Cities: []RegionCity{{"Vancouver", "BC"}}}, DefaultNeighborhoodSearchLimit)
+	if err != nil {
+		t.Fatalf("Unexpected error searching neighborhoods: %v", err)
+	}
+
+	if len(results) == 0 || results[0].Name != "Downtown" {
+		t.Errorf("Expected Downtown to be the best match. Got: %+v.", results)
+	}
+}
+
+func TestSearchNeighborhoods_emptySearch(t *testing.T) {
+	_, err := SearchNeighborhoods("  ", Region{}, DefaultNeighborhoodSearchLimit)
+
+	if _, ok := err.(*InvalidNeighborhoodError); !ok {
+		t.Errorf("Expected an InvalidNeighborhoodError for an empty search. Got: %v.", err)
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import "sort"
+
+// NeighborhoodCandidate is a matched neighborhood considered when picking the best neighborhood, with how
+// it scored.
+type NeighborhoodCandidate struct {
+	Neighborhood
+	// MatchedAttractions is how many attractions were matched to the neighborhood.
+	MatchedAttractions int `json:"matched_attractions"`
+	// Finalists tie for the most matched attractions; only they are measured against each other.
+	Finalist bool `json:"finalist"`
+	// TotalDistanceInMeters is a finalist's total distance to the other finalists or, for
+	// EdgeDistanceScoring, from its edge to every attraction. Lower is better.
+	TotalDistanceInMeters float64 `json:"total_distance_in_meters,omitempty"`
+	// Rank 1 is the best neighborhood.
+	Rank int `json:"rank"`
+}
+
+// ScoreNeighborhoodCandidates scores each distinct neighborhood as FindBestNeighborhoodWithStrategy
+// would, ranked best first. Finalists' coordinates are the center the strategy measured from.
+func ScoreNeighborhoodCandidates(
+	neighborhoods []Neighborhood,
+	attractions []Attraction,
+	strategy ScoringStrategy) ([]NeighborhoodCandidate, error) {
+	finalists, err := findHighestOccurrenceNeighborhoods(neighborhoods)
+	if err != nil {
+		return nil, err
+	}
+
+	var distances map[int64]float64
+	if strategy == EdgeDistanceScoring {
+		distances, err = sumEdgeDistancesToAttractions(finalists, attractions)
+	} else {
+		err = resolveNeighborhoodCenters(finalists, strategy)
+		if err == nil {
+			var graph Graph
+			graph, err = buildNeighborhoodGraph(finalists, strategy)
+			distances = sumEdgeDistances(graph)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	finalistIDs := make(map[int64]bool)
+	for _, finalist := range finalists {
+		finalistIDs[finalist.ID] = true
+	}
+
+	occurrences := make(map[int64]int)
+	for _, neighborhood := range withoutEmptyNeighborhoods(neighborhoods) {
+		occurrences[neighborhood.ID]++
+	}
+
+	// Finalists come first, in the order the best neighborhood is picked from, so ties rank alike.
+	var candidates []NeighborhoodCandidate
+	scored := make(map[int64]bool)
+	for _, neighborhood := range append(finalists, neighborhoods...) {
+		if neighborhood.ID == 0 || scored[neighborhood.ID] {
+			continue
+		}
+		scored[neighborhood.ID] = true
+
+		candidates = append(candidates, NeighborhoodCandidate{
+			Neighborhood:          neighborhood,
+			MatchedAttractions:    occurrences[neighborhood.ID],
+			Finalist:              finalistIDs[neighborhood.ID],
+			TotalDistanceInMeters: distances[neighborhood.ID],
+		})
+	}
+
+	rankNeighborhoodCandidates(candidates)
+	return candidates, nil
+}
+
+// Orders candidates best first: finalists by least total distance, then the rest by most matched
+// attractions. Equal candidates keep their order.
+func rankNeighborhoodCandidates(candidates []NeighborhoodCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Finalist != candidates[j].Finalist {
+			return candidates[i].Finalist
+		}
+
+		if candidates[i].Finalist {
+			return candidates[i].TotalDistanceInMeters < candidates[j].TotalDistanceInMeters
+		}
+
+		return candidates[i].MatchedAttractions > candidates[j].MatchedAttractions
+	})
+
+	for i := range candidates {
+		candidates[i].Rank = i + 1
+	}
+}
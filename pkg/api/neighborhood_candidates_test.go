@@ -0,0 +1,42 @@
+package api
+
+import "testing"
+
+func TestRankNeighborhoodCandidates_finalistsByDistanceThenOthersByAttractions(t *testing.T) {
+	candidates := []NeighborhoodCandidate{
+		{Neighborhood: Neighborhood{ID: 1, Name: "Strathcona"}, MatchedAttractions: 1},
+		{Neighborhood: Neighborhood{ID: 2, Name: "Downtown"}, MatchedAttractions: 3, Finalist: true, TotalDistanceInMeters: 1800},
+		{Neighborhood: Neighborhood{ID: 3, Name: "Mount Pleasant"}, MatchedAttractions: 2},
+		{Neighborhood: Neighborhood{ID: 4, Name: "West End"}, MatchedAttractions: 3, Finalist: true, TotalDistanceInMeters: 1200},
+	}
+
+	rankNeighborhoodCandidates(candidates)
+
+	expectedNames := []string{"West End", "Downtown", "Mount Pleasant", "Strathcona"}
+	for i, candidate := range candidates {
+		if candidate.Name != expectedNames[i] || candidate.Rank != i+1 {
+			t.Errorf("Candidate %d was incorrect. Got: %s ranked %d, expected: %s.", i, candidate.Name, candidate.Rank, expectedNames[i])
+		}
+	}
+}
+
+func TestRankNeighborhoodCandidates_tiedFinalistsKeepTheirOrder(t *testing.T) {
+	candidates := []NeighborhoodCandidate{
+		{Neighborhood: Neighborhood{ID: 1, Name: "Downtown"}, MatchedAttractions: 1, Finalist: true, TotalDistanceInMeters: 500},
+		{Neighborhood: Neighborhood{ID: 2, Name: "West End"}, MatchedAttractions: 1, Finalist: true, TotalDistanceInMeters: 500},
+	}
+
+	rankNeighborhoodCandidates(candidates)
+
+	if candidates[0].Name != "Downtown" {
+		t.Errorf("Tied finalists should keep the order the best neighborhood is picked from. Got: %s first.", candidates[0].Name)
+	}
+}
+
+func TestScoreNeighborhoodCandidates_noMatchedNeighborhoods(t *testing.T) {
+	_, err := ScoreNeighborhoodCandidates([]Neighborhood{{}}, nil, CentroidScoring)
+
+	if _, ok := err.(*NoNeighborhoodFoundError); !ok {
+		t.Errorf("Expected a NoNeighborhoodFoundError. Got: %v.", err)
+	}
+}
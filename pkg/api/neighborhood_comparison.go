@@ -0,0 +1,240 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"../connections"
+	"github.com/lib/pq"
+)
+
+// NeighborhoodComparisonMatrix compares shortlisted neighborhoods against the same attractions. Each
+// neighborhood's distances and travel times are in the order of Attractions.
+type NeighborhoodComparisonMatrix struct {
+	Attractions   []Attraction             `json:"attractions"`
+	Neighborhoods []NeighborhoodComparison `json:"neighborhoods"`
+}
+
+// NeighborhoodComparison is a neighborhood's row of the comparison matrix. Its coordinates are the
+// center distances were measured from.
+type NeighborhoodComparison struct {
+	Neighborhood
+	DistancesInMeters     []float64 `json:"distances_in_meters"`
+	TotalDistanceInMeters float64   `json:"total_distance_in_meters"`
+	MaxDistanceInMeters   float64   `json:"max_distance_in_meters"`
+	// Travel times are only reported when a TravelTimeEstimator is set. Unreachable attractions are null,
+	// and leave the total and maximum unknown.
+	TravelTimesInSeconds     []*float64 `json:"travel_times_in_seconds,omitempty"`
+	TotalTravelTimeInSeconds *float64   `json:"total_travel_time_in_seconds,omitempty"`
+	MaxTravelTimeInSeconds   *float64   `json:"max_travel_time_in_seconds,omitempty"`
+	// Listing statistics are only reported when the listings table exists. The median price is omitted
+	// for neighborhoods without listings.
+	ListingCount       *int     `json:"listing_count,omitempty"`
+	MedianListingPrice *float64 `json:"median_listing_price,omitempty"`
+}
+
+// Distances from a point to every attraction, in order.
+const centerDistancesQuery = `
+    SELECT ST_Distance_Sphere(
+        ST_SetSRID(ST_Point($1, $2), 4326),
+        ST_SetSRID(ST_Point(attractions.longitude, attractions.latitude), 4326)
+    )
+    FROM unnest($3::float8[], $4::float8[]) WITH ORDINALITY as attractions(longitude, latitude, position)
+    ORDER BY attractions.position
+    `
+
+// Distances from a neighborhood's edge to every attraction, in order.
+const edgeDistancesQuery = `
+    SELECT ST_Distance(neighborhoods.geom::geography, ST_SetSRID(ST_Point(attractions.longitude, attractions.latitude), 4326)::geography)
+    FROM neighborhood_geocoding.neighborhoods as neighborhoods,
+        unnest($2::float8[], $3::float8[]) WITH ORDINALITY as attractions(longitude, latitude, position)
+    WHERE neighborhoods.gid = $1
+    ORDER BY attractions.position
+    `
+
+// FindNeighborhoodsByName resolves active neighborhoods by name (ignoring case) within the region's
+// cities. A name may match a neighborhood in several cities; every match is returned. A
+// NoNeighborhoodFoundError lists the names matching none.
+func FindNeighborhoodsByName(names []string, region Region) ([]Neighborhood, error) {
+	neighborhoodsQuery := `
+    SELECT gid, name, city, state, country, coalesce(parent_gid, 0), coalesce(level, 'neighborhood'),
+        coalesce(dataset_id, 0), ST_X(ST_Centroid(geom)), ST_Y(ST_Centroid(geom))
+    FROM neighborhood_geocoding.active_neighborhoods as neighborhoods
+    WHERE lower(name) = lower($1)
+        AND ` + regionCondition("neighborhoods", 2, 3) + `
+    ORDER BY city, state, gid
+    `
+
+	cities, states := region.citiesAndStates()
+	var neighborhoods []Neighborhood
+	var unknownNames []string
+	found := make(map[int64]bool)
+	for _, name := range names {
+		rows, err := connections.Init().Query(neighborhoodsQuery, strings.TrimSpace(name), pq.Array(cities), pq.Array(states))
+		if err != nil {
+			return nil, err
+		}
+
+		matched := false
+		for rows.Next() {
+			var neighborhood Neighborhood
+			if err := rows.Scan(
+				&neighborhood.ID,
+				&neighborhood.Name,
+				&neighborhood.City,
+				&neighborhood.StateOrProvinceName,
+				&neighborhood.Country,
+				&neighborhood.ParentID,
+				&neighborhood.Level,
+				&neighborhood.DatasetID,
+				&neighborhood.Longitude,
+				&neighborhood.Latitude); err != nil {
+				rows.Close()
+				return nil, err
+			}
+
+			matched = true
+			if !found[neighborhood.ID] {
+				found[neighborhood.ID] = true
+				neighborhoods = append(neighborhoods, neighborhood)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		if !matched {
+			unknownNames = append(unknownNames, name)
+		}
+	}
+
+	if len(unknownNames) > 0 {
+		return nil, &NoNeighborhoodFoundError{fmt.Sprintf("No neighborhood named %s in the region.", strings.Join(unknownNames, ", "))}
+	}
+
+	return neighborhoods, nil
+}
+
+// CompareNeighborhoods measures each neighborhood against every attraction, from the center the strategy
+// describes (or, for EdgeDistanceScoring, from its edge). Travel times are from the same center (the
+// centroid for EdgeDistanceScoring) and reported when available.
+func CompareNeighborhoods(
+	neighborhoods []Neighborhood,
+	attractions []Attraction,
+	strategy ScoringStrategy) (NeighborhoodComparisonMatrix, error) {
+	matrix := NeighborhoodComparisonMatrix{Attractions: attractions}
+
+	longitudes := make([]float64, len(attractions))
+	latitudes := make([]float64, len(attractions))
+	destinations := make([][]float64, len(attractions))
+	for i, attraction := range attractions {
+		longitudes[i] = attraction.Longitude
+		latitudes[i] = attraction.Latitude
+		destinations[i] = []float64{attraction.Longitude, attraction.Latitude}
+	}
+
+	listingsExist, err := listingsTableExists()
+	if err != nil {
+		return matrix, err
+	}
+
+	var origins [][]float64
+	for _, neighborhood := range neighborhoods {
+		comparison := NeighborhoodComparison{Neighborhood: neighborhood}
+
+		var rows *sql.Rows
+		if strategy == EdgeDistanceScoring {
+			rows, err = connections.Init().Query(edgeDistancesQuery, neighborhood.ID, pq.Array(longitudes), pq.Array(latitudes))
+		} else {
+			var center []float64
+			center, err = resolveNeighborhoodCenter(neighborhood, strategy)
+			if err != nil {
+				return matrix, err
+			}
+			comparison.Longitude = center[0]
+			comparison.Latitude = center[1]
+			rows, err = connections.Init().Query(centerDistancesQuery, center[0], center[1], pq.Array(longitudes), pq.Array(latitudes))
+		}
+		if err != nil {
+			return matrix, err
+		}
+
+		comparison.DistancesInMeters, err = scanDistances(rows)
+		if err != nil {
+			return matrix, err
+		}
+
+		for _, distanceInMeters := range comparison.DistancesInMeters {
+			comparison.TotalDistanceInMeters += distanceInMeters
+			comparison.MaxDistanceInMeters = maxFloat(comparison.MaxDistanceInMeters, distanceInMeters)
+		}
+
+		if listingsExist {
+			if err := comparison.summarizeListings(); err != nil {
+				return matrix, err
+			}
+		}
+
+		origins = append(origins, []float64{comparison.Longitude, comparison.Latitude})
+		matrix.Neighborhoods = append(matrix.Neighborhoods, comparison)
+	}
+
+	if estimator := getTravelTimeEstimator(); estimator != nil {
+		travelTimes, err := estimator.TravelTimesInSeconds(origins, destinations)
+		if err != nil {
+			// Travel times are a nicety; distances still compare the neighborhoods.
+			log.Printf("Unable to estimate travel times; having error: %v", err)
+		} else {
+			for i := range travelTimes {
+				matrix.Neighborhoods[i].addTravelTimes(travelTimes[i])
+			}
+		}
+	}
+
+	return matrix, nil
+}
+
+func (comparison *NeighborhoodComparison) addTravelTimes(travelTimesInSeconds []*float64) {
+	comparison.TravelTimesInSeconds = travelTimesInSeconds
+
+	var total, max float64
+	for _, travelTime := range travelTimesInSeconds {
+		if travelTime == nil {
+			return
+		}
+		total += *travelTime
+		max = maxFloat(max, *travelTime)
+	}
+
+	comparison.TotalTravelTimeInSeconds = &total
+	comparison.MaxTravelTimeInSeconds = &max
+}
+
+func (comparison *NeighborhoodComparison) summarizeListings() error {
+	summary, err := summarizeListings(comparison.ID)
+	if err != nil {
+		return err
+	}
+
+	comparison.ListingCount = &summary.Count
+	comparison.MedianListingPrice = summary.MedianPrice
+	return nil
+}
+
+func scanDistances(rows *sql.Rows) ([]float64, error) {
+	defer rows.Close()
+
+	distances := []float64{}
+	for rows.Next() {
+		var distanceInMeters float64
+		if err := rows.Scan(&distanceInMeters); err != nil {
+			return nil, err
+		}
+		distances = append(distances, distanceInMeters)
+	}
+
+	return distances, rows.Err()
+}
@@ -0,0 +1,61 @@
+package api
+
+import "testing"
+
+func TestAddTravelTimes_totalAndMaximum(t *testing.T) {
+	first, second := 300.0, 540.0
+	var comparison NeighborhoodComparison
+
+	comparison.addTravelTimes([]*float64{&first, &second})
+
+	if comparison.TotalTravelTimeInSeconds == nil || *comparison.TotalTravelTimeInSeconds != 840 {
+		t.Errorf("Total travel time was incorrect. Got: %v, expected: 840.", comparison.TotalTravelTimeInSeconds)
+	}
+
+	if comparison.MaxTravelTimeInSeconds == nil || *comparison.MaxTravelTimeInSeconds != 540 {
+		t.Errorf("Max travel time was incorrect. Got: %v, expected: 540.", comparison.MaxTravelTimeInSeconds)
+	}
+}
+
+func TestAddTravelTimes_unreachableAttractionLeavesTotalUnknown(t *testing.T) {
+	first := 300.0
+	var comparison NeighborhoodComparison
+
+	comparison.addTravelTimes([]*float64{&first, nil})
+
+	if len(comparison.TravelTimesInSeconds) != 2 {
+		t.Errorf("Every travel time should be reported. Got: %v.", comparison.TravelTimesInSeconds)
+	}
+
+	if comparison.TotalTravelTimeInSeconds != nil || comparison.MaxTravelTimeInSeconds != nil {
+		t.Errorf("An unreachable attraction should leave the total and maximum unknown.")
+	}
+}
+
+func TestFindNeighborhoodsByName_unknownNameReported(t *testing.T) {
+	region := Region{Cities: []RegionCity{{"Vancouver", "BC"}}}
+
+	_, err := FindNeighborhoodsByName([]string{"Downtown", "Atlantis"}, region)
+
+	if _, ok := err.(*NoNeighborhoodFoundError); !ok {
+		t.Errorf("Expected a NoNeighborhoodFoundError for an unknown name. Got: %v.", err)
+	}
+}
+
+func TestCompareNeighborhoods_distancesInAttractionOrder(t *testing.T) {
+	attractions := []Attraction{
+		{Name: "Science World", Latitude: 49.2734, Longitude: -123.1038},
+		{Name: "Canada Place", Latitude: 49.2888, Longitude: -123.1111},
+	}
+	downtown := findNeighborhoodAt(t, 49.2820, -123.1171)
+
+	matrix, err := CompareNeighborhoods([]Neighborhood{downtown}, attractions, EdgeDistanceScoring)
+	if err != nil {
+		t.Fatalf("Unexpected error comparing neighborhoods: %v", err)
+	}
+
+	distances := matrix.Neighborhoods[0].DistancesInMeters
+	if len(distances) != 2 || distances[1] != 0 || distances[0] <= 0 {
+		t.Errorf("Canada Place lies within Downtown and Science World outside it. Got: %v.", distances)
+	}
+}
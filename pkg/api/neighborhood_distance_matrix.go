@@ -0,0 +1,237 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"../connections"
+)
+
+// Precomputed centroid distances and adjacency between every pair of active neighborhoods of the same
+// level, stored in both directions. RefreshNeighborhoodDistances must be run after importing or changing
+// neighborhoods.
+const createNeighborhoodDistancesTable = `
+    CREATE TABLE IF NOT EXISTS neighborhood_geocoding.neighborhood_distances (
+        neighborhood_gid integer references neighborhood_geocoding.neighborhoods (gid) on delete cascade,
+        other_neighborhood_gid integer references neighborhood_geocoding.neighborhoods (gid) on delete cascade,
+        distance_in_meters double precision not null,
+        touches boolean not null,
+
+        primary key (neighborhood_gid, other_neighborhood_gid)
+    )
+    `
+
+const populateNeighborhoodDistancesTable = `
+    INSERT INTO neighborhood_geocoding.neighborhood_distances
+        (neighborhood_gid, other_neighborhood_gid, distance_in_meters, touches)
+    SELECT neighborhood.gid,
+        other_neighborhood.gid,
+        ST_Distance_Sphere(ST_Centroid(neighborhood.geom), ST_Centroid(other_neighborhood.geom)),
+        ST_Touches(neighborhood.geom, other_neighborhood.geom)
+    FROM neighborhood_geocoding.active_neighborhoods as neighborhood
+    JOIN neighborhood_geocoding.active_neighborhoods as other_neighborhood
+        ON neighborhood.gid != other_neighborhood.gid
+            AND coalesce(neighborhood.level, 'neighborhood') = coalesce(other_neighborhood.level, 'neighborhood')
+    `
+
+// Rebuilds of neighborhood_distances hold this transaction-level advisory lock, so concurrent rebuilds
+// (i.e, several replicas starting at once) run one after the other rather than contending row by row.
+const neighborhoodDistancesLockID = 7243190255
+
+// neighborhoodDistanceMatrix is the in-memory copy of neighborhood_distances.
+type neighborhoodDistanceMatrix struct {
+	distances map[neighborhoodPair]float64
+	adjacent  map[int64][]Neighborhood
+	loadedAt  time.Time
+}
+
+// Neighborhood IDs, in the order they were measured from and to.
+type neighborhoodPair [2]int64
+
+// After the matrix fails to load, requests compute distances themselves for this long before it is tried
+// again, rather than each repeating the query.
+const distanceMatrixRetryInterval = time.Minute
+
+// The matrix is reloaded once it is this old, picking up rebuilds run by other processes (i.e, another
+// replica, or -refresh-distances).
+const distanceMatrixMaxAge = 5 * time.Minute
+
+var (
+	distanceMatrixMutex sync.Mutex
+	distanceMatrix      *neighborhoodDistanceMatrix
+	// distanceMatrixRetryAt is when loading the matrix may be tried again after failing.
+	distanceMatrixRetryAt time.Time
+)
+
+// RefreshNeighborhoodDistances rebuilds the neighborhood_distances table from the current neighborhoods
+// and discards the in-memory copy, which is reloaded on next use.
+func RefreshNeighborhoodDistances() error {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := rebuildNeighborhoodDistances(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	forgetNeighborhoodDistanceMatrix()
+	return nil
+}
+
+// RefreshNeighborhoodDistancesUnlessRunning is RefreshNeighborhoodDistances, except that it returns false
+// without rebuilding when another process is already rebuilding the table.
+func RefreshNeighborhoodDistancesUnlessRunning() (bool, error) {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return false, err
+	}
+
+	var locked bool
+	if err := tx.QueryRow("SELECT pg_try_advisory_xact_lock($1)", neighborhoodDistancesLockID).Scan(&locked); err != nil || !locked {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := rebuildNeighborhoodDistances(tx); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	forgetNeighborhoodDistanceMatrix()
+	return true, nil
+}
+
+// Rebuilds the neighborhood_distances table within the transaction, which the caller commits. Waits for
+// any other rebuild to commit first.
+func rebuildNeighborhoodDistances(tx *sql.Tx) error {
+	if _, err := tx.Exec("SELECT pg_advisory_xact_lock($1)", neighborhoodDistancesLockID); err != nil {
+		return err
+	}
+
+	for _, statement := range []string{
+		createNeighborhoodDistancesTable,
+		"DELETE FROM neighborhood_geocoding.neighborhood_distances",
+		populateNeighborhoodDistancesTable,
+	} {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Discards the in-memory copy of neighborhood_distances, which is reloaded on next use.
+func forgetNeighborhoodDistanceMatrix() {
+	distanceMatrixMutex.Lock()
+	distanceMatrix = nil
+	distanceMatrixRetryAt = time.Time{}
+	distanceMatrixMutex.Unlock()
+}
+
+// Returns the distance matrix, loading it with a single query on first use and again once it is
+// distanceMatrixMaxAge old. Returns nil when it has never loaded (i.e, RefreshNeighborhoodDistances has
+// never been run), in which case callers compute distances themselves. After a failed load the previous
+// matrix, if any, is kept, and loading is not tried again for distanceMatrixRetryInterval.
+func loadNeighborhoodDistanceMatrix() *neighborhoodDistanceMatrix {
+	distanceMatrixMutex.Lock()
+	defer distanceMatrixMutex.Unlock()
+
+	now := time.Now()
+	if distanceMatrix != nil && now.Sub(distanceMatrix.loadedAt) < distanceMatrixMaxAge || now.Before(distanceMatrixRetryAt) {
+		return distanceMatrix
+	}
+
+	matrix, err := queryNeighborhoodDistanceMatrix()
+	if err != nil {
+		log.Printf("Unable to load neighborhood distances, retrying in %v; having error: %v", distanceMatrixRetryInterval, err)
+		distanceMatrixRetryAt = now.Add(distanceMatrixRetryInterval)
+		return distanceMatrix
+	}
+
+	distanceMatrix = matrix
+	return distanceMatrix
+}
+
+func queryNeighborhoodDistanceMatrix() (*neighborhoodDistanceMatrix, error) {
+	distanceMatrixQuery := `
+    SELECT distances.neighborhood_gid,
+        other_neighborhood.gid, other_neighborhood.name, other_neighborhood.city, other_neighborhood.state, other_neighborhood.country,
+        coalesce(other_neighborhood.parent_gid, 0), coalesce(other_neighborhood.level, 'neighborhood'),
+        distances.distance_in_meters, distances.touches
+    FROM neighborhood_geocoding.neighborhood_distances as distances
+    JOIN neighborhood_geocoding.neighborhoods as other_neighborhood
+        ON other_neighborhood.gid = distances.other_neighborhood_gid
+    `
+
+	rows, err := connections.Init().Query(distanceMatrixQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matrix := &neighborhoodDistanceMatrix{
+		distances: make(map[neighborhoodPair]float64),
+		adjacent:  make(map[int64][]Neighborhood),
+		loadedAt:  time.Now(),
+	}
+	for rows.Next() {
+		var neighborhoodID int64
+		var otherNeighborhood Neighborhood
+		var distanceInMeters float64
+		var touches bool
+		if err := rows.Scan(
+			&neighborhoodID,
+			&otherNeighborhood.ID,
+			&otherNeighborhood.Name,
+			&otherNeighborhood.City,
+			&otherNeighborhood.StateOrProvinceName,
+			&otherNeighborhood.Country,
+			&otherNeighborhood.ParentID,
+			&otherNeighborhood.Level,
+			&distanceInMeters,
+			&touches); err != nil {
+			return nil, err
+		}
+
+		matrix.distances[neighborhoodPair{neighborhoodID, otherNeighborhood.ID}] = distanceInMeters
+		if touches {
+			matrix.adjacent[neighborhoodID] = append(matrix.adjacent[neighborhoodID], otherNeighborhood)
+		}
+	}
+
+	return matrix, rows.Err()
+}
+
+// lookup finds the centroid distance between two neighborhoods. A nil matrix finds nothing.
+func (matrix *neighborhoodDistanceMatrix) lookup(neighborhood Neighborhood, otherNeighborhood Neighborhood) (float64, bool) {
+	if matrix == nil {
+		return 0.0, false
+	}
+
+	distanceInMeters, ok := matrix.distances[neighborhoodPair{neighborhood.ID, otherNeighborhood.ID}]
+	return distanceInMeters, ok
+}
+
+// FindAdjacentNeighborhoods returns the neighborhoods sharing an edge with the given one, as of the last
+// RefreshNeighborhoodDistances. Adjacent neighborhoods are returned without coordinates.
+func FindAdjacentNeighborhoods(neighborhood Neighborhood) ([]Neighborhood, error) {
+	matrix := loadNeighborhoodDistanceMatrix()
+	if matrix == nil {
+		return nil, &NoNeighborhoodFoundError{"Neighborhood distances have not been computed."}
+	}
+
+	return matrix.adjacent[neighborhood.ID], nil
+}
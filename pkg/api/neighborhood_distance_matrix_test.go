@@ -0,0 +1,98 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNeighborhoodDistanceMatrixLookup_nilMatrixFindsNothing(t *testing.T) {
+	var matrix *neighborhoodDistanceMatrix
+
+	_, found := matrix.lookup(Neighborhood{ID: 1}, Neighborhood{ID: 2})
+
+	if found {
+		t.Errorf("A nil matrix should not find any distances.")
+	}
+}
+
+func TestNeighborhoodDistanceMatrixLookup_sameNameInDifferentCities(t *testing.T) {
+	vancouverDowntown := Neighborhood{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"}
+	seattleDowntown := Neighborhood{ID: 2, Name: "Downtown", City: "Seattle", StateOrProvinceName: "WA"}
+	westEnd := Neighborhood{ID: 3, Name: "West End", City: "Vancouver", StateOrProvinceName: "BC"}
+	matrix := &neighborhoodDistanceMatrix{
+		distances: map[neighborhoodPair]float64{{vancouverDowntown.ID, westEnd.ID}: 1200.0},
+	}
+
+	distanceInMeters, found := matrix.lookup(vancouverDowntown, westEnd)
+	if !found || distanceInMeters != 1200.0 {
+		t.Errorf("Distance was incorrect. Got: %.2f (found: %t), expected: %.2f.", distanceInMeters, found, 1200.0)
+	}
+
+	if _, found := matrix.lookup(seattleDowntown, westEnd); found {
+		t.Errorf("A neighborhood of the same name in another city should not share distances.")
+	}
+}
+
+func TestRefreshNeighborhoodDistances_adjacentNeighborhoodsLoaded(t *testing.T) {
+	if err := RefreshNeighborhoodDistances(); err != nil {
+		t.Fatalf("Unexpected error refreshing neighborhood distances: %v", err)
+	}
+
+	downtown := findNeighborhoodAt(t, 49.2820, -123.1171)
+	adjacentNeighborhoods, err := FindAdjacentNeighborhoods(downtown)
+	if err != nil {
+		t.Fatalf("Unexpected error finding adjacent neighborhoods: %v", err)
+	}
+
+	if len(adjacentNeighborhoods) == 0 {
+		t.Errorf("Downtown should have adjacent neighborhoods.")
+	}
+
+	for _, neighborhood := range adjacentNeighborhoods {
+		if neighborhood.ID == downtown.ID {
+			t.Errorf("A neighborhood should not be adjacent to itself.")
+		}
+	}
+}
+
+func TestLoadNeighborhoodDistanceMatrix_notRetriedUntilIntervalElapses(t *testing.T) {
+	forgetNeighborhoodDistanceMatrix()
+	defer forgetNeighborhoodDistanceMatrix()
+
+	distanceMatrixMutex.Lock()
+	distanceMatrixRetryAt = time.Now().Add(distanceMatrixRetryInterval)
+	distanceMatrixMutex.Unlock()
+
+	if matrix := loadNeighborhoodDistanceMatrix(); matrix != nil {
+		t.Errorf("The matrix should not be loaded again until the retry interval elapses. Got: %+v.", matrix)
+	}
+}
+
+func TestLoadNeighborhoodDistanceMatrix_freshMatrixKept(t *testing.T) {
+	forgetNeighborhoodDistanceMatrix()
+	defer forgetNeighborhoodDistanceMatrix()
+
+	loaded := &neighborhoodDistanceMatrix{loadedAt: time.Now()}
+	distanceMatrixMutex.Lock()
+	distanceMatrix = loaded
+	distanceMatrixMutex.Unlock()
+
+	if matrix := loadNeighborhoodDistanceMatrix(); matrix != loaded {
+		t.Errorf("The matrix should not be reloaded until it is %v old. Got: %+v.", distanceMatrixMaxAge, matrix)
+	}
+}
+
+func TestLoadNeighborhoodDistanceMatrix_staleMatrixKeptUntilRetry(t *testing.T) {
+	forgetNeighborhoodDistanceMatrix()
+	defer forgetNeighborhoodDistanceMatrix()
+
+	stale := &neighborhoodDistanceMatrix{loadedAt: time.Now().Add(-2 * distanceMatrixMaxAge)}
+	distanceMatrixMutex.Lock()
+	distanceMatrix = stale
+	distanceMatrixRetryAt = time.Now().Add(distanceMatrixRetryInterval)
+	distanceMatrixMutex.Unlock()
+
+	if matrix := loadNeighborhoodDistanceMatrix(); matrix != stale {
+		t.Errorf("The stale matrix should be kept after a failed reload. Got: %+v.", matrix)
+	}
+}
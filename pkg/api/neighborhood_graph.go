@@ -15,7 +15,7 @@ type Edge struct {
 // Graph stores all Neighborhoods and their connections between each other.
 type Graph struct {
 	nodes []Neighborhood
-	edges map[string][]Edge
+	edges map[int64][]Edge
 }
 
 func (graph Graph) buildGraphFromNeighborhoods(neighborhoods []Neighborhood) (Graph, error) {
@@ -26,47 +26,70 @@ func (graph Graph) buildGraphFromNeighborhoods(neighborhoods []Neighborhood) (Gr
 	return graph, nil
 }
 
-func findNeighborhoodWithLeastDistanceToAllOtherNeighborhoods(neighborhoods []Neighborhood) (Neighborhood, error) {
-	var graph Graph
-	// Ideally, this would be a thread-safe cache to deal with concurrent requests (i.e, Redis).
-	distanceCache := make(map[string]float64)
+// Neighborhood coordinates are the centers resolved by the given strategy. Precomputed distances are
+// between centroids, so only apply to CentroidScoring.
+func findNeighborhoodWithLeastDistanceToAllOtherNeighborhoods(
+	neighborhoods []Neighborhood,
+	strategy ScoringStrategy) (Neighborhood, error) {
+	graph, err := buildNeighborhoodGraph(neighborhoods, strategy)
+	if err != nil {
+		return Neighborhood{}, err
+	}
+
+	optimalNeighborhood, err := findMinDistanceBetweenNodes(graph)
+	if err != nil {
+		log.Printf("Error after finding optimal neighborhood: %v\n", err)
+		return Neighborhood{}, err
+	}
+
+	return optimalNeighborhood, nil
+}
+
+// Connects every neighborhood to every other, weighing edges by the distance between their coordinates.
+// Only measured distances are cached, as the cache outlives the request.
+func buildNeighborhoodGraph(neighborhoods []Neighborhood, strategy ScoringStrategy) (Graph, error) {
+	graph := Graph{edges: make(map[int64][]Edge)}
+	distanceCache := getDistanceCache()
+
+	var matrix *neighborhoodDistanceMatrix
+	if strategy == CentroidScoring {
+		matrix = loadNeighborhoodDistanceMatrix()
+	}
 
 	for _, neighborhood := range neighborhoods {
 		sourceNode := neighborhood
 		graph.nodes = append(graph.nodes, sourceNode)
-		remainingNeighborhoods := composeDifferingNeighborhoodNamesSlice(neighborhood.Name, neighborhoods)
+		remainingNeighborhoods := composeDifferingNeighborhoodsSlice(neighborhood.ID, neighborhoods)
 		for _, otherNeighborhood := range remainingNeighborhoods {
-			targetNode := neighborhood
-
-			var distanceInMeters float64
-			hashedString := generateNeighborhoodCacheKey(neighborhood.Name, otherNeighborhood.Name)
-			_, ok := distanceCache[hashedString]
-
-			if ok == false {
-				distanceInMeters, _ = getDistanceBetweenTwoCoordinates([]float64{neighborhood.Longitude, neighborhood.Latitude}, []float64{otherNeighborhood.Longitude, otherNeighborhood.Latitude})
-				distanceCache[hashedString] = distanceInMeters
-			} else {
-				distanceInMeters = distanceCache[hashedString]
+			targetNode := otherNeighborhood
+
+			// The matrix is in memory, so the cache (possibly Redis) is only asked about pairs it lacks.
+			distanceInMeters, found := matrix.lookup(neighborhood, otherNeighborhood)
+			if !found {
+				cacheKey := generateNeighborhoodCacheKey(strategy, neighborhood, otherNeighborhood)
+				distanceInMeters, found = distanceCache.GetDistance(cacheKey)
+				if !found {
+					var err error
+					distanceInMeters, err = getDistanceBetweenTwoCoordinates([]float64{neighborhood.Longitude, neighborhood.Latitude}, []float64{otherNeighborhood.Longitude, otherNeighborhood.Latitude})
+					if err != nil {
+						return Graph{}, err
+					}
+					distanceCache.SetDistance(cacheKey, distanceInMeters)
+				}
 			}
 
 			edge := Edge{sourceNode, targetNode, distanceInMeters}
-			graph.edges[neighborhood.Name] = append(graph.edges[neighborhood.Name], edge)
+			graph.edges[neighborhood.ID] = append(graph.edges[neighborhood.ID], edge)
 		}
 	}
 
-	optimalNeighborhood, err := findMinDistanceBetweenNodes(graph)
-	if err != nil {
-		log.Printf("Error after finding optimal neighborhood: %v\n", err)
-		return Neighborhood{}, err
-	}
-
-	return optimalNeighborhood, nil
+	return graph, nil
 }
 
-func composeDifferingNeighborhoodNamesSlice(currentNeighborhoodName string, allNeighborhoodNames []Neighborhood) []Neighborhood {
+func composeDifferingNeighborhoodsSlice(currentNeighborhoodID int64, allNeighborhoods []Neighborhood) []Neighborhood {
 	var newSlice []Neighborhood
-	for _, neighborhood := range allNeighborhoodNames {
-		if currentNeighborhoodName != neighborhood.Name {
+	for _, neighborhood := range allNeighborhoods {
+		if currentNeighborhoodID != neighborhood.ID {
 			newSlice = append(newSlice, neighborhood)
 		}
 	}
@@ -82,21 +105,12 @@ func findMinDistanceBetweenNodes(graph Graph) (Neighborhood, error) {
 		return graph.nodes[0], nil
 	}
 
-	neighborhoodDistanceSums := make(map[string]float64)
-	for sourceNode, edges := range graph.edges {
-		_, ok := neighborhoodDistanceSums[sourceNode]
-		if ok == true {
-			neighborhoodDistanceSums[sourceNode] = 0
-		}
-		for _, targetNode := range edges {
-			neighborhoodDistanceSums[sourceNode] += targetNode.distanceInMeters
-		}
-	}
+	neighborhoodDistanceSums := sumEdgeDistances(graph)
 
 	minValue := math.Inf(1)
 	var bestNeighborhood Neighborhood
 	for _, node := range graph.nodes {
-		nodeDistanceSum := neighborhoodDistanceSums[node.Name]
+		nodeDistanceSum := neighborhoodDistanceSums[node.ID]
 		if nodeDistanceSum < minValue {
 			minValue = nodeDistanceSum
 			bestNeighborhood = node
@@ -105,3 +119,19 @@ func findMinDistanceBetweenNodes(graph Graph) (Neighborhood, error) {
 
 	return bestNeighborhood, nil
 }
+
+// Totals the distances of each neighborhood's edges, by neighborhood ID.
+func sumEdgeDistances(graph Graph) map[int64]float64 {
+	neighborhoodDistanceSums := make(map[int64]float64)
+	for sourceNode, edges := range graph.edges {
+		_, ok := neighborhoodDistanceSums[sourceNode]
+		if ok == true {
+			neighborhoodDistanceSums[sourceNode] = 0
+		}
+		for _, targetNode := range edges {
+			neighborhoodDistanceSums[sourceNode] += targetNode.distanceInMeters
+		}
+	}
+
+	return neighborhoodDistanceSums
+}
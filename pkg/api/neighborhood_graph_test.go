@@ -6,15 +6,15 @@ import "testing"
 func TestFindOptimalNeighborhood_twoNeighborhoodsTiesForDistance(t *testing.T) {
 	g := Graph{}
 	nodes := []Neighborhood{
-		Neighborhood{"Downtown", "Foobar City", "CA", "USA", -3.1, 0.0},
-		Neighborhood{"West Side", "Foobar City", "CA", "USA", -3.2, 0.0},
-		Neighborhood{"Central", "Foobar City", "CA", "USA", -3.3, 0.0}}
+		Neighborhood{ID: 1, Name: "Downtown", City: "Foobar City", StateOrProvinceName: "CA", Country: "USA", Latitude: -3.1},
+		Neighborhood{ID: 2, Name: "West Side", City: "Foobar City", StateOrProvinceName: "CA", Country: "USA", Latitude: -3.2},
+		Neighborhood{ID: 3, Name: "Central", City: "Foobar City", StateOrProvinceName: "CA", Country: "USA", Latitude: -3.3}}
 	g.nodes = nodes
 	// Both "A" and "B" are considered to be optimal here.
-	g.edges = map[string][]Edge{
-		nodes[0].Name: {Edge{nodes[0], nodes[1], 3.0}, Edge{nodes[0], nodes[2], 1.0}},
-		nodes[1].Name: {Edge{nodes[1], nodes[0], 3.0}, Edge{nodes[1], nodes[2], 1.0}},
-		nodes[2].Name: {Edge{nodes[2], nodes[1], 5.0}, Edge{nodes[2], nodes[0], 1.0}}}
+	g.edges = map[int64][]Edge{
+		nodes[0].ID: {Edge{nodes[0], nodes[1], 3.0}, Edge{nodes[0], nodes[2], 1.0}},
+		nodes[1].ID: {Edge{nodes[1], nodes[0], 3.0}, Edge{nodes[1], nodes[2], 1.0}},
+		nodes[2].ID: {Edge{nodes[2], nodes[1], 5.0}, Edge{nodes[2], nodes[0], 1.0}}}
 
 	bestNeighborhood, _ := findMinDistanceBetweenNodes(g)
 
@@ -43,3 +43,47 @@ func TestFindOptimalNeighborhood_emptyGraphGiven(t *testing.T) {
 			expectedOptimalNeighborhood)
 	}
 }
+
+func TestGenerateNeighborhoodCacheKey_sameNameInDifferentCities(t *testing.T) {
+	vancouverDowntown := Neighborhood{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"}
+	seattleDowntown := Neighborhood{ID: 2, Name: "Downtown", City: "Seattle", StateOrProvinceName: "WA"}
+	westEnd := Neighborhood{ID: 3, Name: "West End", City: "Vancouver", StateOrProvinceName: "BC"}
+
+	if generateNeighborhoodCacheKey(CentroidScoring, vancouverDowntown, westEnd) ==
+		generateNeighborhoodCacheKey(CentroidScoring, seattleDowntown, westEnd) {
+		t.Errorf("Neighborhoods of the same name in different cities should have differing keys.")
+	}
+}
+
+func TestGenerateNeighborhoodCacheKey_orderDoesNotMatter(t *testing.T) {
+	downtown := Neighborhood{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"}
+	westEnd := Neighborhood{ID: 3, Name: "West End", City: "Vancouver", StateOrProvinceName: "BC"}
+
+	if generateNeighborhoodCacheKey(CentroidScoring, downtown, westEnd) !=
+		generateNeighborhoodCacheKey(CentroidScoring, westEnd, downtown) {
+		t.Errorf("The key for two neighborhoods should not depend on their order.")
+	}
+}
+
+func TestGenerateNeighborhoodCacheKey_strategiesDoNotShareDistances(t *testing.T) {
+	downtown := Neighborhood{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"}
+	westEnd := Neighborhood{ID: 3, Name: "West End", City: "Vancouver", StateOrProvinceName: "BC"}
+
+	if generateNeighborhoodCacheKey(CentroidScoring, downtown, westEnd) ==
+		generateNeighborhoodCacheKey(PointOnSurfaceScoring, downtown, westEnd) {
+		t.Errorf("Distances measured by different strategies should have differing keys.")
+	}
+}
+
+func TestFindNeighborhoodWithLeastDistanceToAllOtherNeighborhoods_sameNameInDifferentCitiesKeptApart(t *testing.T) {
+	neighborhoods := []Neighborhood{
+		{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"},
+		{ID: 2, Name: "Downtown", City: "Seattle", StateOrProvinceName: "WA"},
+	}
+
+	remainingNeighborhoods := composeDifferingNeighborhoodsSlice(neighborhoods[0].ID, neighborhoods)
+
+	if len(remainingNeighborhoods) != 1 || remainingNeighborhoods[0].ID != neighborhoods[1].ID {
+		t.Errorf("A neighborhood of the same name in another city should remain. Got: %+v.", remainingNeighborhoods)
+	}
+}
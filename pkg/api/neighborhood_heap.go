@@ -2,32 +2,32 @@ package api
 
 import "container/heap"
 
-type neighorboodNameFrequency struct {
-	name  string
+type neighborhoodFrequency struct {
+	id    int64
 	count int
 }
 
-type neighborhoodNameFrequencyMaxHeap []neighorboodNameFrequency
+type neighborhoodFrequencyMaxHeap []neighborhoodFrequency
 
-func getMaxHeap(m map[string]int) *neighborhoodNameFrequencyMaxHeap {
-	h := &neighborhoodNameFrequencyMaxHeap{}
+func getMaxHeap(m map[int64]int) *neighborhoodFrequencyMaxHeap {
+	h := &neighborhoodFrequencyMaxHeap{}
 	heap.Init(h)
 	for k, v := range m {
-		heap.Push(h, neighorboodNameFrequency{k, v})
+		heap.Push(h, neighborhoodFrequency{k, v})
 	}
 
 	return h
 }
 
-func (h neighborhoodNameFrequencyMaxHeap) Less(i, j int) bool { return h[i].count > h[j].count }
-func (h neighborhoodNameFrequencyMaxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
-func (h neighborhoodNameFrequencyMaxHeap) Len() int           { return len(h) }
+func (h neighborhoodFrequencyMaxHeap) Less(i, j int) bool { return h[i].count > h[j].count }
+func (h neighborhoodFrequencyMaxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h neighborhoodFrequencyMaxHeap) Len() int           { return len(h) }
 
-func (h *neighborhoodNameFrequencyMaxHeap) Push(x interface{}) {
-	*h = append(*h, x.(neighorboodNameFrequency))
+func (h *neighborhoodFrequencyMaxHeap) Push(x interface{}) {
+	*h = append(*h, x.(neighborhoodFrequency))
 }
 
-func (h *neighborhoodNameFrequencyMaxHeap) Pop() interface{} {
+func (h *neighborhoodFrequencyMaxHeap) Pop() interface{} {
 	old := *h
 	n := len(old)
 	x := old[n-1]
@@ -6,11 +6,11 @@ import (
 )
 
 func TestMaxHeap_popReturnsLargestElement(t *testing.T) {
-	frequencyMap := map[string]int{
-		"Downtown":   1,
-		"South Side": 5,
-		"East End":   4,
-		"Central":    3,
+	frequencyMap := map[int64]int{
+		1: 1,
+		2: 5,
+		3: 4,
+		4: 3,
 	}
 
 	h := getMaxHeap(frequencyMap)
@@ -18,18 +18,18 @@ func TestMaxHeap_popReturnsLargestElement(t *testing.T) {
 	rootNode := heap.Pop(h)
 
 	expectedRootNodeValue := 5
-	rootNodeValue := rootNode.(neighorboodNameFrequency).count
+	rootNodeValue := rootNode.(neighborhoodFrequency).count
 	if rootNodeValue != expectedRootNodeValue {
 		t.Errorf("The root node's value was incorrect. Expected: %d, got: %d.", expectedRootNodeValue, rootNodeValue)
 	}
 }
 
 func TestGetMaxHeap_rootNodeCorrectlySet(t *testing.T) {
-	frequencyMap := map[string]int{
-		"Downtown":   1,
-		"South Side": 5,
-		"East End":   4,
-		"Central":    3,
+	frequencyMap := map[int64]int{
+		1: 1,
+		2: 5,
+		3: 4,
+		4: 3,
 	}
 
 	h := getMaxHeap(frequencyMap)
@@ -37,11 +37,11 @@ func TestGetMaxHeap_rootNodeCorrectlySet(t *testing.T) {
 		t.Errorf("Number of heap elements was incorrect. Got: %d, expected: %d.", len(frequencyMap), h.Len())
 	}
 
-	rootNode := heap.Pop(h).(neighorboodNameFrequency)
-	expectedRootNodeName := "South Side"
+	rootNode := heap.Pop(h).(neighborhoodFrequency)
+	expectedRootNodeID := int64(2)
 	expectedRootNodeCount := 5
-	if rootNode.name != expectedRootNodeName {
-		t.Errorf("Root node name was incorrect. Got: %s, expected: %s.", rootNode.name, expectedRootNodeName)
+	if rootNode.id != expectedRootNodeID {
+		t.Errorf("Root node ID was incorrect. Got: %d, expected: %d.", rootNode.id, expectedRootNodeID)
 	}
 
 	if rootNode.count != expectedRootNodeCount {
@@ -50,7 +50,7 @@ func TestGetMaxHeap_rootNodeCorrectlySet(t *testing.T) {
 }
 
 func TestGetMaxHeap_heapIsEmptyWhenEmptyMapGiven(t *testing.T) {
-	frequencyMap := map[string]int{}
+	frequencyMap := map[int64]int{}
 
 	h := getMaxHeap(frequencyMap)
 
@@ -61,16 +61,16 @@ func TestGetMaxHeap_heapIsEmptyWhenEmptyMapGiven(t *testing.T) {
 }
 
 func TestMaxHeap_elementsSwapCorrectly(t *testing.T) {
-	frequencyMap := map[string]int{}
+	frequencyMap := map[int64]int{}
 
 	h := getMaxHeap(frequencyMap)
-	heap.Push(h, neighorboodNameFrequency{"foo", 1})
-	heap.Push(h, neighorboodNameFrequency{"bar", 2})
+	heap.Push(h, neighborhoodFrequency{1, 1})
+	heap.Push(h, neighborhoodFrequency{2, 2})
 	i := 0
 	j := 1
 	h.Swap(i, j)
 
-	rootNode := heap.Pop(h).(neighorboodNameFrequency)
+	rootNode := heap.Pop(h).(neighborhoodFrequency)
 	expectedRootNodeCount := 1
 	if rootNode.count != expectedRootNodeCount {
 		t.Errorf("Root node was invalid after swapping. Got: %d, expected: %d.", rootNode.count, expectedRootNodeCount)
@@ -78,11 +78,11 @@ func TestMaxHeap_elementsSwapCorrectly(t *testing.T) {
 }
 
 func TestFindNeighborhoodsWithSameFrequency_onlyOneMaxFrequency(t *testing.T) {
-	frequencyMap := map[string]int{
-		"Downtown":   1,
-		"South Side": 5,
-		"East End":   4,
-		"Central":    3,
+	frequencyMap := map[int64]int{
+		1: 1,
+		2: 5,
+		3: 4,
+		4: 3,
 	}
 
 	maxHeap := getMaxHeap(frequencyMap)
@@ -94,14 +94,14 @@ func TestFindNeighborhoodsWithSameFrequency_onlyOneMaxFrequency(t *testing.T) {
 		t.Errorf("Number of neighborhoods was invalid. Got: %d, expected: %d.", len(neighborhoods), expectedNeighborhoodsCount)
 	}
 
-	expectedNeighborhoodName := "South Side"
-	if neighborhoods[0] != expectedNeighborhoodName {
-		t.Errorf("The returned neighborhood name was not correct. Got: %s, expected: %s.", neighborhoods[0], expectedNeighborhoodName)
+	expectedNeighborhoodID := int64(2)
+	if neighborhoods[0] != expectedNeighborhoodID {
+		t.Errorf("The returned neighborhood ID was not correct. Got: %d, expected: %d.", neighborhoods[0], expectedNeighborhoodID)
 	}
 }
 
 func TestFindNeighborhoodsWithSameFrequency_noHeapEntriesGiven(t *testing.T) {
-	frequencyMap := map[string]int{}
+	frequencyMap := map[int64]int{}
 
 	maxHeap := getMaxHeap(frequencyMap)
 
@@ -114,8 +114,8 @@ func TestFindNeighborhoodsWithSameFrequency_noHeapEntriesGiven(t *testing.T) {
 }
 
 func TestFindNeighborhoodsWithSameFrequency_oneHeapEntryGiven(t *testing.T) {
-	expectedNeighborhoodName := "Downtown"
-	frequencyMap := map[string]int{expectedNeighborhoodName: 1}
+	expectedNeighborhoodID := int64(7)
+	frequencyMap := map[int64]int{expectedNeighborhoodID: 1}
 
 	maxHeap := getMaxHeap(frequencyMap)
 
@@ -126,7 +126,26 @@ func TestFindNeighborhoodsWithSameFrequency_oneHeapEntryGiven(t *testing.T) {
 		t.Errorf("Number of neighborhoods was invalid. Got: %d, expected: %d.", len(neighborhoods), expectedNeighborhoodsCount)
 	}
 
-	if neighborhoods[0] != expectedNeighborhoodName {
-		t.Errorf("The returned neighborhood name was not correct. Got: %s, expected: %s.", neighborhoods[0], expectedNeighborhoodName)
+	if neighborhoods[0] != expectedNeighborhoodID {
+		t.Errorf("The returned neighborhood ID was not correct. Got: %d, expected: %d.", neighborhoods[0], expectedNeighborhoodID)
+	}
+}
+
+func TestFindNeighborhoodsWithSameFrequency_allTiesReturned(t *testing.T) {
+	frequencyMap := map[int64]int{
+		1: 4,
+		2: 4,
+		3: 4,
+		4: 4,
+		5: 1,
+	}
+
+	maxHeap := getMaxHeap(frequencyMap)
+
+	neighborhoods, _ := findNeighborhoodsWithSameFrequency(maxHeap)
+
+	expectedNeighborhoodsCount := 4
+	if len(neighborhoods) != expectedNeighborhoodsCount {
+		t.Errorf("Number of neighborhoods was invalid. Got: %d, expected: %d.", len(neighborhoods), expectedNeighborhoodsCount)
 	}
 }
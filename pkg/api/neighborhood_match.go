@@ -0,0 +1,120 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+
+	"../connections"
+	"github.com/lib/pq"
+)
+
+// NeighborhoodMatchType describes how an attraction was matched to a neighborhood.
+type NeighborhoodMatchType string
+
+const (
+	// CoveringNeighborhoodMatch means the neighborhood contains the attraction, or it lies on its edge.
+	CoveringNeighborhoodMatch NeighborhoodMatchType = "covers"
+	// NearestNeighborhoodMatch means no neighborhood contains the attraction (i.e, it is just offshore or
+	// in a park between neighborhoods) and the closest one within the fallback distance was used.
+	NearestNeighborhoodMatch NeighborhoodMatchType = "nearest"
+)
+
+// DefaultNeighborhoodFallbackDistanceInMeters is how far an attraction may lie from a neighborhood and
+// still be matched to it.
+const DefaultNeighborhoodFallbackDistanceInMeters = DefaultNearCityBufferInMeters
+
+// NeighborhoodMatch is the neighborhood an attraction was matched to, and how.
+type NeighborhoodMatch struct {
+	Neighborhood Neighborhood          `json:"neighborhood"`
+	MatchType    NeighborhoodMatchType `json:"match_type"`
+	// DistanceInMeters between the attraction and the neighborhood's edge; zero for covering matches.
+	DistanceInMeters float64 `json:"distance_in_meters"`
+}
+
+// FindNeighborhoodMatchForAttraction resolves the neighborhood of the attraction's city covering the
+// attraction, falling back to the nearest one within maxDistanceInMeters. A NoNeighborhoodFoundError is
+// returned when neither exists.
+func FindNeighborhoodMatchForAttraction(attraction Attraction, maxDistanceInMeters float64) (NeighborhoodMatch, error) {
+	return FindNeighborhoodMatchInRegion(attraction, NewRegion([]Attraction{attraction}), maxDistanceInMeters)
+}
+
+// FindNeighborhoodMatchInRegion resolves the neighborhood of any of the region's cities covering the
+// attraction, falling back to the nearest one within maxDistanceInMeters. Neighborhoods of every city are
+// considered when the region is empty.
+func FindNeighborhoodMatchInRegion(attraction Attraction, region Region, maxDistanceInMeters float64) (NeighborhoodMatch, error) {
+	neighborhood, err := findNeighborhoodContainingAttractionInRegion(attraction, region)
+	if err != nil {
+		return NeighborhoodMatch{}, err
+	}
+
+	if neighborhood.ID != 0 {
+		return NeighborhoodMatch{neighborhood, CoveringNeighborhoodMatch, 0.0}, nil
+	}
+
+	neighborhood, distanceInMeters, err := findNearestNeighborhood(attraction, region, maxDistanceInMeters)
+	if err == sql.ErrNoRows {
+		return NeighborhoodMatch{}, &NoNeighborhoodFoundError{
+			fmt.Sprintf("No neighborhood within %.0f meters of the attraction.", maxDistanceInMeters)}
+	}
+
+	if err != nil {
+		return NeighborhoodMatch{}, err
+	}
+
+	return NeighborhoodMatch{neighborhood, NearestNeighborhoodMatch, distanceInMeters}, nil
+}
+
+// Finds the neighborhood closest to the attraction within maxDistanceInMeters of its edge, along with that
+// distance. Only neighborhoods of the region's cities are considered, unless the region is empty.
+func findNearestNeighborhood(attraction Attraction, region Region, maxDistanceInMeters float64) (Neighborhood, float64, error) {
+	nearestNeighborhoodQuery := `
+    SELECT gid, name, city, state, country, parent_gid, level, longitude, latitude, distance_in_meters
+    FROM (
+        SELECT gid, name, city, state, country,
+            coalesce(parent_gid, 0) as parent_gid,
+            coalesce(level, 'neighborhood') as level,
+            ST_X(ST_Centroid(geom)) as longitude,
+            ST_Y(ST_Centroid(geom)) as latitude,
+            ST_Distance(geom::geography, ST_SetSRID(ST_Point($1, $2), 4326)::geography) as distance_in_meters
+        FROM neighborhood_geocoding.active_neighborhoods as neighborhoods
+        WHERE ` + regionCondition("neighborhoods", 3, 4) + `
+            AND ST_DWithin(geom::geography, ST_SetSRID(ST_Point($1, $2), 4326)::geography, $5)
+    ) as nearby_neighborhoods
+    ORDER BY distance_in_meters
+    LIMIT 1
+    `
+
+	cities, states := region.citiesAndStates()
+	row := connections.Init().QueryRow(
+		nearestNeighborhoodQuery,
+		attraction.Longitude,
+		attraction.Latitude,
+		pq.Array(cities),
+		pq.Array(states),
+		maxDistanceInMeters)
+
+	var neighborhood Neighborhood
+	var distanceInMeters float64
+	err := row.Scan(
+		&neighborhood.ID,
+		&neighborhood.Name,
+		&neighborhood.City,
+		&neighborhood.StateOrProvinceName,
+		&neighborhood.Country,
+		&neighborhood.ParentID,
+		&neighborhood.Level,
+		&neighborhood.Longitude,
+		&neighborhood.Latitude,
+		&distanceInMeters)
+
+	if err != nil {
+		return Neighborhood{}, 0.0, err
+	}
+
+	neighborhood.Ancestors, err = findAncestorAreas(neighborhood)
+	if err != nil {
+		return Neighborhood{}, 0.0, err
+	}
+
+	return neighborhood, distanceInMeters, nil
+}
@@ -0,0 +1,64 @@
+package api
+
+import "testing"
+
+func TestFindNeighborhoodMatchForAttraction_coveringMatch(t *testing.T) {
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 49.2820, Longitude: -123.1171}
+
+	match, _ := FindNeighborhoodMatchForAttraction(attraction, DefaultNeighborhoodFallbackDistanceInMeters)
+
+	if match.MatchType != CoveringNeighborhoodMatch || match.Neighborhood.Name != "Downtown" {
+		t.Errorf("Expected Downtown to cover the attraction. Got: %s, %s.", match.MatchType, match.Neighborhood.Name)
+	}
+}
+
+func TestFindNeighborhoodMatchForAttraction_offshoreAttractionMatchedToNearest(t *testing.T) {
+	// Just off the Stanley Park seawall, in Burrard Inlet.
+	attraction := Attraction{Name: "Seawall", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 49.3040, Longitude: -123.1250}
+
+	match, err := FindNeighborhoodMatchForAttraction(attraction, 1000.0)
+	if err != nil {
+		t.Fatalf("Unexpected error matching attraction: %v", err)
+	}
+
+	if match.MatchType != NearestNeighborhoodMatch || match.DistanceInMeters <= 0.0 {
+		t.Errorf("Expected a nearest match with a positive distance. Got: %s, %.2f.", match.MatchType, match.DistanceInMeters)
+	}
+}
+
+func TestFindNeighborhoodMatchForAttraction_noNeighborhoodWithinDistance(t *testing.T) {
+	attraction := Attraction{Name: "Foobar", City: "Foobar City", StateOrProvinceName: "CA", Latitude: -32.0, Longitude: 3.00}
+
+	_, err := FindNeighborhoodMatchForAttraction(attraction, DefaultNeighborhoodFallbackDistanceInMeters)
+
+	if _, ok := err.(*NoNeighborhoodFoundError); !ok {
+		t.Errorf("Expected a NoNeighborhoodFoundError. Got: %v.", err)
+	}
+}
+
+func TestFindNeighborhoodMatchInRegion_attractionMatchedAcrossCityLine(t *testing.T) {
+	// Just east of Boundary Road, in Burnaby.
+	attraction := Attraction{Name: "Boundary", City: "Burnaby", StateOrProvinceName: "BC", Latitude: 49.2600, Longitude: -123.0200}
+	region := Region{Cities: []RegionCity{{"Burnaby", "BC"}, {"Vancouver", "BC"}}}
+
+	match, err := FindNeighborhoodMatchInRegion(attraction, region, 1000.0)
+	if err != nil {
+		t.Fatalf("Unexpected error matching attraction: %v", err)
+	}
+
+	if match.Neighborhood.City != "Vancouver" {
+		t.Errorf("Expected a neighborhood of Vancouver. Got: %+v.", match.Neighborhood)
+	}
+}
+
+func TestFindNeighborhoodMatchInRegion_coveringNeighborhoodOutsideRegionIgnored(t *testing.T) {
+	// Science World is covered by Downtown, Vancouver, which is not part of the region.
+	attraction := Attraction{Name: "Science World", City: "Burnaby", StateOrProvinceName: "BC", Latitude: 49.2820, Longitude: -123.1171}
+	region := Region{Cities: []RegionCity{{"Burnaby", "BC"}}}
+
+	match, err := FindNeighborhoodMatchInRegion(attraction, region, 1000.0)
+
+	if err == nil && match.Neighborhood.City == "Vancouver" {
+		t.Errorf("Expected a neighborhood outside the region to be ignored. Got: %+v.", match.Neighborhood)
+	}
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+
+	"../connections"
+	"github.com/lib/pq"
+)
+
+// ScoringStrategy selects how distances are measured when choosing between neighborhoods which tie for
+// the most attractions.
+type ScoringStrategy string
+
+const (
+	// CentroidScoring measures between neighborhood centroids. A centroid may lie outside an oddly shaped
+	// neighborhood (i.e, a crescent, or a multipolygon of islands).
+	CentroidScoring ScoringStrategy = "centroid"
+	// PointOnSurfaceScoring measures between points guaranteed to lie within each neighborhood.
+	PointOnSurfaceScoring ScoringStrategy = "point_on_surface"
+	// PopulationWeightedScoring measures between each neighborhood's center of population, using the
+	// population_areas table. Neighborhoods without population data fall back to their centroid.
+	PopulationWeightedScoring ScoringStrategy = "population_weighted"
+	// ListingDensityWeightedScoring measures between the mean location of each neighborhood's listings,
+	// using the listings table. Neighborhoods without listings fall back to their centroid.
+	ListingDensityWeightedScoring ScoringStrategy = "listing_density_weighted"
+	// EdgeDistanceScoring picks the neighborhood whose edge is closest to all attractions in total, which
+	// favours large neighborhoods less than centroid distances do.
+	EdgeDistanceScoring ScoringStrategy = "edge_distance"
+)
+
+// DefaultScoringStrategy is used when no strategy is requested.
+const DefaultScoringStrategy = CentroidScoring
+
+// Queries resolving a neighborhood's center for each center-based strategy, as (longitude, latitude).
+// Weighted strategies return no rows when there is no data to weight by.
+var neighborhoodCenterQueries = map[ScoringStrategy]string{
+	PointOnSurfaceScoring: `
+    SELECT ST_X(center) as longitude, ST_Y(center) as latitude
+    FROM (
+        SELECT ST_PointOnSurface(geom) as center
+        FROM neighborhood_geocoding.neighborhoods
+        WHERE gid = $1
+    ) as centers
+    `,
+	PopulationWeightedScoring: `
+    SELECT sum(ST_X(ST_Centroid(population_areas.geom)) * population_areas.population) / sum(population_areas.population) as longitude,
+        sum(ST_Y(ST_Centroid(population_areas.geom)) * population_areas.population) / sum(population_areas.population) as latitude
+    FROM neighborhood_geocoding.neighborhoods as neighborhoods
+    JOIN neighborhood_geocoding.population_areas as population_areas
+        ON ST_Covers(neighborhoods.geom, ST_Centroid(population_areas.geom))
+    WHERE neighborhoods.gid = $1
+    HAVING sum(population_areas.population) > 0
+    `,
+	ListingDensityWeightedScoring: `
+    SELECT avg(ST_X(listings.geom)) as longitude, avg(ST_Y(listings.geom)) as latitude
+    FROM neighborhood_geocoding.neighborhoods as neighborhoods
+    JOIN neighborhood_geocoding.listings as listings
+        ON ST_Covers(neighborhoods.geom, listings.geom)
+    WHERE neighborhoods.gid = $1
+    HAVING count(*) > 0
+    `,
+}
+
+// ParseScoringStrategy validates a strategy name, returning DefaultScoringStrategy for an empty one.
+func ParseScoringStrategy(name string) (ScoringStrategy, error) {
+	strategy := ScoringStrategy(name)
+	switch strategy {
+	case "":
+		return DefaultScoringStrategy, nil
+	case CentroidScoring, PointOnSurfaceScoring, PopulationWeightedScoring, ListingDensityWeightedScoring, EdgeDistanceScoring:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown scoring strategy %q", name)
+	}
+}
+
+// FindBestNeighborhoodWithStrategy resolves the best neighborhood as FindBestNeighborhood does, measuring
+// distances as the strategy describes. The edge distance strategy measures against the given attractions;
+// the others only need the neighborhoods. The returned neighborhood's coordinates are the center the
+// strategy used.
+func FindBestNeighborhoodWithStrategy(
+	neighborhoods []Neighborhood,
+	attractions []Attraction,
+	strategy ScoringStrategy) (Neighborhood, error) {
+	if strategy == CentroidScoring {
+		return FindBestNeighborhood(neighborhoods)
+	}
+
+	highestOccurrenceNeighborhoods, err := findHighestOccurrenceNeighborhoods(neighborhoods)
+	if err != nil {
+		return Neighborhood{}, err
+	}
+
+	if strategy == EdgeDistanceScoring {
+		return findNeighborhoodWithLeastEdgeDistanceToAttractions(highestOccurrenceNeighborhoods, attractions)
+	}
+
+	if err := resolveNeighborhoodCenters(highestOccurrenceNeighborhoods, strategy); err != nil {
+		return Neighborhood{}, err
+	}
+
+	optimalNeighborhood, err := findNeighborhoodWithLeastDistanceToAllOtherNeighborhoods(highestOccurrenceNeighborhoods, strategy)
+	if err != nil {
+		return Neighborhood{}, &NoNeighborhoodFoundError{"Unable to resolve neighborhood after attempting to find best match."}
+	}
+
+	return optimalNeighborhood, nil
+}
+
+// Moves each neighborhood's coordinates to its center according to the strategy. Each neighborhood
+// appears once per attraction within it, so each center is only resolved once.
+func resolveNeighborhoodCenters(neighborhoods []Neighborhood, strategy ScoringStrategy) error {
+	centers := make(map[int64][]float64)
+	for i, neighborhood := range neighborhoods {
+		center, ok := centers[neighborhood.ID]
+		if !ok {
+			var err error
+			center, err = resolveNeighborhoodCenter(neighborhood, strategy)
+			if err != nil {
+				return err
+			}
+			centers[neighborhood.ID] = center
+		}
+
+		neighborhoods[i].Longitude = center[0]
+		neighborhoods[i].Latitude = center[1]
+	}
+
+	return nil
+}
+
+// Returns the neighborhood's center according to the strategy. idx 0 => longitude, idx 1 => latitude
+func resolveNeighborhoodCenter(neighborhood Neighborhood, strategy ScoringStrategy) ([]float64, error) {
+	cacheKey := generateNeighborhoodCenterCacheKey(strategy, neighborhood)
+	if center, ok := getDistanceCache().GetCoordinates(cacheKey); ok {
+		return center, nil
+	}
+
+	center, err := queryNeighborhoodCenter(neighborhood, strategy)
+	if err != nil {
+		return []float64{}, err
+	}
+
+	getDistanceCache().SetCoordinates(cacheKey, center)
+	return center, nil
+}
+
+func queryNeighborhoodCenter(neighborhood Neighborhood, strategy ScoringStrategy) ([]float64, error) {
+	centerQuery, ok := neighborhoodCenterQueries[strategy]
+	if !ok {
+		return resolveNeighborhoodMultiPolygonsCentroidPoint(neighborhood.ID)
+	}
+
+	row := connections.Init().QueryRow(centerQuery, neighborhood.ID)
+
+	center := make([]float64, 2)
+	err := row.Scan(&center[0], &center[1])
+	if err == sql.ErrNoRows {
+		log.Printf("No %s data for %s; using its centroid", strategy, neighborhood.Name)
+		return resolveNeighborhoodMultiPolygonsCentroidPoint(neighborhood.ID)
+	}
+
+	if err != nil {
+		return []float64{}, err
+	}
+
+	return center, nil
+}
+
+// Picks the neighborhood with the smallest total distance from its edge to every attraction. Attractions
+// within a neighborhood are zero meters from it.
+func findNeighborhoodWithLeastEdgeDistanceToAttractions(neighborhoods []Neighborhood, attractions []Attraction) (Neighborhood, error) {
+	edgeDistances, err := sumEdgeDistancesToAttractions(neighborhoods, attractions)
+	if err != nil {
+		return Neighborhood{}, err
+	}
+
+	minDistanceInMeters := math.Inf(1)
+	var bestNeighborhood Neighborhood
+	for _, neighborhood := range neighborhoods {
+		if distanceInMeters := edgeDistances[neighborhood.ID]; distanceInMeters < minDistanceInMeters {
+			minDistanceInMeters = distanceInMeters
+			bestNeighborhood = neighborhood
+		}
+	}
+
+	return bestNeighborhood, nil
+}
+
+// Totals the distances from each neighborhood's edge to every attraction, by neighborhood ID.
+func sumEdgeDistancesToAttractions(neighborhoods []Neighborhood, attractions []Attraction) (map[int64]float64, error) {
+	longitudes := make([]float64, len(attractions))
+	latitudes := make([]float64, len(attractions))
+	for i, attraction := range attractions {
+		longitudes[i] = attraction.Longitude
+		latitudes[i] = attraction.Latitude
+	}
+
+	edgeDistanceQuery := `
+    SELECT coalesce(sum(ST_Distance(neighborhoods.geom::geography, ST_SetSRID(ST_Point(attractions.longitude, attractions.latitude), 4326)::geography)), 0)
+    FROM neighborhood_geocoding.neighborhoods as neighborhoods,
+        unnest($2::float8[], $3::float8[]) as attractions(longitude, latitude)
+    WHERE neighborhoods.gid = $1
+    `
+
+	edgeDistances := make(map[int64]float64)
+	for _, neighborhood := range neighborhoods {
+		if _, measured := edgeDistances[neighborhood.ID]; measured {
+			continue
+		}
+
+		row := connections.Init().QueryRow(
+			edgeDistanceQuery,
+			neighborhood.ID,
+			pq.Array(longitudes),
+			pq.Array(latitudes))
+
+		var distanceInMeters float64
+		if err := row.Scan(&distanceInMeters); err != nil {
+			return nil, err
+		}
+		edgeDistances[neighborhood.ID] = distanceInMeters
+	}
+
+	return edgeDistances, nil
+}
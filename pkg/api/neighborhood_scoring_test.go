@@ -0,0 +1,55 @@
+package api
+
+import "testing"
+
+func TestParseScoringStrategy_emptyNameUsesDefault(t *testing.T) {
+	strategy, err := ParseScoringStrategy("")
+
+	if err != nil || strategy != DefaultScoringStrategy {
+		t.Errorf("Expected the default strategy. Got: %s, %v.", strategy, err)
+	}
+}
+
+func TestParseScoringStrategy_unknownStrategy(t *testing.T) {
+	_, err := ParseScoringStrategy("closest_pub")
+
+	if err == nil {
+		t.Errorf("An unknown strategy should have been rejected.")
+	}
+}
+
+func TestResolveNeighborhoodCenter_pointOnSurfaceWithinNeighborhood(t *testing.T) {
+	downtown := findNeighborhoodAt(t, 49.2820, -123.1171)
+
+	center, err := resolveNeighborhoodCenter(downtown, PointOnSurfaceScoring)
+	if err != nil {
+		t.Fatalf("Unexpected error resolving center: %v", err)
+	}
+
+	attraction := Attraction{Longitude: center[0], Latitude: center[1]}
+	neighborhood, _ := FindNeighborhoodContainingAttraction(attraction)
+	if neighborhood.ID != downtown.ID {
+		t.Errorf("Point on surface should lie within %s. Got: %s.", downtown.Name, neighborhood.Name)
+	}
+}
+
+func TestFindBestNeighborhoodWithStrategy_edgeDistanceFavoursNeighborhoodNearestAttractions(t *testing.T) {
+	attractions := []Attraction{
+		{Name: "Science World", Latitude: 49.2734, Longitude: -123.1038},
+		{Name: "Canada Place", Latitude: 49.2888, Longitude: -123.1111},
+	}
+	neighborhoods := []Neighborhood{
+		findNeighborhoodAt(t, 49.2820, -123.1171),
+		findNeighborhoodAt(t, 49.2346, -123.1553),
+	}
+
+	bestNeighborhood, _ := FindBestNeighborhoodWithStrategy(neighborhoods, attractions, EdgeDistanceScoring)
+
+	expectedNeighborhoodName := "Downtown"
+	if bestNeighborhood.Name != expectedNeighborhoodName {
+		t.Errorf(
+			"The determined best neighborhood was incorrect. Got: %s, expected: %s.",
+			bestNeighborhood.Name,
+			expectedNeighborhoodName)
+	}
+}
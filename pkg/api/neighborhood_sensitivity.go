@@ -0,0 +1,97 @@
+package api
+
+// SensitivityAnalysis shows how much the best neighborhood depends on each attraction.
+type SensitivityAnalysis struct {
+	BestNeighborhood Neighborhood            `json:"best_neighborhood"`
+	Candidates       []NeighborhoodCandidate `json:"candidates"`
+	Attractions      []AttractionSensitivity `json:"attractions"`
+}
+
+// AttractionSensitivity describes the best neighborhood had an attraction been left out.
+type AttractionSensitivity struct {
+	Attraction Attraction `json:"attraction"`
+	// BestNeighborhood without the attraction. It is empty when no other attraction was matched.
+	BestNeighborhood Neighborhood `json:"best_neighborhood"`
+	// Pivotal attractions change the best neighborhood when left out.
+	Pivotal bool `json:"pivotal"`
+	// MatchedAttractionsChange is how the original best neighborhood's matched attractions change
+	// without the attraction (-1 when the attraction was matched to it).
+	MatchedAttractionsChange int `json:"matched_attractions_change"`
+	// TotalDistanceChangeInMeters is how the original best neighborhood's total distance changes without
+	// the attraction. It is omitted when the neighborhood is no longer a finalist, and so is not measured.
+	TotalDistanceChangeInMeters *float64 `json:"total_distance_change_in_meters,omitempty"`
+}
+
+// Scores candidates as ScoreNeighborhoodCandidates does; replaced in tests.
+type candidateScorer func(neighborhoods []Neighborhood, attractions []Attraction) ([]NeighborhoodCandidate, error)
+
+// AnalyzeSensitivity picks the best neighborhood for the matched attractions (those with a
+// NeighborhoodMatch), then again with each attraction left out in turn, ranking candidates as
+// FindBestNeighborhoodWithStrategy does.
+func AnalyzeSensitivity(attractions []Attraction, strategy ScoringStrategy) (SensitivityAnalysis, error) {
+	return analyzeSensitivity(attractions, func(neighborhoods []Neighborhood, attractions []Attraction) ([]NeighborhoodCandidate, error) {
+		return ScoreNeighborhoodCandidates(neighborhoods, attractions, strategy)
+	})
+}
+
+func analyzeSensitivity(attractions []Attraction, score candidateScorer) (SensitivityAnalysis, error) {
+	var matchedAttractions []Attraction
+	for _, attraction := range attractions {
+		if attraction.NeighborhoodMatch != nil {
+			matchedAttractions = append(matchedAttractions, attraction)
+		}
+	}
+
+	candidates, err := score(matchedNeighborhoods(matchedAttractions), matchedAttractions)
+	if err != nil {
+		return SensitivityAnalysis{}, err
+	}
+
+	best := candidates[0]
+	analysis := SensitivityAnalysis{BestNeighborhood: best.Neighborhood, Candidates: candidates}
+	for i, attraction := range matchedAttractions {
+		remainingAttractions := make([]Attraction, 0, len(matchedAttractions)-1)
+		remainingAttractions = append(remainingAttractions, matchedAttractions[:i]...)
+		remainingAttractions = append(remainingAttractions, matchedAttractions[i+1:]...)
+
+		sensitivity := AttractionSensitivity{Attraction: attraction, Pivotal: true}
+		if len(remainingAttractions) == 0 {
+			sensitivity.MatchedAttractionsChange = -best.MatchedAttractions
+			analysis.Attractions = append(analysis.Attractions, sensitivity)
+			continue
+		}
+
+		remainingCandidates, err := score(matchedNeighborhoods(remainingAttractions), remainingAttractions)
+		if err != nil {
+			return SensitivityAnalysis{}, err
+		}
+
+		sensitivity.BestNeighborhood = remainingCandidates[0].Neighborhood
+		sensitivity.Pivotal = remainingCandidates[0].ID != best.ID
+		sensitivity.MatchedAttractionsChange = -best.MatchedAttractions
+		for _, candidate := range remainingCandidates {
+			if candidate.ID != best.ID {
+				continue
+			}
+
+			sensitivity.MatchedAttractionsChange = candidate.MatchedAttractions - best.MatchedAttractions
+			if candidate.Finalist && best.Finalist {
+				change := candidate.TotalDistanceInMeters - best.TotalDistanceInMeters
+				sensitivity.TotalDistanceChangeInMeters = &change
+			}
+		}
+
+		analysis.Attractions = append(analysis.Attractions, sensitivity)
+	}
+
+	return analysis, nil
+}
+
+func matchedNeighborhoods(attractions []Attraction) []Neighborhood {
+	neighborhoods := make([]Neighborhood, len(attractions))
+	for i, attraction := range attractions {
+		neighborhoods[i] = attraction.NeighborhoodMatch.Neighborhood
+	}
+
+	return neighborhoods
+}
@@ -0,0 +1,105 @@
+package api
+
+import "testing"
+
+// Measures each neighborhood by the number of attractions elsewhere, so the most matched neighborhood is
+// best and no database is needed.
+func scoreByOccurrence(neighborhoods []Neighborhood, attractions []Attraction) ([]NeighborhoodCandidate, error) {
+	var candidates []NeighborhoodCandidate
+	indexes := make(map[int64]int)
+	for _, neighborhood := range neighborhoods {
+		if i, ok := indexes[neighborhood.ID]; ok {
+			candidates[i].MatchedAttractions++
+			continue
+		}
+		indexes[neighborhood.ID] = len(candidates)
+		candidates = append(candidates, NeighborhoodCandidate{Neighborhood: neighborhood, MatchedAttractions: 1})
+	}
+
+	for i := range candidates {
+		candidates[i].Finalist = true
+		candidates[i].TotalDistanceInMeters = float64(len(neighborhoods) - candidates[i].MatchedAttractions)
+	}
+	rankNeighborhoodCandidates(candidates)
+
+	return candidates, nil
+}
+
+func matchedAttraction(name string, neighborhood Neighborhood) Attraction {
+	return Attraction{Name: name, NeighborhoodMatch: &NeighborhoodMatch{Neighborhood: neighborhood}}
+}
+
+func TestAnalyzeSensitivity_pivotalAttraction(t *testing.T) {
+	downtown := Neighborhood{ID: 1, Name: "Downtown"}
+	westEnd := Neighborhood{ID: 2, Name: "West End"}
+	attractions := []Attraction{
+		matchedAttraction("Canada Place", downtown),
+		matchedAttraction("Vancouver Aquarium", downtown),
+		matchedAttraction("English Bay", westEnd),
+		matchedAttraction("Stanley Park", westEnd),
+		matchedAttraction("Vancouver Art Gallery", downtown),
+	}
+
+	analysis, err := analyzeSensitivity(attractions, scoreByOccurrence)
+	if err != nil {
+		t.Fatalf("Unexpected error analyzing sensitivity: %v", err)
+	}
+
+	if analysis.BestNeighborhood.ID != downtown.ID {
+		t.Fatalf("The best neighborhood was incorrect. Got: %s, expected: %s.", analysis.BestNeighborhood.Name, downtown.Name)
+	}
+
+	for _, sensitivity := range analysis.Attractions {
+		// Leaving out any Downtown attraction ties the neighborhoods; the first matched wins.
+		if sensitivity.Pivotal {
+			t.Errorf("%s should not be pivotal.", sensitivity.Attraction.Name)
+		}
+	}
+
+	aquarium := analysis.Attractions[1]
+	if aquarium.MatchedAttractionsChange != -1 {
+		t.Errorf("Leaving out the aquarium should cost Downtown an attraction. Got: %d.", aquarium.MatchedAttractionsChange)
+	}
+
+	englishBay := analysis.Attractions[2]
+	if englishBay.MatchedAttractionsChange != 0 || englishBay.TotalDistanceChangeInMeters == nil || *englishBay.TotalDistanceChangeInMeters != -1 {
+		t.Errorf("Leaving out English Bay should only bring Downtown closer. Got: %+v.", englishBay)
+	}
+}
+
+func TestAnalyzeSensitivity_leavingOutBreaksTie(t *testing.T) {
+	downtown := Neighborhood{ID: 1, Name: "Downtown"}
+	westEnd := Neighborhood{ID: 2, Name: "West End"}
+	attractions := []Attraction{
+		matchedAttraction("Canada Place", downtown),
+		matchedAttraction("English Bay", westEnd),
+		matchedAttraction("Stanley Park", westEnd),
+	}
+
+	analysis, err := analyzeSensitivity(attractions, scoreByOccurrence)
+	if err != nil {
+		t.Fatalf("Unexpected error analyzing sensitivity: %v", err)
+	}
+
+	stanleyPark := analysis.Attractions[2]
+	if !stanleyPark.Pivotal || stanleyPark.BestNeighborhood.ID != downtown.ID {
+		t.Errorf("Leaving out Stanley Park should make Downtown the best neighborhood. Got: %+v.", stanleyPark)
+	}
+}
+
+func TestAnalyzeSensitivity_unmatchedAttractionsIgnored(t *testing.T) {
+	downtown := Neighborhood{ID: 1, Name: "Downtown"}
+	attractions := []Attraction{
+		matchedAttraction("Canada Place", downtown),
+		{Name: "Whistler"},
+	}
+
+	analysis, err := analyzeSensitivity(attractions, scoreByOccurrence)
+	if err != nil {
+		t.Fatalf("Unexpected error analyzing sensitivity: %v", err)
+	}
+
+	if len(analysis.Attractions) != 1 || !analysis.Attractions[0].Pivotal || analysis.Attractions[0].BestNeighborhood.ID != 0 {
+		t.Errorf("Only the lone matched attraction should be analyzed, and is pivotal. Got: %+v.", analysis.Attractions)
+	}
+}
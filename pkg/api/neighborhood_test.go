@@ -6,7 +6,7 @@ import (
 )
 
 func TestFindNeighborhoodContainingAttraction_noNeighborhoodFound(t *testing.T) {
-	attraction := Attraction{"Foobar", "Foobar City", "CA", -32.0, 3.00}
+	attraction := Attraction{Name: "Foobar", City: "Foobar City", StateOrProvinceName: "CA", Latitude: -32.0, Longitude: 3.00}
 
 	neighborhood, _ := FindNeighborhoodContainingAttraction(attraction)
 
@@ -20,7 +20,7 @@ func TestFindNeighborhoodContainingAttraction_noNeighborhoodFound(t *testing.T)
 }
 
 func TestFindNeighborhoodContainingAttraction_multipleMatchesExpectedClosestToAttractionReturned(t *testing.T) {
-	attraction := Attraction{"Science World", "Vancouver", "BC", 49.2820, -123.1171}
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 49.2820, Longitude: -123.1171}
 
 	neighborhood, _ := FindNeighborhoodContainingAttraction(attraction)
 
@@ -47,8 +47,28 @@ func TestFindNeighborhoodContainingAttraction_emptyAttractionGiven(t *testing.T)
 	}
 }
 
-func TestResolveNeighborhoodMultiPolygonsCentroidPoint_neighborhoodNameIsInvalid(t *testing.T) {
-	_, err := resolveNeighborhoodMultiPolygonsCentroidPoint("fake", "Vancouver", "BC")
+// Finds the neighborhood containing the coordinates, failing the test when there is none.
+func findNeighborhoodAt(t *testing.T, latitude float64, longitude float64) Neighborhood {
+	neighborhood, err := FindNeighborhoodContainingAttraction(Attraction{Latitude: latitude, Longitude: longitude})
+	if err != nil || neighborhood.ID == 0 {
+		t.Fatalf("Expected a neighborhood at (%.4f, %.4f). Got: %+v, %v.", latitude, longitude, neighborhood, err)
+	}
+
+	return neighborhood
+}
+
+func TestFindNeighborhoodContainingAttraction_neighborhoodIDResolved(t *testing.T) {
+	attraction := Attraction{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC", Latitude: 49.2820, Longitude: -123.1171}
+
+	neighborhood, _ := FindNeighborhoodContainingAttraction(attraction)
+
+	if neighborhood.ID == 0 {
+		t.Errorf("The neighborhood's ID should have been resolved. Got: %+v.", neighborhood)
+	}
+}
+
+func TestResolveNeighborhoodMultiPolygonsCentroidPoint_neighborhoodIDIsInvalid(t *testing.T) {
+	_, err := resolveNeighborhoodMultiPolygonsCentroidPoint(-1)
 
 	if err == nil {
 		t.Errorf("An exception should have been thrown due to no rows.")
@@ -56,7 +76,8 @@ func TestResolveNeighborhoodMultiPolygonsCentroidPoint_neighborhoodNameIsInvalid
 }
 
 func TestResovleNeighborhoodMultiPolygonsCentroidPoint_neighborhoodCentroidResolved(t *testing.T) {
-	neighborhoodCoordinates, _ := resolveNeighborhoodMultiPolygonsCentroidPoint("Downtown", "Vancouver", "BC")
+	downtown := findNeighborhoodAt(t, 49.2820, -123.1171)
+	neighborhoodCoordinates, _ := resolveNeighborhoodMultiPolygonsCentroidPoint(downtown.ID)
 	epsilon := 0.0000001
 	expectedCoordinates := []float64{-123.116626, 49.280705}
 	if math.Abs(neighborhoodCoordinates[0])-math.Abs(expectedCoordinates[0]) > epsilon {
@@ -83,14 +104,14 @@ func TestGetDistanceBetweenTwoCoordinates_exactSameCoordinatesGiven(t *testing.T
 func TestFindOptimalNeighborhood_noTies(t *testing.T) {
 	g := Graph{}
 	nodes := []Neighborhood{
-		Neighborhood{"Downtown", "Foobar City", "CA", "USA", -3.1, 0.0},
-		Neighborhood{"West Side", "Foobar City", "CA", "USA", -3.2, 0.0},
-		Neighborhood{"Central", "Foobar City", "CA", "USA", -3.3, 0.0}}
+		Neighborhood{ID: 1, Name: "Downtown", City: "Foobar City", StateOrProvinceName: "CA", Country: "USA", Latitude: -3.1},
+		Neighborhood{ID: 2, Name: "West Side", City: "Foobar City", StateOrProvinceName: "CA", Country: "USA", Latitude: -3.2},
+		Neighborhood{ID: 3, Name: "Central", City: "Foobar City", StateOrProvinceName: "CA", Country: "USA", Latitude: -3.3}}
 	g.nodes = nodes
-	g.edges = map[string][]Edge{
-		nodes[0].Name: {Edge{nodes[0], nodes[1], 3.0}, Edge{nodes[0], nodes[2], 1.0}},
-		nodes[1].Name: {Edge{nodes[1], nodes[0], 3.0}, Edge{nodes[1], nodes[2], 5.0}},
-		nodes[2].Name: {Edge{nodes[2], nodes[1], 5.0}, Edge{nodes[2], nodes[0], 1.0}}}
+	g.edges = map[int64][]Edge{
+		nodes[0].ID: {Edge{nodes[0], nodes[1], 3.0}, Edge{nodes[0], nodes[2], 1.0}},
+		nodes[1].ID: {Edge{nodes[1], nodes[0], 3.0}, Edge{nodes[1], nodes[2], 5.0}},
+		nodes[2].ID: {Edge{nodes[2], nodes[1], 5.0}, Edge{nodes[2], nodes[0], 1.0}}}
 
 	bestNeighborhood, _ := findMinDistanceBetweenNodes(g)
 
@@ -102,3 +123,53 @@ func TestFindOptimalNeighborhood_noTies(t *testing.T) {
 			expectedBestNeighborhood)
 	}
 }
+
+func TestFindCityExtent_extentContainsCityAttraction(t *testing.T) {
+	extent, err := FindCityExtent("Vancouver", "BC")
+	if err != nil {
+		t.Fatalf("Unexpected error resolving city extent: %v", err)
+	}
+
+	if !extent.Contains(49.2734, -123.1038) {
+		t.Errorf("City extent should contain Science World. Got: %+v.", extent)
+	}
+}
+
+func TestFindCityExtent_unknownCity(t *testing.T) {
+	_, err := FindCityExtent("Atlantis", "ZZ")
+
+	if _, ok := err.(*NoNeighborhoodFoundError); !ok {
+		t.Errorf("Expected a NoNeighborhoodFoundError for an unknown city. Got: %v.", err)
+	}
+}
+
+func TestFindBestNeighborhood_emptyNeighborhoodsIgnored(t *testing.T) {
+	_, err := FindBestNeighborhood([]Neighborhood{{}, {}})
+
+	if _, ok := err.(*NoNeighborhoodFoundError); !ok {
+		t.Errorf("Expected a NoNeighborhoodFoundError when only empty neighborhoods are given. Got: %v.", err)
+	}
+}
+
+func TestFindHighestOccurrenceNeighborhoods_sameNameInDifferentCitiesCountedSeparately(t *testing.T) {
+	vancouverDowntown := Neighborhood{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"}
+	seattleDowntown := Neighborhood{ID: 2, Name: "Downtown", City: "Seattle", StateOrProvinceName: "WA"}
+	westEnd := Neighborhood{ID: 3, Name: "West End", City: "Vancouver", StateOrProvinceName: "BC"}
+
+	neighborhoods, _ := findHighestOccurrenceNeighborhoods(
+		[]Neighborhood{vancouverDowntown, seattleDowntown, westEnd, westEnd})
+
+	for _, neighborhood := range neighborhoods {
+		if neighborhood.ID != westEnd.ID {
+			t.Errorf("Only %s should have the highest occurrence. Got: %+v.", westEnd.Name, neighborhood)
+		}
+	}
+}
+
+func TestFindNeighborhoodContainingAttraction_flatBoundariesAreNeighborhoods(t *testing.T) {
+	neighborhood := findNeighborhoodAt(t, 49.2820, -123.1171)
+
+	if neighborhood.Level != NeighborhoodLevel || neighborhood.Ancestors != nil {
+		t.Errorf("Boundaries without a level should be neighborhoods without ancestors. Got: %+v.", neighborhood)
+	}
+}
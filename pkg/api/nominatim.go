@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codingsince1985/geo-golang"
+	"github.com/codingsince1985/geo-golang/openstreetmap"
+)
+
+// DefaultNominatimURL is the public OpenStreetMap Nominatim instance.
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org/"
+
+// DefaultNominatimTimeout bounds each search request.
+const DefaultNominatimTimeout = 10 * time.Second
+
+// Nominatim's usage policy requires an identifying user agent.
+const nominatimUserAgent = "closest-airbnb-to-attractions-finder"
+
+// NominatimGeocoder geocodes against OpenStreetMap's Nominatim. Attractions are searched by address
+// component (see https://nominatim.org/release-docs/latest/api/Search/#structured-query) so that
+// "Paris, TX" and "Paris, France" cannot be confused. Free-form and reverse geocoding are delegated to
+// geo-golang's openstreetmap geocoder.
+type NominatimGeocoder struct {
+	geo.Geocoder
+	baseURL string
+	client  *http.Client
+}
+
+// Nominatim considers this many places per search; all but the first are reported as alternatives.
+const nominatimCandidateLimit = 5
+
+type nominatimPlace struct {
+	Latitude    string  `json:"lat"`
+	Longitude   string  `json:"lon"`
+	DisplayName string  `json:"display_name"`
+	Importance  float64 `json:"importance"`
+	// South latitude, north latitude, west longitude, east longitude.
+	BoundingBox []string `json:"boundingbox"`
+}
+
+// NewNominatimGeocoder creates a geocoder for the Nominatim instance at baseURL (i.e, DefaultNominatimURL).
+func NewNominatimGeocoder(baseURL string) *NominatimGeocoder {
+	return NewNominatimGeocoderWithTimeout(baseURL, DefaultNominatimTimeout)
+}
+
+// NewNominatimGeocoderWithTimeout creates a geocoder for the Nominatim instance at baseURL whose searches
+// give up after timeout.
+func NewNominatimGeocoderWithTimeout(baseURL string, timeout time.Duration) *NominatimGeocoder {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	return &NominatimGeocoder{
+		Geocoder: openstreetmap.GeocoderWithURL(baseURL),
+		baseURL:  baseURL,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// GeocodeStructured searches for the attraction by name, city, state and country, returning the best
+// match. A nil location is returned when nothing matches.
+func (g *NominatimGeocoder) GeocodeStructured(query StructuredGeocodingQuery) (*geo.Location, error) {
+	result, err := g.GeocodeWithResult(query)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return result.Location(), nil
+}
+
+// GeocodeWithResult searches for the attraction by name, city, state and country. Attraction names which
+// Nominatim does not know as an amenity (i.e, a street address) are retried as a free-form search still
+// restricted to the attraction's country. A nil result is returned when nothing matches.
+func (g *NominatimGeocoder) GeocodeWithResult(query StructuredGeocodingQuery) (*GeocodingResult, error) {
+	params := url.Values{}
+	params.Set("amenity", query.Name)
+	params.Set("city", query.City)
+	params.Set("state", query.StateOrProvinceName)
+	if query.Country != "" {
+		params.Set("country", query.Country)
+	}
+
+	places, err := g.search(params, query.CountryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(places) == 0 {
+		params = url.Values{}
+		params.Set("q", strings.Join([]string{query.Name, query.City, query.StateOrProvinceName}, ", "))
+		places, err = g.search(params, query.CountryCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []GeocodingCandidate
+	for _, place := range places {
+		candidate, err := place.candidate()
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return newGeocodingResult(ProviderNominatim, candidates), nil
+}
+
+func (g *NominatimGeocoder) search(params url.Values, countryCode string) ([]nominatimPlace, error) {
+	params.Set("format", "jsonv2")
+	params.Set("limit", strconv.Itoa(nominatimCandidateLimit))
+	if countryCode != "" {
+		params.Set("countrycodes", strings.ToLower(countryCode))
+	}
+
+	request, err := http.NewRequest(http.MethodGet, g.baseURL+"search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", nominatimUserAgent)
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nominatim search failed with status %s", response.Status)
+	}
+
+	var places []nominatimPlace
+	if err := json.NewDecoder(response.Body).Decode(&places); err != nil {
+		return nil, err
+	}
+
+	return places, nil
+}
+
+// Nominatim returns coordinates as strings.
+func (place nominatimPlace) candidate() (GeocodingCandidate, error) {
+	latitude, err := strconv.ParseFloat(place.Latitude, 64)
+	if err != nil {
+		return GeocodingCandidate{}, err
+	}
+
+	longitude, err := strconv.ParseFloat(place.Longitude, 64)
+	if err != nil {
+		return GeocodingCandidate{}, err
+	}
+
+	candidate := GeocodingCandidate{
+		DisplayName: place.DisplayName,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Confidence:  place.Importance,
+	}
+
+	if len(place.BoundingBox) == 4 {
+		var bounds [4]float64
+		for i, bound := range place.BoundingBox {
+			if bounds[i], err = strconv.ParseFloat(bound, 64); err != nil {
+				return GeocodingCandidate{}, err
+			}
+		}
+		candidate.BoundingBox = &BoundingBox{bounds[0], bounds[2], bounds[1], bounds[3]}
+	}
+
+	return candidate, nil
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestGeocodeStructured_componentsSentSeparately(t *testing.T) {
+	var searches []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searches = append(searches, r.URL.Query())
+		w.Write([]byte(`[{"lat": "48.8566", "lon": "2.3522"}]`))
+	}))
+	defer server.Close()
+
+	attraction := Attraction{Name: "Eiffel Tower", City: "Paris", StateOrProvinceName: "Ile-de-France", Country: "France"}
+	location, err := attraction.GeocodeAttraction(NewNominatimGeocoder(server.URL))
+	if err != nil {
+		t.Fatalf("Unexpected error geocoding: %v", err)
+	}
+
+	if location.Lat != 48.8566 || location.Lng != 2.3522 {
+		t.Errorf("Location was incorrect. Got: (%.4f, %.4f), expected: (48.8566, 2.3522).", location.Lat, location.Lng)
+	}
+
+	if len(searches) != 1 {
+		t.Fatalf("Number of searches was incorrect. Got: %d, expected: %d.", len(searches), 1)
+	}
+
+	expectedParams := map[string]string{
+		"amenity":      "Eiffel Tower",
+		"city":         "Paris",
+		"country":      "France",
+		"countrycodes": "fr",
+	}
+	for param, expectedValue := range expectedParams {
+		if searches[0].Get(param) != expectedValue {
+			t.Errorf("Search parameter %s was incorrect. Got: %s, expected: %s.", param, searches[0].Get(param), expectedValue)
+		}
+	}
+
+	if searches[0].Get("q") != "" {
+		t.Errorf("A structured search should not send a free-form query.")
+	}
+}
+
+func TestGeocodeStructured_fallsBackToFreeFormWithinCountry(t *testing.T) {
+	var searches []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		searches = append(searches, r.URL.Query())
+		if r.URL.Query().Get("q") == "" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Write([]byte(`[{"lat": "33.6609", "lon": "-95.5555"}]`))
+	}))
+	defer server.Close()
+
+	geocoder := NewNominatimGeocoder(server.URL)
+	location, _ := geocoder.GeocodeStructured(StructuredGeocodingQuery{
+		Name: "100 Main St", City: "Paris", StateOrProvinceName: "Texas", CountryCode: "US", Country: "United States"})
+
+	if location == nil || location.Lat != 33.6609 {
+		t.Fatalf("Free-form fallback result was not returned. Got: %v.", location)
+	}
+
+	if len(searches) != 2 || searches[1].Get("countrycodes") != "us" {
+		t.Errorf("Free-form fallback should be restricted to the attraction's country. Got: %v.", searches)
+	}
+}
+
+func TestGeocodeStructured_noMatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	location, err := NewNominatimGeocoder(server.URL).GeocodeStructured(StructuredGeocodingQuery{Name: "Nowhere"})
+
+	if location != nil || err != nil {
+		t.Errorf("Expected no location and no error. Got: %v, %v.", location, err)
+	}
+}
+
+func TestGeocodeStructured_slowSearchTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	_, err := NewNominatimGeocoderWithTimeout(server.URL, 50*time.Millisecond).GeocodeStructured(StructuredGeocodingQuery{Name: "Nowhere"})
+
+	if err == nil {
+		t.Errorf("Expected the search to time out.")
+	}
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TravelTimeEstimator estimates how long it takes to travel between places.
+type TravelTimeEstimator interface {
+	// TravelTimesInSeconds returns the travel time from each origin (row) to each destination (column).
+	// Places are (longitude, latitude) pairs; unreachable destinations are nil.
+	TravelTimesInSeconds(origins [][]float64, destinations [][]float64) ([][]*float64, error)
+}
+
+var (
+	travelTimeEstimatorMutex sync.RWMutex
+	travelTimeEstimator      TravelTimeEstimator
+)
+
+// SetTravelTimeEstimator sets the estimator used to report travel times alongside distances. Travel
+// times are not reported by default.
+func SetTravelTimeEstimator(estimator TravelTimeEstimator) {
+	travelTimeEstimatorMutex.Lock()
+	defer travelTimeEstimatorMutex.Unlock()
+
+	travelTimeEstimator = estimator
+}
+
+func getTravelTimeEstimator() TravelTimeEstimator {
+	travelTimeEstimatorMutex.RLock()
+	defer travelTimeEstimatorMutex.RUnlock()
+
+	return travelTimeEstimator
+}
+
+// DefaultOSRMProfile is the profile every OSRM server provides; others depend on how it was set up.
+const DefaultOSRMProfile = "driving"
+
+// OSRMRouter estimates travel times with an OSRM server's table service (see
+// http://project-osrm.org/docs/v5.24.0/api/#table-service).
+type OSRMRouter struct {
+	baseURL string
+	profile string
+	client  *http.Client
+}
+
+type osrmTable struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Durations [][]*float64 `json:"durations"`
+}
+
+// NewOSRMRouter creates a router for the OSRM server at baseURL, travelling by the given profile (i.e,
+// DefaultOSRMProfile).
+func NewOSRMRouter(baseURL string, profile string) *OSRMRouter {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	return &OSRMRouter{
+		baseURL: baseURL,
+		profile: profile,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// TravelTimesInSeconds asks OSRM for the durations between every origin and destination in one request.
+func (router *OSRMRouter) TravelTimesInSeconds(origins [][]float64, destinations [][]float64) ([][]*float64, error) {
+	if len(origins) == 0 || len(destinations) == 0 {
+		return nil, nil
+	}
+
+	var coordinates, sources, targets []string
+	for i, place := range append(append([][]float64{}, origins...), destinations...) {
+		coordinates = append(coordinates, strconv.FormatFloat(place[0], 'f', -1, 64)+","+strconv.FormatFloat(place[1], 'f', -1, 64))
+		if i < len(origins) {
+			sources = append(sources, strconv.Itoa(i))
+		} else {
+			targets = append(targets, strconv.Itoa(i))
+		}
+	}
+
+	tableURL := fmt.Sprintf(
+		"%stable/v1/%s/%s?sources=%s&destinations=%s&annotations=duration",
+		router.baseURL,
+		router.profile,
+		strings.Join(coordinates, ";"),
+		strings.Join(sources, ";"),
+		strings.Join(targets, ";"))
+
+	response, err := router.client.Get(tableURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var table osrmTable
+	if err := json.NewDecoder(response.Body).Decode(&table); err != nil {
+		return nil, fmt.Errorf("osrm table failed with status %s: %v", response.Status, err)
+	}
+
+	if table.Code != "Ok" {
+		return nil, fmt.Errorf("osrm table failed with code %s: %s", table.Code, table.Message)
+	}
+
+	if len(table.Durations) != len(origins) {
+		return nil, fmt.Errorf("osrm table returned %d rows for %d origins", len(table.Durations), len(origins))
+	}
+
+	return table.Durations, nil
+}
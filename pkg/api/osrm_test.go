@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTravelTimesInSeconds_originsAndDestinationsInOneRequest(t *testing.T) {
+	var paths []string
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`{"code": "Ok", "durations": [[120.5, null]]}`))
+	}))
+	defer server.Close()
+
+	durations, err := NewOSRMRouter(server.URL, DefaultOSRMProfile).TravelTimesInSeconds(
+		[][]float64{{-123.1171, 49.2820}},
+		[][]float64{{-123.1038, 49.2734}, {-123.1111, 49.2888}})
+	if err != nil {
+		t.Fatalf("Unexpected error estimating travel times: %v", err)
+	}
+
+	if len(paths) != 1 {
+		t.Fatalf("Number of requests was incorrect. Got: %d, expected: %d.", len(paths), 1)
+	}
+
+	expectedPath := "/table/v1/driving/-123.1171,49.282;-123.1038,49.2734;-123.1111,49.2888"
+	if paths[0] != expectedPath {
+		t.Errorf("Request path was incorrect. Got: %s, expected: %s.", paths[0], expectedPath)
+	}
+
+	expectedQuery := "sources=0&destinations=1;2&annotations=duration"
+	if queries[0] != expectedQuery {
+		t.Errorf("Request query was incorrect. Got: %s, expected: %s.", queries[0], expectedQuery)
+	}
+
+	if len(durations) != 1 || len(durations[0]) != 2 || *durations[0][0] != 120.5 || durations[0][1] != nil {
+		t.Errorf("Durations were incorrect. Got: %v.", durations)
+	}
+}
+
+func TestTravelTimesInSeconds_errorCodeReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": "InvalidQuery", "message": "Query string malformed"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewOSRMRouter(server.URL, DefaultOSRMProfile).TravelTimesInSeconds(
+		[][]float64{{-123.1171, 49.2820}},
+		[][]float64{{-123.1038, 49.2734}})
+
+	if err == nil {
+		t.Errorf("Expected an error for a failed table request.")
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegionCity is one of the cities making up a Region.
+type RegionCity struct {
+	City                string `json:"city_name"`
+	StateOrProvinceName string `json:"state_or_province_name"`
+}
+
+// Region is the set of cities a trip spans (i.e, Vancouver, Burnaby and North Vancouver). Attractions are
+// matched to the neighborhoods of any city within the region, so one lying just across a city line is
+// matched to the neighborhood next to it rather than treated as outside its own city.
+type Region struct {
+	Cities []RegionCity `json:"cities"`
+}
+
+// NewRegion builds the region spanned by the given attractions' cities. Attractions without a city do not
+// contribute to it.
+func NewRegion(attractions []Attraction) Region {
+	var region Region
+	for _, attraction := range attractions {
+		if attraction.City == "" || region.Contains(attraction.City, attraction.StateOrProvinceName) {
+			continue
+		}
+
+		region.Cities = append(region.Cities, RegionCity{attraction.City, attraction.StateOrProvinceName})
+	}
+
+	return region
+}
+
+// Contains reports whether the city is part of the region. Cities are matched case-insensitively, as in
+// queries.
+func (region Region) Contains(city string, stateOrProvinceName string) bool {
+	for _, regionCity := range region.Cities {
+		if strings.EqualFold(regionCity.City, city) && strings.EqualFold(regionCity.StateOrProvinceName, stateOrProvinceName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the region's cities and states as parallel slices, for use as query arrays.
+func (region Region) citiesAndStates() ([]string, []string) {
+	cities := make([]string, len(region.Cities))
+	states := make([]string, len(region.Cities))
+	for i, regionCity := range region.Cities {
+		cities[i] = regionCity.City
+		states[i] = regionCity.StateOrProvinceName
+	}
+
+	return cities, states
+}
+
+// Builds a query condition restricting the table's rows to the region's cities, given the positions of the
+// citiesAndStates query arrays. An empty region matches every row.
+func regionCondition(table string, citiesParameter int, statesParameter int) string {
+	return fmt.Sprintf(`(cardinality($%[2]d::text[]) = 0 OR EXISTS (
+                SELECT 1
+                FROM unnest($%[2]d::text[], $%[3]d::text[]) as region(city, state)
+                WHERE %[1]s.city ilike region.city
+                    AND (region.state = '' OR %[1]s.state ilike region.state)))`, table, citiesParameter, statesParameter)
+}
+
+// Union returns the smallest box containing both boxes.
+func (box BoundingBox) Union(other BoundingBox) BoundingBox {
+	if box == (BoundingBox{}) {
+		return other
+	}
+
+	if other == (BoundingBox{}) {
+		return box
+	}
+
+	return BoundingBox{
+		MinLatitude:  minFloat(box.MinLatitude, other.MinLatitude),
+		MinLongitude: minFloat(box.MinLongitude, other.MinLongitude),
+		MaxLatitude:  maxFloat(box.MaxLatitude, other.MaxLatitude),
+		MaxLongitude: maxFloat(box.MaxLongitude, other.MaxLongitude),
+	}
+}
+
+func minFloat(a float64, b float64) float64 {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxFloat(a float64, b float64) float64 {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+// CityStatistics summarises a trip's attractions within one of its cities.
+type CityStatistics struct {
+	City                   string `json:"city_name"`
+	StateOrProvinceName    string `json:"state_or_province_name"`
+	SuccessfulAttractions  int    `json:"successful_attractions"`
+	FailedAttractions      int    `json:"failed_attractions"`
+	InsideCityAttractions  int    `json:"inside_city_attractions"`
+	NearCityAttractions    int    `json:"near_city_attractions"`
+	OutsideCityAttractions int    `json:"outside_city_attractions"`
+	// MatchedNeighborhoods is the number of distinct neighborhoods the city's attractions were matched to.
+	MatchedNeighborhoods int `json:"matched_neighborhoods"`
+	// CrossCityMatches counts attractions matched to a neighborhood of another city in the region.
+	CrossCityMatches int `json:"cross_city_matches"`
+	// ContainsClosestNeighborhood is set for the city of the trip's closest neighborhood.
+	ContainsClosestNeighborhood bool `json:"contains_closest_neighborhood"`
+}
+
+// RegionStatistics accumulates CityStatistics as a trip's attractions are planned. The zero value is ready
+// to use.
+type RegionStatistics struct {
+	cities               []CityStatistics
+	matchedNeighborhoods []map[int64]bool
+}
+
+// AddFailed counts an attraction which could not be located.
+func (statistics *RegionStatistics) AddFailed(attraction Attraction) {
+	statistics.city(attraction).FailedAttractions++
+}
+
+// Add counts a located attraction, along with the neighborhood it was matched to (if any).
+func (statistics *RegionStatistics) Add(attraction Attraction, classification CityBoundaryClassification) {
+	city := statistics.city(attraction)
+	city.SuccessfulAttractions++
+	switch classification {
+	case InsideCity:
+		city.InsideCityAttractions++
+	case NearCity:
+		city.NearCityAttractions++
+	default:
+		city.OutsideCityAttractions++
+	}
+
+	if attraction.NeighborhoodMatch == nil {
+		return
+	}
+
+	neighborhood := attraction.NeighborhoodMatch.Neighborhood
+	i := statistics.cityIndex(attraction)
+	matchedNeighborhoods := statistics.matchedNeighborhoods[i]
+	if !matchedNeighborhoods[neighborhood.ID] {
+		matchedNeighborhoods[neighborhood.ID] = true
+		city.MatchedNeighborhoods++
+	}
+
+	if !strings.EqualFold(neighborhood.City, attraction.City) ||
+		!strings.EqualFold(neighborhood.StateOrProvinceName, attraction.StateOrProvinceName) {
+		city.CrossCityMatches++
+	}
+}
+
+// Cities returns the statistics of every city, in the order they were first seen, marking the city of the
+// closest neighborhood.
+func (statistics *RegionStatistics) Cities(closestNeighborhood Neighborhood) []CityStatistics {
+	cities := make([]CityStatistics, len(statistics.cities))
+	copy(cities, statistics.cities)
+	for i := range cities {
+		cities[i].ContainsClosestNeighborhood = closestNeighborhood.ID != 0 &&
+			strings.EqualFold(cities[i].City, closestNeighborhood.City) &&
+			strings.EqualFold(cities[i].StateOrProvinceName, closestNeighborhood.StateOrProvinceName)
+	}
+
+	return cities
+}
+
+// cityIndex may grow statistics.cities, so it is called before the slice is indexed.
+func (statistics *RegionStatistics) city(attraction Attraction) *CityStatistics {
+	i := statistics.cityIndex(attraction)
+	return &statistics.cities[i]
+}
+
+func (statistics *RegionStatistics) cityIndex(attraction Attraction) int {
+	for i, city := range statistics.cities {
+		if strings.EqualFold(city.City, attraction.City) && strings.EqualFold(city.StateOrProvinceName, attraction.StateOrProvinceName) {
+			return i
+		}
+	}
+
+	statistics.cities = append(statistics.cities, CityStatistics{
+		City:                attraction.City,
+		StateOrProvinceName: attraction.StateOrProvinceName,
+	})
+	statistics.matchedNeighborhoods = append(statistics.matchedNeighborhoods, make(map[int64]bool))
+
+	return len(statistics.cities) - 1
+}
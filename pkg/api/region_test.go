@@ -0,0 +1,96 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewRegion_citiesDeduplicated(t *testing.T) {
+	region := NewRegion([]Attraction{
+		{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC"},
+		{Name: "Metrotown", City: "Burnaby", StateOrProvinceName: "BC"},
+		{Name: "Canada Place", City: "vancouver", StateOrProvinceName: "bc"},
+		{Latitude: 49.2734, Longitude: -123.1038},
+	})
+
+	expectedCitiesCount := 2
+	if len(region.Cities) != expectedCitiesCount {
+		t.Errorf("Number of cities was incorrect. Got: %d, expected: %d.", len(region.Cities), expectedCitiesCount)
+	}
+
+	if !region.Contains("VANCOUVER", "BC") || region.Contains("Vancouver", "WA") {
+		t.Errorf("Cities should be matched case-insensitively by city and state. Got: %+v.", region.Cities)
+	}
+}
+
+func TestBoundingBoxUnion_containsBothBoxes(t *testing.T) {
+	burnabyExtent := BoundingBox{49.180, -123.024, 49.300, -122.892}
+
+	union := vancouverExtent.Union(burnabyExtent)
+
+	if !union.Contains(49.2820, -123.1171) || !union.Contains(49.2255, -122.9990) {
+		t.Errorf("Union should contain both boxes. Got: %+v.", union)
+	}
+}
+
+func TestBoundingBoxUnion_emptyBoxIgnored(t *testing.T) {
+	union := BoundingBox{}.Union(vancouverExtent)
+
+	if union != vancouverExtent {
+		t.Errorf("Union with an empty box should be the other box. Got: %+v.", union)
+	}
+}
+
+func TestRegionStatistics_countsByCity(t *testing.T) {
+	downtown := Neighborhood{ID: 1, Name: "Downtown", City: "Vancouver", StateOrProvinceName: "BC"}
+	hastingsSunrise := Neighborhood{ID: 2, Name: "Hastings-Sunrise", City: "Vancouver", StateOrProvinceName: "BC"}
+	var statistics RegionStatistics
+
+	statistics.Add(Attraction{City: "Vancouver", StateOrProvinceName: "BC", NeighborhoodMatch: &NeighborhoodMatch{Neighborhood: downtown}}, InsideCity)
+	statistics.Add(Attraction{City: "Vancouver", StateOrProvinceName: "BC", NeighborhoodMatch: &NeighborhoodMatch{Neighborhood: downtown}}, InsideCity)
+	statistics.Add(Attraction{City: "Burnaby", StateOrProvinceName: "BC", NeighborhoodMatch: &NeighborhoodMatch{Neighborhood: hastingsSunrise}}, NearCity)
+	statistics.Add(Attraction{City: "Burnaby", StateOrProvinceName: "BC"}, OutsideCity)
+	statistics.AddFailed(Attraction{City: "Burnaby", StateOrProvinceName: "BC"})
+
+	cities := statistics.Cities(downtown)
+
+	expectedCities := []CityStatistics{
+		{
+			City:                        "Vancouver",
+			StateOrProvinceName:         "BC",
+			SuccessfulAttractions:       2,
+			InsideCityAttractions:       2,
+			MatchedNeighborhoods:        1,
+			ContainsClosestNeighborhood: true,
+		},
+		{
+			City:                   "Burnaby",
+			StateOrProvinceName:    "BC",
+			SuccessfulAttractions:  2,
+			FailedAttractions:      1,
+			NearCityAttractions:    1,
+			OutsideCityAttractions: 1,
+			MatchedNeighborhoods:   1,
+			CrossCityMatches:       1,
+		},
+	}
+	if len(cities) != len(expectedCities) {
+		t.Fatalf("Number of cities was incorrect. Got: %d, expected: %d.", len(cities), len(expectedCities))
+	}
+
+	for i := range expectedCities {
+		if cities[i] != expectedCities[i] {
+			t.Errorf("City statistics were incorrect. Got: %+v, expected: %+v.", cities[i], expectedCities[i])
+		}
+	}
+}
+
+func TestRegionCondition_parametersNumbered(t *testing.T) {
+	condition := regionCondition("neighborhoods", 3, 4)
+
+	for _, expected := range []string{"cardinality($3::text[])", "unnest($3::text[], $4::text[])", "neighborhoods.city ilike"} {
+		if !strings.Contains(condition, expected) {
+			t.Errorf("Condition should contain %q. Got: %s.", expected, condition)
+		}
+	}
+}
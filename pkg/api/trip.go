@@ -0,0 +1,287 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"../connections"
+)
+
+// Trip is a saved list of attractions, with the preferences it is planned with and its latest plan.
+type Trip struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name,omitempty"`
+	Attractions []TripAttraction `json:"attractions"`
+	// Preferences and LastResult are stored as given; the caller decides their shape.
+	Preferences json.RawMessage `json:"preferences,omitempty"`
+	LastResult  json.RawMessage `json:"last_result,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	EvaluatedAt *time.Time      `json:"evaluated_at,omitempty"`
+}
+
+// TripAttraction is an attraction saved in a trip.
+type TripAttraction struct {
+	ID int64 `json:"id"`
+	Attraction
+	// Located attractions keep their coordinates and geocoding, and are not geocoded again.
+	Located bool `json:"located"`
+}
+
+// NoTripFoundError indicates a trip (or an attraction of it) was not resolved
+type NoTripFoundError struct {
+	message string
+}
+
+func (e *NoTripFoundError) Error() string {
+	return e.message
+}
+
+// CreateTrip saves a new trip with the given attractions, none of them located yet.
+func CreateTrip(name string, attractions []Attraction, preferences json.RawMessage) (Trip, error) {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return Trip{}, err
+	}
+
+	var tripID int64
+	err = tx.QueryRow(
+		"INSERT INTO neighborhood_geocoding.trips (name, preferences) VALUES ($1, $2) RETURNING id",
+		name,
+		string(preferences)).Scan(&tripID)
+	if err != nil {
+		tx.Rollback()
+		return Trip{}, err
+	}
+
+	if err := insertTripAttractions(tx, tripID, attractions); err != nil {
+		tx.Rollback()
+		return Trip{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Trip{}, err
+	}
+
+	return FindTrip(tripID)
+}
+
+// FindTrip returns the trip with its attractions in the order they were added. A NoTripFoundError is
+// returned when there is no such trip.
+func FindTrip(tripID int64) (Trip, error) {
+	tripQuery := `
+    SELECT id, coalesce(name, ''), preferences::text, coalesce(last_result::text, ''), created_at, updated_at, evaluated_at
+    FROM neighborhood_geocoding.trips
+    WHERE id = $1
+    `
+
+	var trip Trip
+	var preferences, lastResult string
+	var evaluatedAt sql.NullTime
+	err := connections.Init().QueryRow(tripQuery, tripID).Scan(
+		&trip.ID,
+		&trip.Name,
+		&preferences,
+		&lastResult,
+		&trip.CreatedAt,
+		&trip.UpdatedAt,
+		&evaluatedAt)
+	if err == sql.ErrNoRows {
+		return Trip{}, noTripWithID(tripID)
+	}
+
+	if err != nil {
+		return Trip{}, err
+	}
+
+	trip.Preferences = json.RawMessage(preferences)
+	if lastResult != "" {
+		trip.LastResult = json.RawMessage(lastResult)
+	}
+	if evaluatedAt.Valid {
+		trip.EvaluatedAt = &evaluatedAt.Time
+	}
+
+	trip.Attractions, err = findTripAttractions(tripID)
+	if err != nil {
+		return Trip{}, err
+	}
+
+	return trip, nil
+}
+
+// DeleteTrip removes the trip and its attractions. A NoTripFoundError is returned when there is no such
+// trip.
+func DeleteTrip(tripID int64) error {
+	result, err := connections.Init().Exec("DELETE FROM neighborhood_geocoding.trips WHERE id = $1", tripID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffectedRow(result, noTripWithID(tripID))
+}
+
+// AddTripAttractions appends attractions to the trip, returning the trip as saved. They are located the
+// next time the trip is evaluated.
+func AddTripAttractions(tripID int64, attractions []Attraction) (Trip, error) {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return Trip{}, err
+	}
+
+	result, err := tx.Exec("UPDATE neighborhood_geocoding.trips SET updated_at = now() WHERE id = $1", tripID)
+	if err == nil {
+		err = requireAffectedRow(result, noTripWithID(tripID))
+	}
+	if err == nil {
+		err = insertTripAttractions(tx, tripID, attractions)
+	}
+	if err != nil {
+		tx.Rollback()
+		return Trip{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Trip{}, err
+	}
+
+	return FindTrip(tripID)
+}
+
+// RemoveTripAttraction removes an attraction from the trip. A NoTripFoundError is returned when the trip
+// has no such attraction.
+func RemoveTripAttraction(tripID int64, attractionID int64) error {
+	removeAttractionQuery := `
+    WITH removed AS (
+        DELETE FROM neighborhood_geocoding.trip_attractions
+        WHERE trip_id = $1 AND id = $2
+        RETURNING trip_id
+    )
+    UPDATE neighborhood_geocoding.trips
+    SET updated_at = now()
+    WHERE id IN (SELECT trip_id FROM removed)
+    `
+
+	result, err := connections.Init().Exec(removeAttractionQuery, tripID, attractionID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffectedRow(
+		result,
+		&NoTripFoundError{fmt.Sprintf("Trip %d has no attraction with id %d.", tripID, attractionID)})
+}
+
+// SaveLocatedTripAttractions stores attractions of the trip as located, so they are not geocoded again.
+// Attractions removed from the trip in the meantime are skipped.
+func SaveLocatedTripAttractions(tripID int64, attractions []TripAttraction) error {
+	tx, err := connections.Init().Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, attraction := range attractions {
+		// Matches depend on the boundaries at the time, so are found afresh on each evaluation.
+		attraction.NeighborhoodMatch = nil
+		jsn, err := json.Marshal(attraction.Attraction)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec(
+			"UPDATE neighborhood_geocoding.trip_attractions SET attraction = $3, located = true WHERE trip_id = $1 AND id = $2",
+			tripID,
+			attraction.ID,
+			string(jsn))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveTripResult stores the trip's latest plan.
+func SaveTripResult(tripID int64, result json.RawMessage) error {
+	saveResultQuery := `
+    UPDATE neighborhood_geocoding.trips
+    SET last_result = $2, evaluated_at = now()
+    WHERE id = $1
+    `
+
+	dbResult, err := connections.Init().Exec(saveResultQuery, tripID, string(result))
+	if err != nil {
+		return err
+	}
+
+	return requireAffectedRow(dbResult, noTripWithID(tripID))
+}
+
+func findTripAttractions(tripID int64) ([]TripAttraction, error) {
+	rows, err := connections.Init().Query(
+		"SELECT id, attraction::text, located FROM neighborhood_geocoding.trip_attractions WHERE trip_id = $1 ORDER BY id",
+		tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attractions := []TripAttraction{}
+	for rows.Next() {
+		var attraction TripAttraction
+		var jsn string
+		if err := rows.Scan(&attraction.ID, &jsn, &attraction.Located); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(jsn), &attraction.Attraction); err != nil {
+			return nil, err
+		}
+		attractions = append(attractions, attraction)
+	}
+
+	return attractions, rows.Err()
+}
+
+func insertTripAttractions(tx *sql.Tx, tripID int64, attractions []Attraction) error {
+	for _, attraction := range attractions {
+		attraction.NeighborhoodMatch = nil
+		attraction.Geocoding = nil
+		jsn, err := json.Marshal(attraction)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO neighborhood_geocoding.trip_attractions (trip_id, attraction) VALUES ($1, $2)",
+			tripID,
+			string(jsn))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns notFound when the statement affected no rows.
+func requireAffectedRow(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+func noTripWithID(tripID int64) error {
+	return &NoTripFoundError{fmt.Sprintf("No trip with id %d.", tripID)}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFindTrip_unknownTrip(t *testing.T) {
+	_, err := FindTrip(-1)
+
+	if _, ok := err.(*NoTripFoundError); !ok {
+		t.Errorf("Expected a NoTripFoundError for an unknown trip. Got: %v.", err)
+	}
+}
+
+func TestRemoveTripAttraction_unknownAttraction(t *testing.T) {
+	err := RemoveTripAttraction(-1, -1)
+
+	if _, ok := err.(*NoTripFoundError); !ok {
+		t.Errorf("Expected a NoTripFoundError for an unknown attraction. Got: %v.", err)
+	}
+}
+
+func TestSaveLocatedTripAttractions_notGeocodedAgain(t *testing.T) {
+	trip, err := CreateTrip("Vancouver", []Attraction{{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC"}}, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Unexpected error creating trip: %v", err)
+	}
+	defer DeleteTrip(trip.ID)
+
+	if len(trip.Attractions) != 1 || trip.Attractions[0].Located {
+		t.Fatalf("A new trip's attractions should not be located yet. Got: %+v.", trip.Attractions)
+	}
+
+	located := trip.Attractions[0]
+	located.Latitude = 49.2734
+	located.Longitude = -123.1038
+	if err := SaveLocatedTripAttractions(trip.ID, []TripAttraction{located}); err != nil {
+		t.Fatalf("Unexpected error saving located attractions: %v", err)
+	}
+
+	saved, err := FindTrip(trip.ID)
+	if err != nil {
+		t.Fatalf("Unexpected error finding trip: %v", err)
+	}
+
+	if !saved.Attractions[0].Located || saved.Attractions[0].Latitude != located.Latitude {
+		t.Errorf("The located attraction was not saved. Got: %+v.", saved.Attractions[0])
+	}
+}
+
+func TestAddTripAttractions_appendedInOrder(t *testing.T) {
+	trip, err := CreateTrip("", []Attraction{{Name: "Science World", City: "Vancouver", StateOrProvinceName: "BC"}}, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatalf("Unexpected error creating trip: %v", err)
+	}
+	defer DeleteTrip(trip.ID)
+
+	trip, err = AddTripAttractions(trip.ID, []Attraction{{Name: "Canada Place", City: "Vancouver", StateOrProvinceName: "BC"}})
+	if err != nil {
+		t.Fatalf("Unexpected error adding attractions: %v", err)
+	}
+
+	if len(trip.Attractions) != 2 || trip.Attractions[1].Name != "Canada Place" {
+		t.Errorf("The added attraction should come last. Got: %+v.", trip.Attractions)
+	}
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DistanceCache stores the results of PostGIS calculations (distances between neighborhoods and their
+// centers) so they can be shared between concurrent requests. Implementations must be safe for
+// concurrent use. A failing cache behaves as if it were empty.
+type DistanceCache interface {
+	GetDistance(key string) (float64, bool)
+	SetDistance(key string, distanceInMeters float64)
+	// Coordinates are stored as given, i.e, []float64{longitude, latitude}.
+	GetCoordinates(key string) ([]float64, bool)
+	SetCoordinates(key string, coordinates []float64)
+	// Clear drops every entry, i.e, after neighborhood boundaries change.
+	Clear()
+}
+
+// Key joins the components into a cache key. Each component is prefixed by its length, so no two
+// different lists of components produce the same key ("AB"+"C" and "A"+"BC" differ).
+func Key(components ...string) string {
+	var builder strings.Builder
+	for _, component := range components {
+		fmt.Fprintf(&builder, "%d:%s", len(component), component)
+	}
+
+	return builder.String()
+}
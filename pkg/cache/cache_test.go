@@ -0,0 +1,15 @@
+package cache
+
+import "testing"
+
+func TestKey_componentsCannotCollide(t *testing.T) {
+	if Key("AB", "C") == Key("A", "BC") {
+		t.Errorf("Keys for differing components should differ. Got: %s for both.", Key("AB", "C"))
+	}
+}
+
+func TestKey_sameComponentsSameKey(t *testing.T) {
+	if Key("Downtown", "Vancouver", "BC") != Key("Downtown", "Vancouver", "BC") {
+		t.Errorf("Keys for the same components should be equal.")
+	}
+}
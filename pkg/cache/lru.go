@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultLRUCapacity holds every pairwise distance for roughly a hundred neighborhoods.
+const DefaultLRUCapacity = 10000
+
+// LRUDistanceCache is an in-memory DistanceCache evicting the least recently used entry once full.
+type LRUDistanceCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// Most recently used at the front.
+	recency *list.List
+}
+
+type lruEntry struct {
+	key   string
+	value []float64
+}
+
+// NewLRUDistanceCache creates a cache holding at most capacity entries.
+func NewLRUDistanceCache(capacity int) *LRUDistanceCache {
+	if capacity < 1 {
+		capacity = DefaultLRUCapacity
+	}
+
+	return &LRUDistanceCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		recency:  list.New(),
+	}
+}
+
+// GetDistance returns the cached distance for the key, if any.
+func (c *LRUDistanceCache) GetDistance(key string) (float64, bool) {
+	value, ok := c.get(key)
+	if !ok || len(value) != 1 {
+		return 0.0, false
+	}
+
+	return value[0], true
+}
+
+// SetDistance caches the distance for the key.
+func (c *LRUDistanceCache) SetDistance(key string, distanceInMeters float64) {
+	c.set(key, []float64{distanceInMeters})
+}
+
+// GetCoordinates returns the cached coordinates for the key, if any.
+func (c *LRUDistanceCache) GetCoordinates(key string) ([]float64, bool) {
+	value, ok := c.get(key)
+	if !ok {
+		return nil, false
+	}
+
+	return append([]float64(nil), value...), true
+}
+
+// SetCoordinates caches the coordinates for the key.
+func (c *LRUDistanceCache) SetCoordinates(key string, coordinates []float64) {
+	c.set(key, append([]float64(nil), coordinates...))
+}
+
+// Clear drops every entry.
+func (c *LRUDistanceCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.recency.Init()
+}
+
+// Len returns the number of cached entries.
+func (c *LRUDistanceCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.recency.Len()
+}
+
+func (c *LRUDistanceCache) get(key string) ([]float64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.recency.MoveToFront(element)
+	return element.Value.(*lruEntry).value, true
+}
+
+func (c *LRUDistanceCache) set(key string, value []float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).value = value
+		c.recency.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.recency.PushFront(&lruEntry{key, value})
+	if c.recency.Len() > c.capacity {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
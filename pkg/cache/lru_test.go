@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestLRUDistanceCache_leastRecentlyUsedEvicted(t *testing.T) {
+	c := NewLRUDistanceCache(2)
+	c.SetDistance("a", 1.0)
+	c.SetDistance("b", 2.0)
+	c.GetDistance("a")
+	c.SetDistance("c", 3.0)
+
+	if _, ok := c.GetDistance("b"); ok {
+		t.Errorf("The least recently used entry should have been evicted.")
+	}
+
+	if distance, ok := c.GetDistance("a"); !ok || distance != 1.0 {
+		t.Errorf("Recently used entry was incorrect. Got: %.2f (found: %t), expected: %.2f.", distance, ok, 1.0)
+	}
+
+	if c.Len() != 2 {
+		t.Errorf("Number of entries was incorrect. Got: %d, expected: %d.", c.Len(), 2)
+	}
+}
+
+func TestLRUDistanceCache_coordinatesAreCopied(t *testing.T) {
+	c := NewLRUDistanceCache(DefaultLRUCapacity)
+	coordinates := []float64{-123.116626, 49.280705}
+	c.SetCoordinates("Downtown", coordinates)
+	coordinates[0] = 0.0
+
+	cached, ok := c.GetCoordinates("Downtown")
+	if !ok || cached[0] != -123.116626 {
+		t.Errorf("Cached coordinates should not change with the caller's slice. Got: %v.", cached)
+	}
+}
+
+func TestLRUDistanceCache_concurrentUse(t *testing.T) {
+	c := NewLRUDistanceCache(100)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i % 10)
+			c.SetDistance(key, float64(i))
+			c.GetDistance(key)
+		}(i)
+	}
+	wg.Wait()
+
+	if c.Len() != 10 {
+		t.Errorf("Number of entries was incorrect. Got: %d, expected: %d.", c.Len(), 10)
+	}
+}
+
+func TestLRUDistanceCache_clearDropsEveryEntry(t *testing.T) {
+	c := NewLRUDistanceCache(DefaultLRUCapacity)
+	c.SetDistance("a", 1.0)
+	c.SetCoordinates("b", []float64{-123.116626, 49.280705})
+
+	c.Clear()
+
+	if _, ok := c.GetDistance("a"); ok || c.Len() != 0 {
+		t.Errorf("Cache should be empty after clearing. Got %d entries.", c.Len())
+	}
+}
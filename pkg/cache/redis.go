@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRedisTTL bounds how stale a cached distance can become after neighborhoods change.
+const DefaultRedisTTL = 24 * time.Hour
+
+const redisTimeout = 2 * time.Second
+
+// After Redis cannot be reached, commands fail immediately, as cache misses, for this long rather than each
+// waiting out redisTimeout.
+const redisRetryInterval = 10 * time.Second
+
+// At most this many idle connections are kept for reuse; more are opened when commands run concurrently.
+const redisMaxIdleConnections = 8
+
+var errRedisUnavailable = errors.New("Redis is unavailable; not retrying yet")
+
+// RedisDistanceCache is a DistanceCache stored in Redis (or anything speaking its protocol), so that every
+// instance of the application shares it. Values are stored as comma-separated numbers under the given
+// key prefix and expire after the TTL.
+type RedisDistanceCache struct {
+	address   string
+	keyPrefix string
+	ttl       time.Duration
+
+	mutex sync.Mutex
+	// idle connections are reused by later commands.
+	idle []*redisConnection
+	// unavailableUntil is when Redis may be tried again after it could not be reached.
+	unavailableUntil time.Time
+	closed           bool
+}
+
+type redisConnection struct {
+	connection net.Conn
+	reader     *bufio.Reader
+}
+
+// NewRedisDistanceCache creates a cache for the Redis server at address (i.e, "localhost:6379").
+// Connections are made on first use.
+func NewRedisDistanceCache(address string, keyPrefix string, ttl time.Duration) *RedisDistanceCache {
+	return &RedisDistanceCache{address: address, keyPrefix: keyPrefix, ttl: ttl}
+}
+
+// GetDistance returns the cached distance for the key, if any.
+func (c *RedisDistanceCache) GetDistance(key string) (float64, bool) {
+	value, ok := c.get(key)
+	if !ok || len(value) != 1 {
+		return 0.0, false
+	}
+
+	return value[0], true
+}
+
+// SetDistance caches the distance for the key.
+func (c *RedisDistanceCache) SetDistance(key string, distanceInMeters float64) {
+	c.set(key, []float64{distanceInMeters})
+}
+
+// GetCoordinates returns the cached coordinates for the key, if any.
+func (c *RedisDistanceCache) GetCoordinates(key string) ([]float64, bool) {
+	return c.get(key)
+}
+
+// SetCoordinates caches the coordinates for the key.
+func (c *RedisDistanceCache) SetCoordinates(key string, coordinates []float64) {
+	c.set(key, coordinates)
+}
+
+// Clear deletes every key under the cache's prefix. Keys are found with SCAN so other clients are not
+// blocked as they would be by KEYS.
+func (c *RedisDistanceCache) Clear() {
+	cursor := "0"
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", c.keyPrefix+"*", "COUNT", "1000")
+		if err != nil {
+			log.Printf("Unable to clear Redis cache; having error: %v", err)
+			return
+		}
+
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			log.Printf("Unable to clear Redis cache; unexpected SCAN reply %v", reply)
+			return
+		}
+
+		keys, _ := page[1].([]interface{})
+		if len(keys) > 0 {
+			args := []string{"DEL"}
+			for _, key := range keys {
+				args = append(args, fmt.Sprint(key))
+			}
+			if _, err := c.do(args...); err != nil {
+				log.Printf("Unable to clear Redis cache; having error: %v", err)
+				return
+			}
+		}
+
+		cursor = fmt.Sprint(page[0])
+		if cursor == "0" {
+			return
+		}
+	}
+}
+
+// Close closes the connections to Redis. Commands still running close theirs as they finish.
+func (c *RedisDistanceCache) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var err error
+	for _, idle := range c.idle {
+		if closeErr := idle.connection.Close(); closeErr != nil {
+			err = closeErr
+		}
+	}
+	c.idle = nil
+	c.closed = true
+	return err
+}
+
+func (c *RedisDistanceCache) get(key string) ([]float64, bool) {
+	reply, err := c.do("GET", c.keyPrefix+key)
+	if err != nil {
+		if err != errRedisUnavailable {
+			log.Printf("Unable to read from Redis cache; having error: %v", err)
+		}
+		return nil, false
+	}
+
+	encoded, ok := reply.(string)
+	if !ok {
+		return nil, false
+	}
+
+	var value []float64
+	for _, number := range strings.Split(encoded, ",") {
+		parsed, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, false
+		}
+		value = append(value, parsed)
+	}
+
+	return value, true
+}
+
+func (c *RedisDistanceCache) set(key string, value []float64) {
+	numbers := make([]string, len(value))
+	for i, number := range value {
+		numbers[i] = strconv.FormatFloat(number, 'g', -1, 64)
+	}
+
+	args := []string{"SET", c.keyPrefix + key, strings.Join(numbers, ",")}
+	if c.ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(int64(c.ttl/time.Millisecond), 10))
+	}
+
+	if _, err := c.do(args...); err != nil && err != errRedisUnavailable {
+		log.Printf("Unable to write to Redis cache; having error: %v", err)
+	}
+}
+
+// Sends a command and reads its reply. Bulk strings are returned as strings, nil bulk strings as nil and
+// arrays as []interface{}.
+// A connection is dropped after any error but an error reply. Once Redis cannot be reached or times out,
+// errRedisUnavailable is returned without trying it again for redisRetryInterval.
+func (c *RedisDistanceCache) do(args ...string) (interface{}, error) {
+	connection, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := connection.roundTrip(args)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		connection.connection.Close()
+		// A timed out server is skipped, as dialing it again would likely time out too; other errors
+		// (i.e, an idle connection the server has since closed) only cost the connection.
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			c.markUnavailable(err)
+		}
+		return nil, err
+	}
+
+	c.release(connection)
+	return reply, err
+}
+
+// Returns an idle connection, or dials a new one.
+func (c *RedisDistanceCache) acquire() (*redisConnection, error) {
+	c.mutex.Lock()
+	if time.Now().Before(c.unavailableUntil) {
+		c.mutex.Unlock()
+		return nil, errRedisUnavailable
+	}
+	if n := len(c.idle); n > 0 {
+		connection := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mutex.Unlock()
+		return connection, nil
+	}
+	c.mutex.Unlock()
+
+	connection, err := net.DialTimeout("tcp", c.address, redisTimeout)
+	if err != nil {
+		c.markUnavailable(err)
+		return nil, err
+	}
+
+	return &redisConnection{connection, bufio.NewReader(connection)}, nil
+}
+
+// Keeps the connection for reuse, unless enough are idle already or the cache is closed.
+func (c *RedisDistanceCache) release(connection *redisConnection) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed || len(c.idle) >= redisMaxIdleConnections {
+		connection.connection.Close()
+		return
+	}
+	c.idle = append(c.idle, connection)
+}
+
+// Stops commands trying Redis for redisRetryInterval, closing the idle connections, which are likely broken
+// too.
+func (c *RedisDistanceCache) markUnavailable(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if time.Now().Before(c.unavailableUntil) {
+		return
+	}
+
+	log.Printf("Unable to reach Redis cache, skipping it for %v; having error: %v", redisRetryInterval, err)
+	c.unavailableUntil = time.Now().Add(redisRetryInterval)
+	for _, idle := range c.idle {
+		idle.connection.Close()
+	}
+	c.idle = nil
+}
+
+func (c *redisConnection) roundTrip(args []string) (interface{}, error) {
+	c.connection.SetDeadline(time.Now().Add(redisTimeout))
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(c.connection, command.String()); err != nil {
+		return nil, err
+	}
+
+	return readRedisReply(c.reader)
+}
+
+// redisError is an error reply from the server (i.e, "-ERR unknown command").
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+func readRedisReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if len(line) == 0 {
+		return nil, fmt.Errorf("empty reply from Redis")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+
+		bulk := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, bulk); err != nil {
+			return nil, err
+		}
+		return string(bulk[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, nil
+		}
+
+		elements := make([]interface{}, length)
+		for i := range elements {
+			if elements[i], err = readRedisReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("unsupported Redis reply %q", line)
+	}
+}
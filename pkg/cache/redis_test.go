@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedisServer is a local stand-in speaking enough of the Redis protocol for GET, SET, SCAN and DEL.
+// SCAN returns every matching key in a single page.
+type fakeRedisServer struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	values   map[string]string
+	commands [][]string
+}
+
+func newFakeRedisServer(t *testing.T) *fakeRedisServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to start fake Redis server: %v", err)
+	}
+
+	server := &fakeRedisServer{listener: listener, values: make(map[string]string)}
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(connection)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeRedisServer) serve(connection net.Conn) {
+	defer connection.Close()
+	reader := bufio.NewReader(connection)
+	for {
+		args, err := readFakeRedisCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mutex.Lock()
+		s.commands = append(s.commands, args)
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			if value, ok := s.values[args[1]]; ok {
+				fmt.Fprintf(connection, "$%d\r\n%s\r\n", len(value), value)
+			} else {
+				io.WriteString(connection, "$-1\r\n")
+			}
+		case "SET":
+			s.values[args[1]] = args[2]
+			io.WriteString(connection, "+OK\r\n")
+		case "SCAN":
+			var keys []string
+			for key := range s.values {
+				if strings.HasPrefix(key, strings.TrimSuffix(args[3], "*")) {
+					keys = append(keys, key)
+				}
+			}
+			fmt.Fprintf(connection, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+			for _, key := range keys {
+				fmt.Fprintf(connection, "$%d\r\n%s\r\n", len(key), key)
+			}
+		case "DEL":
+			for _, key := range args[1:] {
+				delete(s.values, key)
+			}
+			fmt.Fprintf(connection, ":%d\r\n", len(args)-1)
+		default:
+			io.WriteString(connection, "-ERR unknown command\r\n")
+		}
+		s.mutex.Unlock()
+	}
+}
+
+func readFakeRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, count)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+
+	return args, nil
+}
+
+func TestRedisDistanceCache_valuesRoundTrip(t *testing.T) {
+	server := newFakeRedisServer(t)
+	defer server.listener.Close()
+
+	c := NewRedisDistanceCache(server.listener.Addr().String(), "distances:", time.Minute)
+	defer c.Close()
+
+	c.SetDistance("a", 1234.5)
+	c.SetCoordinates("b", []float64{-123.116626, 49.280705})
+
+	if distance, ok := c.GetDistance("a"); !ok || distance != 1234.5 {
+		t.Errorf("Cached distance was incorrect. Got: %.2f (found: %t), expected: %.2f.", distance, ok, 1234.5)
+	}
+
+	coordinates, ok := c.GetCoordinates("b")
+	if !ok || len(coordinates) != 2 || coordinates[0] != -123.116626 || coordinates[1] != 49.280705 {
+		t.Errorf("Cached coordinates were incorrect. Got: %v (found: %t).", coordinates, ok)
+	}
+
+	if _, ok := c.GetDistance("missing"); ok {
+		t.Errorf("A missing key should not be found.")
+	}
+}
+
+func TestRedisDistanceCache_keysPrefixedAndExpire(t *testing.T) {
+	server := newFakeRedisServer(t)
+	defer server.listener.Close()
+
+	c := NewRedisDistanceCache(server.listener.Addr().String(), "distances:", time.Minute)
+	defer c.Close()
+	c.SetDistance("a", 1.0)
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	expectedCommand := []string{"SET", "distances:a", "1", "PX", "60000"}
+	if fmt.Sprint(server.commands[0]) != fmt.Sprint(expectedCommand) {
+		t.Errorf("SET command was incorrect. Got: %v, expected: %v.", server.commands[0], expectedCommand)
+	}
+}
+
+func TestRedisDistanceCache_unreachableServerIsAMiss(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	listener.Close()
+
+	c := NewRedisDistanceCache(address, "", time.Minute)
+	c.SetDistance("a", 1.0)
+
+	if _, ok := c.GetDistance("a"); ok {
+		t.Errorf("An unreachable cache should behave as if it were empty.")
+	}
+}
+
+func TestRedisDistanceCache_clearDeletesPrefixedKeysOnly(t *testing.T) {
+	server := newFakeRedisServer(t)
+	defer server.listener.Close()
+	server.values["other:a"] = "1"
+
+	c := NewRedisDistanceCache(server.listener.Addr().String(), "distances:", time.Minute)
+	defer c.Close()
+	c.SetDistance("a", 1.0)
+	c.SetCoordinates("b", []float64{-123.116626, 49.280705})
+
+	c.Clear()
+
+	if _, ok := c.GetDistance("a"); ok {
+		t.Errorf("Cleared entries should not be found.")
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if _, ok := server.values["other:a"]; !ok {
+		t.Errorf("Keys outside the cache's prefix should not be deleted.")
+	}
+}
+
+func TestRedisDistanceCache_unreachableServerSkippedUntilRetry(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	listener.Close()
+
+	c := NewRedisDistanceCache(address, "", time.Minute)
+	if _, err := c.do("GET", "a"); err == nil || err == errRedisUnavailable {
+		t.Fatalf("Expected the first command to fail dialing. Got: %v.", err)
+	}
+
+	if _, err := c.do("GET", "a"); err != errRedisUnavailable {
+		t.Errorf("Expected Redis to be skipped after failing to dial. Got: %v.", err)
+	}
+
+	c.mutex.Lock()
+	c.unavailableUntil = time.Now()
+	c.mutex.Unlock()
+
+	if _, err := c.do("GET", "a"); err == errRedisUnavailable {
+		t.Errorf("Expected Redis to be tried again once the retry interval elapsed.")
+	}
+}
+
+func TestRedisDistanceCache_connectionsReused(t *testing.T) {
+	server := newFakeRedisServer(t)
+	defer server.listener.Close()
+
+	c := NewRedisDistanceCache(server.listener.Addr().String(), "", time.Minute)
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		c.SetDistance("a", 1.0)
+	}
+
+	c.mutex.Lock()
+	idle := len(c.idle)
+	c.mutex.Unlock()
+	if idle != 1 {
+		t.Errorf("Expected a single connection to be kept and reused. Got: %d idle.", idle)
+	}
+}
+
+func TestRedisDistanceCache_idleConnectionsBounded(t *testing.T) {
+	server := newFakeRedisServer(t)
+	defer server.listener.Close()
+
+	c := NewRedisDistanceCache(server.listener.Addr().String(), "", time.Minute)
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4*redisMaxIdleConnections; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.SetDistance(strconv.Itoa(i), float64(i))
+		}(i)
+	}
+	wg.Wait()
+
+	if _, ok := c.GetDistance("3"); !ok {
+		t.Errorf("Expected every concurrent write to be cached.")
+	}
+
+	c.mutex.Lock()
+	idle := len(c.idle)
+	c.mutex.Unlock()
+	if idle > redisMaxIdleConnections {
+		t.Errorf("Expected at most %d idle connections. Got: %d.", redisMaxIdleConnections, idle)
+	}
+}
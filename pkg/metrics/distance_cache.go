@@ -0,0 +1,25 @@
+package metrics
+
+import "../cache"
+
+// instrumentedDistanceCache counts the hits and misses of the cache it wraps.
+type instrumentedDistanceCache struct {
+	cache.DistanceCache
+}
+
+// InstrumentDistanceCache wraps the cache so its hit rate is recorded.
+func InstrumentDistanceCache(c cache.DistanceCache) cache.DistanceCache {
+	return &instrumentedDistanceCache{c}
+}
+
+func (c *instrumentedDistanceCache) GetDistance(key string) (float64, bool) {
+	distanceInMeters, ok := c.DistanceCache.GetDistance(key)
+	observeCacheLookup("distance", ok)
+	return distanceInMeters, ok
+}
+
+func (c *instrumentedDistanceCache) GetCoordinates(key string) ([]float64, bool) {
+	coordinates, ok := c.DistanceCache.GetCoordinates(key)
+	observeCacheLookup("coordinates", ok)
+	return coordinates, ok
+}
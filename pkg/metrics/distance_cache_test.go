@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"testing"
+
+	"../cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentDistanceCache_hitsAndMissesCounted(t *testing.T) {
+	hits := testutil.ToFloat64(distanceCacheLookups.WithLabelValues("distance", "hit"))
+	misses := testutil.ToFloat64(distanceCacheLookups.WithLabelValues("distance", "miss"))
+	c := InstrumentDistanceCache(cache.NewLRUDistanceCache(cache.DefaultLRUCapacity))
+
+	c.SetDistance("a", 1.0)
+	c.GetDistance("a")
+	c.GetDistance("a")
+	c.GetDistance("b")
+
+	if got := testutil.ToFloat64(distanceCacheLookups.WithLabelValues("distance", "hit")) - hits; got != 2 {
+		t.Errorf("Number of hits was incorrect. Got: %.0f, expected: 2.", got)
+	}
+
+	if got := testutil.ToFloat64(distanceCacheLookups.WithLabelValues("distance", "miss")) - misses; got != 1 {
+		t.Errorf("Number of misses was incorrect. Got: %.0f, expected: 1.", got)
+	}
+}
+
+func TestInstrumentDistanceCache_valuesPassedThrough(t *testing.T) {
+	c := InstrumentDistanceCache(cache.NewLRUDistanceCache(cache.DefaultLRUCapacity))
+
+	c.SetCoordinates("a", []float64{-123.1, 49.2})
+	coordinates, ok := c.GetCoordinates("a")
+
+	if !ok || len(coordinates) != 2 || coordinates[0] != -123.1 {
+		t.Errorf("Cached coordinates were incorrect. Got: %v (found: %t), expected: [-123.1 49.2].", coordinates, ok)
+	}
+}
@@ -0,0 +1,99 @@
+// Package metrics records how the planner performs, for Prometheus to scrape from Handler.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "planner"
+
+// Planning requests wait on Nominatim about once a second per attraction, so request latencies run from
+// milliseconds to minutes.
+var requestDurationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Outcomes of geocoder requests. Searches matching nothing still succeed.
+const (
+	GeocoderSucceeded = "success"
+	GeocoderFailed    = "failure"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route, method and status code.",
+		Buckets:   requestDurationBuckets,
+	}, []string{"route", "method", "code"})
+
+	geocoderRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "geocoder_requests_total",
+		Help:      "Geocoder requests by provider and outcome.",
+	}, []string{"provider", "outcome"})
+
+	geocoderDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "geocoder_request_duration_seconds",
+		Help:      "Latency of geocoder requests by provider.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"provider"})
+
+	distanceCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "distance_cache_lookups_total",
+		Help:      "Distance cache lookups by kind of entry (distance or coordinates) and result (hit or miss).",
+	}, []string{"kind", "result"})
+
+	queryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Latency of database queries by query.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query"})
+)
+
+func init() {
+	prometheus.MustRegister(requestDuration, geocoderRequests, geocoderDuration, distanceCacheLookups, queryDuration)
+}
+
+// Handler serves every metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// InstrumentHandler records the latency of the handler's requests under the route (i.e, its ServeMux
+// pattern, which keeps paths with ids from each becoming a series). Streaming responses can still be
+// flushed.
+func InstrumentHandler(route string, handler http.Handler) http.Handler {
+	return promhttp.InstrumentHandlerDuration(requestDuration.MustCurryWith(prometheus.Labels{"route": route}), handler)
+}
+
+// ObserveGeocoderRequest records a request to the provider which started at started and failed with err,
+// if not nil.
+func ObserveGeocoderRequest(provider string, started time.Time, err error) {
+	outcome := GeocoderSucceeded
+	if err != nil {
+		outcome = GeocoderFailed
+	}
+
+	geocoderRequests.WithLabelValues(provider, outcome).Inc()
+	geocoderDuration.WithLabelValues(provider).Observe(time.Since(started).Seconds())
+}
+
+// ObserveQuery records the duration of the named database query, which started at started.
+func ObserveQuery(query string, started time.Time) {
+	queryDuration.WithLabelValues(query).Observe(time.Since(started).Seconds())
+}
+
+func observeCacheLookup(kind string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	distanceCacheLookups.WithLabelValues(kind, result).Inc()
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentHandler_streamingResponsesStillFlushed(t *testing.T) {
+	flushed := false
+	handler := InstrumentHandler("/attractions/stream", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flushed = w.(http.Flusher)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/attractions/stream", nil))
+
+	if !flushed {
+		t.Errorf("The instrumented response writer should still be an http.Flusher.")
+	}
+}
+
+func TestInstrumentHandler_latencyRecordedByRoute(t *testing.T) {
+	handler := InstrumentHandler("/trips/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/trips/42", nil))
+
+	expected := `planner_http_request_duration_seconds_count{code="404",method="get",route="/trips/"} 1`
+	if !strings.Contains(scrape(t), expected) {
+		t.Errorf("Expected the request to be recorded under its route as %s.", expected)
+	}
+}
+
+func TestObserveGeocoderRequest_failuresCountedPerProvider(t *testing.T) {
+	failures := testutil.ToFloat64(geocoderRequests.WithLabelValues("test", GeocoderFailed))
+
+	ObserveGeocoderRequest("test", time.Now(), errors.New("timeout"))
+	ObserveGeocoderRequest("test", time.Now(), nil)
+
+	if got := testutil.ToFloat64(geocoderRequests.WithLabelValues("test", GeocoderFailed)) - failures; got != 1 {
+		t.Errorf("Number of failures was incorrect. Got: %.0f, expected: 1.", got)
+	}
+}
+
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Unexpected status scraping metrics: %d", recorder.Code)
+	}
+
+	return recorder.Body.String()
+}
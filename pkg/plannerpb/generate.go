@@ -0,0 +1,9 @@
+// Package plannerpb holds the protobuf messages and gRPC service of the planner, generated from
+// planner.proto. Regenerate after changing it with:
+//
+//	go generate ./pkg/plannerpb
+//
+// which requires protoc, protoc-gen-go v1.36.11 and protoc-gen-go-grpc v1.5.1 on the PATH.
+package plannerpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative planner.proto
@@ -0,0 +1,1452 @@
+// Planner finds the best neighborhood to stay in for a trip's attractions. Messages mirror the JSON of
+// the HTTP API (see /v1/openapi.json); field names match its JSON keys.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: planner.proto
+
+package plannerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FindBestNeighborhoodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attractions   []*Attraction          `protobuf:"bytes,1,rep,name=attractions,proto3" json:"attractions,omitempty"`
+	Preferences   *PlanningPreferences   `protobuf:"bytes,2,opt,name=preferences,proto3" json:"preferences,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FindBestNeighborhoodRequest) Reset() {
+	*x = FindBestNeighborhoodRequest{}
+	mi := &file_planner_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FindBestNeighborhoodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FindBestNeighborhoodRequest) ProtoMessage() {}
+
+func (x *FindBestNeighborhoodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FindBestNeighborhoodRequest.ProtoReflect.Descriptor instead.
+func (*FindBestNeighborhoodRequest) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FindBestNeighborhoodRequest) GetAttractions() []*Attraction {
+	if x != nil {
+		return x.Attractions
+	}
+	return nil
+}
+
+func (x *FindBestNeighborhoodRequest) GetPreferences() *PlanningPreferences {
+	if x != nil {
+		return x.Preferences
+	}
+	return nil
+}
+
+// Unset preferences take the server's defaults.
+type PlanningPreferences struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	NearCityBufferInMeters *float64               `protobuf:"fixed64,1,opt,name=near_city_buffer_in_meters,json=nearCityBufferInMeters,proto3,oneof" json:"near_city_buffer_in_meters,omitempty"`
+	// One of centroid, point_on_surface, population_weighted, listing_density_weighted or edge_distance.
+	ScoringStrategy string `protobuf:"bytes,2,opt,name=scoring_strategy,json=scoringStrategy,proto3" json:"scoring_strategy,omitempty"`
+	// One of city, district, neighborhood or sub_neighborhood.
+	Granularity   string `protobuf:"bytes,3,opt,name=granularity,proto3" json:"granularity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanningPreferences) Reset() {
+	*x = PlanningPreferences{}
+	mi := &file_planner_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanningPreferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanningPreferences) ProtoMessage() {}
+
+func (x *PlanningPreferences) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanningPreferences.ProtoReflect.Descriptor instead.
+func (*PlanningPreferences) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PlanningPreferences) GetNearCityBufferInMeters() float64 {
+	if x != nil && x.NearCityBufferInMeters != nil {
+		return *x.NearCityBufferInMeters
+	}
+	return 0
+}
+
+func (x *PlanningPreferences) GetScoringStrategy() string {
+	if x != nil {
+		return x.ScoringStrategy
+	}
+	return ""
+}
+
+func (x *PlanningPreferences) GetGranularity() string {
+	if x != nil {
+		return x.Granularity
+	}
+	return ""
+}
+
+type ResolveAttractionsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ResolveAttractionsResponse_Resolution
+	//	*ResolveAttractionsResponse_Result
+	Event         isResolveAttractionsResponse_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveAttractionsResponse) Reset() {
+	*x = ResolveAttractionsResponse{}
+	mi := &file_planner_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveAttractionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveAttractionsResponse) ProtoMessage() {}
+
+func (x *ResolveAttractionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveAttractionsResponse.ProtoReflect.Descriptor instead.
+func (*ResolveAttractionsResponse) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResolveAttractionsResponse) GetEvent() isResolveAttractionsResponse_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ResolveAttractionsResponse) GetResolution() *AttractionResolution {
+	if x != nil {
+		if x, ok := x.Event.(*ResolveAttractionsResponse_Resolution); ok {
+			return x.Resolution
+		}
+	}
+	return nil
+}
+
+func (x *ResolveAttractionsResponse) GetResult() *AttractionsResponse {
+	if x != nil {
+		if x, ok := x.Event.(*ResolveAttractionsResponse_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isResolveAttractionsResponse_Event interface {
+	isResolveAttractionsResponse_Event()
+}
+
+type ResolveAttractionsResponse_Resolution struct {
+	Resolution *AttractionResolution `protobuf:"bytes,1,opt,name=resolution,proto3,oneof"`
+}
+
+type ResolveAttractionsResponse_Result struct {
+	Result *AttractionsResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*ResolveAttractionsResponse_Resolution) isResolveAttractionsResponse_Event() {}
+
+func (*ResolveAttractionsResponse_Result) isResolveAttractionsResponse_Event() {}
+
+type AttractionResolution struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Attraction *Attraction            `protobuf:"bytes,1,opt,name=attraction,proto3" json:"attraction,omitempty"`
+	// Set when the attraction could not be located.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	// One of inside_city, near_city or outside_city; empty until the attraction is classified.
+	Classification string `protobuf:"bytes,3,opt,name=classification,proto3" json:"classification,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AttractionResolution) Reset() {
+	*x = AttractionResolution{}
+	mi := &file_planner_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttractionResolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttractionResolution) ProtoMessage() {}
+
+func (x *AttractionResolution) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttractionResolution.ProtoReflect.Descriptor instead.
+func (*AttractionResolution) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AttractionResolution) GetAttraction() *Attraction {
+	if x != nil {
+		return x.Attraction
+	}
+	return nil
+}
+
+func (x *AttractionResolution) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AttractionResolution) GetClassification() string {
+	if x != nil {
+		return x.Classification
+	}
+	return ""
+}
+
+type Attraction struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Name                string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	City                string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	StateOrProvinceName string                 `protobuf:"bytes,3,opt,name=state_or_province_name,json=stateOrProvinceName,proto3" json:"state_or_province_name,omitempty"`
+	Country             string                 `protobuf:"bytes,4,opt,name=country,proto3" json:"country,omitempty"`
+	Latitude            float64                `protobuf:"fixed64,5,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude           float64                `protobuf:"fixed64,6,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Weight              float64                `protobuf:"fixed64,7,opt,name=weight,proto3" json:"weight,omitempty"`
+	Notes               string                 `protobuf:"bytes,8,opt,name=notes,proto3" json:"notes,omitempty"`
+	CoordinatePrecision int32                  `protobuf:"varint,9,opt,name=coordinate_precision,json=coordinatePrecision,proto3" json:"coordinate_precision,omitempty"`
+	// Geocoding and neighborhood_match are ignored on input.
+	Geocoding         *GeocodingResult   `protobuf:"bytes,10,opt,name=geocoding,proto3" json:"geocoding,omitempty"`
+	NeighborhoodMatch *NeighborhoodMatch `protobuf:"bytes,11,opt,name=neighborhood_match,json=neighborhoodMatch,proto3" json:"neighborhood_match,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Attraction) Reset() {
+	*x = Attraction{}
+	mi := &file_planner_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attraction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attraction) ProtoMessage() {}
+
+func (x *Attraction) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attraction.ProtoReflect.Descriptor instead.
+func (*Attraction) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Attraction) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Attraction) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Attraction) GetStateOrProvinceName() string {
+	if x != nil {
+		return x.StateOrProvinceName
+	}
+	return ""
+}
+
+func (x *Attraction) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Attraction) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Attraction) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Attraction) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *Attraction) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Attraction) GetCoordinatePrecision() int32 {
+	if x != nil {
+		return x.CoordinatePrecision
+	}
+	return 0
+}
+
+func (x *Attraction) GetGeocoding() *GeocodingResult {
+	if x != nil {
+		return x.Geocoding
+	}
+	return nil
+}
+
+func (x *Attraction) GetNeighborhoodMatch() *NeighborhoodMatch {
+	if x != nil {
+		return x.NeighborhoodMatch
+	}
+	return nil
+}
+
+type GeocodingCandidate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DisplayName   string                 `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	BoundingBox   *BoundingBox           `protobuf:"bytes,5,opt,name=bounding_box,json=boundingBox,proto3" json:"bounding_box,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GeocodingCandidate) Reset() {
+	*x = GeocodingCandidate{}
+	mi := &file_planner_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeocodingCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeocodingCandidate) ProtoMessage() {}
+
+func (x *GeocodingCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeocodingCandidate.ProtoReflect.Descriptor instead.
+func (*GeocodingCandidate) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GeocodingCandidate) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *GeocodingCandidate) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *GeocodingCandidate) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GeocodingCandidate) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *GeocodingCandidate) GetBoundingBox() *BoundingBox {
+	if x != nil {
+		return x.BoundingBox
+	}
+	return nil
+}
+
+type GeocodingResult struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Provider         string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Candidate        *GeocodingCandidate    `protobuf:"bytes,2,opt,name=candidate,proto3" json:"candidate,omitempty"`
+	Alternatives     []*GeocodingCandidate  `protobuf:"bytes,3,rep,name=alternatives,proto3" json:"alternatives,omitempty"`
+	Ambiguous        bool                   `protobuf:"varint,4,opt,name=ambiguous,proto3" json:"ambiguous,omitempty"`
+	Suspicious       bool                   `protobuf:"varint,5,opt,name=suspicious,proto3" json:"suspicious,omitempty"`
+	SuspiciousReason string                 `protobuf:"bytes,6,opt,name=suspicious_reason,json=suspiciousReason,proto3" json:"suspicious_reason,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GeocodingResult) Reset() {
+	*x = GeocodingResult{}
+	mi := &file_planner_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeocodingResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeocodingResult) ProtoMessage() {}
+
+func (x *GeocodingResult) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeocodingResult.ProtoReflect.Descriptor instead.
+func (*GeocodingResult) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GeocodingResult) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *GeocodingResult) GetCandidate() *GeocodingCandidate {
+	if x != nil {
+		return x.Candidate
+	}
+	return nil
+}
+
+func (x *GeocodingResult) GetAlternatives() []*GeocodingCandidate {
+	if x != nil {
+		return x.Alternatives
+	}
+	return nil
+}
+
+func (x *GeocodingResult) GetAmbiguous() bool {
+	if x != nil {
+		return x.Ambiguous
+	}
+	return false
+}
+
+func (x *GeocodingResult) GetSuspicious() bool {
+	if x != nil {
+		return x.Suspicious
+	}
+	return false
+}
+
+func (x *GeocodingResult) GetSuspiciousReason() string {
+	if x != nil {
+		return x.SuspiciousReason
+	}
+	return ""
+}
+
+type BoundingBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLatitude   float64                `protobuf:"fixed64,1,opt,name=min_latitude,json=minLatitude,proto3" json:"min_latitude,omitempty"`
+	MinLongitude  float64                `protobuf:"fixed64,2,opt,name=min_longitude,json=minLongitude,proto3" json:"min_longitude,omitempty"`
+	MaxLatitude   float64                `protobuf:"fixed64,3,opt,name=max_latitude,json=maxLatitude,proto3" json:"max_latitude,omitempty"`
+	MaxLongitude  float64                `protobuf:"fixed64,4,opt,name=max_longitude,json=maxLongitude,proto3" json:"max_longitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BoundingBox) Reset() {
+	*x = BoundingBox{}
+	mi := &file_planner_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BoundingBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoundingBox) ProtoMessage() {}
+
+func (x *BoundingBox) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoundingBox.ProtoReflect.Descriptor instead.
+func (*BoundingBox) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BoundingBox) GetMinLatitude() float64 {
+	if x != nil {
+		return x.MinLatitude
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMinLongitude() float64 {
+	if x != nil {
+		return x.MinLongitude
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMaxLatitude() float64 {
+	if x != nil {
+		return x.MaxLatitude
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMaxLongitude() float64 {
+	if x != nil {
+		return x.MaxLongitude
+	}
+	return 0
+}
+
+type NeighborhoodMatch struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Neighborhood *Neighborhood          `protobuf:"bytes,1,opt,name=neighborhood,proto3" json:"neighborhood,omitempty"`
+	// covers or nearest.
+	MatchType        string  `protobuf:"bytes,2,opt,name=match_type,json=matchType,proto3" json:"match_type,omitempty"`
+	DistanceInMeters float64 `protobuf:"fixed64,3,opt,name=distance_in_meters,json=distanceInMeters,proto3" json:"distance_in_meters,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *NeighborhoodMatch) Reset() {
+	*x = NeighborhoodMatch{}
+	mi := &file_planner_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborhoodMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborhoodMatch) ProtoMessage() {}
+
+func (x *NeighborhoodMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborhoodMatch.ProtoReflect.Descriptor instead.
+func (*NeighborhoodMatch) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *NeighborhoodMatch) GetNeighborhood() *Neighborhood {
+	if x != nil {
+		return x.Neighborhood
+	}
+	return nil
+}
+
+func (x *NeighborhoodMatch) GetMatchType() string {
+	if x != nil {
+		return x.MatchType
+	}
+	return ""
+}
+
+func (x *NeighborhoodMatch) GetDistanceInMeters() float64 {
+	if x != nil {
+		return x.DistanceInMeters
+	}
+	return 0
+}
+
+type Neighborhood struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CityName            string                 `protobuf:"bytes,3,opt,name=city_name,json=cityName,proto3" json:"city_name,omitempty"`
+	StateOrProvinceName string                 `protobuf:"bytes,4,opt,name=state_or_province_name,json=stateOrProvinceName,proto3" json:"state_or_province_name,omitempty"`
+	Country             string                 `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	Latitude            float64                `protobuf:"fixed64,6,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude           float64                `protobuf:"fixed64,7,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	ParentId            int64                  `protobuf:"varint,8,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	Level               string                 `protobuf:"bytes,9,opt,name=level,proto3" json:"level,omitempty"`
+	Ancestors           []*Neighborhood        `protobuf:"bytes,10,rep,name=ancestors,proto3" json:"ancestors,omitempty"`
+	DatasetId           int64                  `protobuf:"varint,11,opt,name=dataset_id,json=datasetId,proto3" json:"dataset_id,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Neighborhood) Reset() {
+	*x = Neighborhood{}
+	mi := &file_planner_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Neighborhood) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Neighborhood) ProtoMessage() {}
+
+func (x *Neighborhood) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Neighborhood.ProtoReflect.Descriptor instead.
+func (*Neighborhood) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Neighborhood) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Neighborhood) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Neighborhood) GetCityName() string {
+	if x != nil {
+		return x.CityName
+	}
+	return ""
+}
+
+func (x *Neighborhood) GetStateOrProvinceName() string {
+	if x != nil {
+		return x.StateOrProvinceName
+	}
+	return ""
+}
+
+func (x *Neighborhood) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Neighborhood) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Neighborhood) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Neighborhood) GetParentId() int64 {
+	if x != nil {
+		return x.ParentId
+	}
+	return 0
+}
+
+func (x *Neighborhood) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *Neighborhood) GetAncestors() []*Neighborhood {
+	if x != nil {
+		return x.Ancestors
+	}
+	return nil
+}
+
+func (x *Neighborhood) GetDatasetId() int64 {
+	if x != nil {
+		return x.DatasetId
+	}
+	return 0
+}
+
+type RegionCity struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	CityName            string                 `protobuf:"bytes,1,opt,name=city_name,json=cityName,proto3" json:"city_name,omitempty"`
+	StateOrProvinceName string                 `protobuf:"bytes,2,opt,name=state_or_province_name,json=stateOrProvinceName,proto3" json:"state_or_province_name,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RegionCity) Reset() {
+	*x = RegionCity{}
+	mi := &file_planner_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegionCity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegionCity) ProtoMessage() {}
+
+func (x *RegionCity) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegionCity.ProtoReflect.Descriptor instead.
+func (*RegionCity) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RegionCity) GetCityName() string {
+	if x != nil {
+		return x.CityName
+	}
+	return ""
+}
+
+func (x *RegionCity) GetStateOrProvinceName() string {
+	if x != nil {
+		return x.StateOrProvinceName
+	}
+	return ""
+}
+
+type Region struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cities        []*RegionCity          `protobuf:"bytes,1,rep,name=cities,proto3" json:"cities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Region) Reset() {
+	*x = Region{}
+	mi := &file_planner_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Region) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Region) ProtoMessage() {}
+
+func (x *Region) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Region.ProtoReflect.Descriptor instead.
+func (*Region) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Region) GetCities() []*RegionCity {
+	if x != nil {
+		return x.Cities
+	}
+	return nil
+}
+
+type CityStatistics struct {
+	state                       protoimpl.MessageState `protogen:"open.v1"`
+	CityName                    string                 `protobuf:"bytes,1,opt,name=city_name,json=cityName,proto3" json:"city_name,omitempty"`
+	StateOrProvinceName         string                 `protobuf:"bytes,2,opt,name=state_or_province_name,json=stateOrProvinceName,proto3" json:"state_or_province_name,omitempty"`
+	SuccessfulAttractions       int32                  `protobuf:"varint,3,opt,name=successful_attractions,json=successfulAttractions,proto3" json:"successful_attractions,omitempty"`
+	FailedAttractions           int32                  `protobuf:"varint,4,opt,name=failed_attractions,json=failedAttractions,proto3" json:"failed_attractions,omitempty"`
+	InsideCityAttractions       int32                  `protobuf:"varint,5,opt,name=inside_city_attractions,json=insideCityAttractions,proto3" json:"inside_city_attractions,omitempty"`
+	NearCityAttractions         int32                  `protobuf:"varint,6,opt,name=near_city_attractions,json=nearCityAttractions,proto3" json:"near_city_attractions,omitempty"`
+	OutsideCityAttractions      int32                  `protobuf:"varint,7,opt,name=outside_city_attractions,json=outsideCityAttractions,proto3" json:"outside_city_attractions,omitempty"`
+	MatchedNeighborhoods        int32                  `protobuf:"varint,8,opt,name=matched_neighborhoods,json=matchedNeighborhoods,proto3" json:"matched_neighborhoods,omitempty"`
+	CrossCityMatches            int32                  `protobuf:"varint,9,opt,name=cross_city_matches,json=crossCityMatches,proto3" json:"cross_city_matches,omitempty"`
+	ContainsClosestNeighborhood bool                   `protobuf:"varint,10,opt,name=contains_closest_neighborhood,json=containsClosestNeighborhood,proto3" json:"contains_closest_neighborhood,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *CityStatistics) Reset() {
+	*x = CityStatistics{}
+	mi := &file_planner_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CityStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CityStatistics) ProtoMessage() {}
+
+func (x *CityStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CityStatistics.ProtoReflect.Descriptor instead.
+func (*CityStatistics) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CityStatistics) GetCityName() string {
+	if x != nil {
+		return x.CityName
+	}
+	return ""
+}
+
+func (x *CityStatistics) GetStateOrProvinceName() string {
+	if x != nil {
+		return x.StateOrProvinceName
+	}
+	return ""
+}
+
+func (x *CityStatistics) GetSuccessfulAttractions() int32 {
+	if x != nil {
+		return x.SuccessfulAttractions
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetFailedAttractions() int32 {
+	if x != nil {
+		return x.FailedAttractions
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetInsideCityAttractions() int32 {
+	if x != nil {
+		return x.InsideCityAttractions
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetNearCityAttractions() int32 {
+	if x != nil {
+		return x.NearCityAttractions
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetOutsideCityAttractions() int32 {
+	if x != nil {
+		return x.OutsideCityAttractions
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetMatchedNeighborhoods() int32 {
+	if x != nil {
+		return x.MatchedNeighborhoods
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetCrossCityMatches() int32 {
+	if x != nil {
+		return x.CrossCityMatches
+	}
+	return 0
+}
+
+func (x *CityStatistics) GetContainsClosestNeighborhood() bool {
+	if x != nil {
+		return x.ContainsClosestNeighborhood
+	}
+	return false
+}
+
+type Dataset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Source        string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`
+	License       string                 `protobuf:"bytes,5,opt,name=license,proto3" json:"license,omitempty"`
+	ImportedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=imported_at,json=importedAt,proto3" json:"imported_at,omitempty"`
+	Active        bool                   `protobuf:"varint,7,opt,name=active,proto3" json:"active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dataset) Reset() {
+	*x = Dataset{}
+	mi := &file_planner_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dataset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dataset) ProtoMessage() {}
+
+func (x *Dataset) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dataset.ProtoReflect.Descriptor instead.
+func (*Dataset) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Dataset) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Dataset) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Dataset) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Dataset) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Dataset) GetLicense() string {
+	if x != nil {
+		return x.License
+	}
+	return ""
+}
+
+func (x *Dataset) GetImportedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ImportedAt
+	}
+	return nil
+}
+
+func (x *Dataset) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+type AttractionsResponse struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	SuccessfulAttractions  []*Attraction          `protobuf:"bytes,1,rep,name=successful_attractions,json=successfulAttractions,proto3" json:"successful_attractions,omitempty"`
+	FailedAttractions      []*Attraction          `protobuf:"bytes,2,rep,name=failed_attractions,json=failedAttractions,proto3" json:"failed_attractions,omitempty"`
+	InsideCityAttractions  []*Attraction          `protobuf:"bytes,3,rep,name=inside_city_attractions,json=insideCityAttractions,proto3" json:"inside_city_attractions,omitempty"`
+	NearCityAttractions    []*Attraction          `protobuf:"bytes,4,rep,name=near_city_attractions,json=nearCityAttractions,proto3" json:"near_city_attractions,omitempty"`
+	OutsideCityAttractions []*Attraction          `protobuf:"bytes,5,rep,name=outside_city_attractions,json=outsideCityAttractions,proto3" json:"outside_city_attractions,omitempty"`
+	ClosestNeighborhood    *Neighborhood          `protobuf:"bytes,6,opt,name=closest_neighborhood,json=closestNeighborhood,proto3" json:"closest_neighborhood,omitempty"`
+	Region                 *Region                `protobuf:"bytes,7,opt,name=region,proto3" json:"region,omitempty"`
+	Cities                 []*CityStatistics      `protobuf:"bytes,8,rep,name=cities,proto3" json:"cities,omitempty"`
+	Datasets               []*Dataset             `protobuf:"bytes,9,rep,name=datasets,proto3" json:"datasets,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *AttractionsResponse) Reset() {
+	*x = AttractionsResponse{}
+	mi := &file_planner_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttractionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttractionsResponse) ProtoMessage() {}
+
+func (x *AttractionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_planner_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttractionsResponse.ProtoReflect.Descriptor instead.
+func (*AttractionsResponse) Descriptor() ([]byte, []int) {
+	return file_planner_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AttractionsResponse) GetSuccessfulAttractions() []*Attraction {
+	if x != nil {
+		return x.SuccessfulAttractions
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetFailedAttractions() []*Attraction {
+	if x != nil {
+		return x.FailedAttractions
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetInsideCityAttractions() []*Attraction {
+	if x != nil {
+		return x.InsideCityAttractions
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetNearCityAttractions() []*Attraction {
+	if x != nil {
+		return x.NearCityAttractions
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetOutsideCityAttractions() []*Attraction {
+	if x != nil {
+		return x.OutsideCityAttractions
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetClosestNeighborhood() *Neighborhood {
+	if x != nil {
+		return x.ClosestNeighborhood
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetRegion() *Region {
+	if x != nil {
+		return x.Region
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetCities() []*CityStatistics {
+	if x != nil {
+		return x.Cities
+	}
+	return nil
+}
+
+func (x *AttractionsResponse) GetDatasets() []*Dataset {
+	if x != nil {
+		return x.Datasets
+	}
+	return nil
+}
+
+var File_planner_proto protoreflect.FileDescriptor
+
+const file_planner_proto_rawDesc = "" +
+	"\n" +
+	"\rplanner.proto\x12\n" +
+	"planner.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9a\x01\n" +
+	"\x1bFindBestNeighborhoodRequest\x128\n" +
+	"\vattractions\x18\x01 \x03(\v2\x16.planner.v1.AttractionR\vattractions\x12A\n" +
+	"\vpreferences\x18\x02 \x01(\v2\x1f.planner.v1.PlanningPreferencesR\vpreferences\"\xc2\x01\n" +
+	"\x13PlanningPreferences\x12?\n" +
+	"\x1anear_city_buffer_in_meters\x18\x01 \x01(\x01H\x00R\x16nearCityBufferInMeters\x88\x01\x01\x12)\n" +
+	"\x10scoring_strategy\x18\x02 \x01(\tR\x0fscoringStrategy\x12 \n" +
+	"\vgranularity\x18\x03 \x01(\tR\vgranularityB\x1d\n" +
+	"\x1b_near_city_buffer_in_meters\"\xa4\x01\n" +
+	"\x1aResolveAttractionsResponse\x12B\n" +
+	"\n" +
+	"resolution\x18\x01 \x01(\v2 .planner.v1.AttractionResolutionH\x00R\n" +
+	"resolution\x129\n" +
+	"\x06result\x18\x02 \x01(\v2\x1f.planner.v1.AttractionsResponseH\x00R\x06resultB\a\n" +
+	"\x05event\"\x8c\x01\n" +
+	"\x14AttractionResolution\x126\n" +
+	"\n" +
+	"attraction\x18\x01 \x01(\v2\x16.planner.v1.AttractionR\n" +
+	"attraction\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12&\n" +
+	"\x0eclassification\x18\x03 \x01(\tR\x0eclassification\"\xa7\x03\n" +
+	"\n" +
+	"Attraction\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04city\x18\x02 \x01(\tR\x04city\x123\n" +
+	"\x16state_or_province_name\x18\x03 \x01(\tR\x13stateOrProvinceName\x12\x18\n" +
+	"\acountry\x18\x04 \x01(\tR\acountry\x12\x1a\n" +
+	"\blatitude\x18\x05 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x06 \x01(\x01R\tlongitude\x12\x16\n" +
+	"\x06weight\x18\a \x01(\x01R\x06weight\x12\x14\n" +
+	"\x05notes\x18\b \x01(\tR\x05notes\x121\n" +
+	"\x14coordinate_precision\x18\t \x01(\x05R\x13coordinatePrecision\x129\n" +
+	"\tgeocoding\x18\n" +
+	" \x01(\v2\x1b.planner.v1.GeocodingResultR\tgeocoding\x12L\n" +
+	"\x12neighborhood_match\x18\v \x01(\v2\x1d.planner.v1.NeighborhoodMatchR\x11neighborhoodMatch\"\xcd\x01\n" +
+	"\x12GeocodingCandidate\x12!\n" +
+	"\fdisplay_name\x18\x01 \x01(\tR\vdisplayName\x12\x1a\n" +
+	"\blatitude\x18\x02 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x03 \x01(\x01R\tlongitude\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x12:\n" +
+	"\fbounding_box\x18\x05 \x01(\v2\x17.planner.v1.BoundingBoxR\vboundingBox\"\x9a\x02\n" +
+	"\x0fGeocodingResult\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12<\n" +
+	"\tcandidate\x18\x02 \x01(\v2\x1e.planner.v1.GeocodingCandidateR\tcandidate\x12B\n" +
+	"\falternatives\x18\x03 \x03(\v2\x1e.planner.v1.GeocodingCandidateR\falternatives\x12\x1c\n" +
+	"\tambiguous\x18\x04 \x01(\bR\tambiguous\x12\x1e\n" +
+	"\n" +
+	"suspicious\x18\x05 \x01(\bR\n" +
+	"suspicious\x12+\n" +
+	"\x11suspicious_reason\x18\x06 \x01(\tR\x10suspiciousReason\"\x9d\x01\n" +
+	"\vBoundingBox\x12!\n" +
+	"\fmin_latitude\x18\x01 \x01(\x01R\vminLatitude\x12#\n" +
+	"\rmin_longitude\x18\x02 \x01(\x01R\fminLongitude\x12!\n" +
+	"\fmax_latitude\x18\x03 \x01(\x01R\vmaxLatitude\x12#\n" +
+	"\rmax_longitude\x18\x04 \x01(\x01R\fmaxLongitude\"\x9e\x01\n" +
+	"\x11NeighborhoodMatch\x12<\n" +
+	"\fneighborhood\x18\x01 \x01(\v2\x18.planner.v1.NeighborhoodR\fneighborhood\x12\x1d\n" +
+	"\n" +
+	"match_type\x18\x02 \x01(\tR\tmatchType\x12,\n" +
+	"\x12distance_in_meters\x18\x03 \x01(\x01R\x10distanceInMeters\"\xe2\x02\n" +
+	"\fNeighborhood\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tcity_name\x18\x03 \x01(\tR\bcityName\x123\n" +
+	"\x16state_or_province_name\x18\x04 \x01(\tR\x13stateOrProvinceName\x12\x18\n" +
+	"\acountry\x18\x05 \x01(\tR\acountry\x12\x1a\n" +
+	"\blatitude\x18\x06 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\a \x01(\x01R\tlongitude\x12\x1b\n" +
+	"\tparent_id\x18\b \x01(\x03R\bparentId\x12\x14\n" +
+	"\x05level\x18\t \x01(\tR\x05level\x126\n" +
+	"\tancestors\x18\n" +
+	" \x03(\v2\x18.planner.v1.NeighborhoodR\tancestors\x12\x1d\n" +
+	"\n" +
+	"dataset_id\x18\v \x01(\x03R\tdatasetId\"^\n" +
+	"\n" +
+	"RegionCity\x12\x1b\n" +
+	"\tcity_name\x18\x01 \x01(\tR\bcityName\x123\n" +
+	"\x16state_or_province_name\x18\x02 \x01(\tR\x13stateOrProvinceName\"8\n" +
+	"\x06Region\x12.\n" +
+	"\x06cities\x18\x01 \x03(\v2\x16.planner.v1.RegionCityR\x06cities\"\x95\x04\n" +
+	"\x0eCityStatistics\x12\x1b\n" +
+	"\tcity_name\x18\x01 \x01(\tR\bcityName\x123\n" +
+	"\x16state_or_province_name\x18\x02 \x01(\tR\x13stateOrProvinceName\x125\n" +
+	"\x16successful_attractions\x18\x03 \x01(\x05R\x15successfulAttractions\x12-\n" +
+	"\x12failed_attractions\x18\x04 \x01(\x05R\x11failedAttractions\x126\n" +
+	"\x17inside_city_attractions\x18\x05 \x01(\x05R\x15insideCityAttractions\x122\n" +
+	"\x15near_city_attractions\x18\x06 \x01(\x05R\x13nearCityAttractions\x128\n" +
+	"\x18outside_city_attractions\x18\a \x01(\x05R\x16outsideCityAttractions\x123\n" +
+	"\x15matched_neighborhoods\x18\b \x01(\x05R\x14matchedNeighborhoods\x12,\n" +
+	"\x12cross_city_matches\x18\t \x01(\x05R\x10crossCityMatches\x12B\n" +
+	"\x1dcontains_closest_neighborhood\x18\n" +
+	" \x01(\bR\x1bcontainsClosestNeighborhood\"\xce\x01\n" +
+	"\aDataset\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12\x18\n" +
+	"\alicense\x18\x05 \x01(\tR\alicense\x12;\n" +
+	"\vimported_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"importedAt\x12\x16\n" +
+	"\x06active\x18\a \x01(\bR\x06active\"\xf7\x04\n" +
+	"\x13AttractionsResponse\x12M\n" +
+	"\x16successful_attractions\x18\x01 \x03(\v2\x16.planner.v1.AttractionR\x15successfulAttractions\x12E\n" +
+	"\x12failed_attractions\x18\x02 \x03(\v2\x16.planner.v1.AttractionR\x11failedAttractions\x12N\n" +
+	"\x17inside_city_attractions\x18\x03 \x03(\v2\x16.planner.v1.AttractionR\x15insideCityAttractions\x12J\n" +
+	"\x15near_city_attractions\x18\x04 \x03(\v2\x16.planner.v1.AttractionR\x13nearCityAttractions\x12P\n" +
+	"\x18outside_city_attractions\x18\x05 \x03(\v2\x16.planner.v1.AttractionR\x16outsideCityAttractions\x12K\n" +
+	"\x14closest_neighborhood\x18\x06 \x01(\v2\x18.planner.v1.NeighborhoodR\x13closestNeighborhood\x12*\n" +
+	"\x06region\x18\a \x01(\v2\x12.planner.v1.RegionR\x06region\x122\n" +
+	"\x06cities\x18\b \x03(\v2\x1a.planner.v1.CityStatisticsR\x06cities\x12/\n" +
+	"\bdatasets\x18\t \x03(\v2\x13.planner.v1.DatasetR\bdatasets2\xd4\x01\n" +
+	"\aPlanner\x12`\n" +
+	"\x14FindBestNeighborhood\x12'.planner.v1.FindBestNeighborhoodRequest\x1a\x1f.planner.v1.AttractionsResponse\x12g\n" +
+	"\x12ResolveAttractions\x12'.planner.v1.FindBestNeighborhoodRequest\x1a&.planner.v1.ResolveAttractionsResponse0\x01B\x19Z\x17pkg/plannerpb;plannerpbb\x06proto3"
+
+var (
+	file_planner_proto_rawDescOnce sync.Once
+	file_planner_proto_rawDescData []byte
+)
+
+func file_planner_proto_rawDescGZIP() []byte {
+	file_planner_proto_rawDescOnce.Do(func() {
+		file_planner_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_planner_proto_rawDesc), len(file_planner_proto_rawDesc)))
+	})
+	return file_planner_proto_rawDescData
+}
+
+var file_planner_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_planner_proto_goTypes = []any{
+	(*FindBestNeighborhoodRequest)(nil), // 0: planner.v1.FindBestNeighborhoodRequest
+	(*PlanningPreferences)(nil),         // 1: planner.v1.PlanningPreferences
+	(*ResolveAttractionsResponse)(nil),  // 2: planner.v1.ResolveAttractionsResponse
+	(*AttractionResolution)(nil),        // 3: planner.v1.AttractionResolution
+	(*Attraction)(nil),                  // 4: planner.v1.Attraction
+	(*GeocodingCandidate)(nil),          // 5: planner.v1.GeocodingCandidate
+	(*GeocodingResult)(nil),             // 6: planner.v1.GeocodingResult
+	(*BoundingBox)(nil),                 // 7: planner.v1.BoundingBox
+	(*NeighborhoodMatch)(nil),           // 8: planner.v1.NeighborhoodMatch
+	(*Neighborhood)(nil),                // 9: planner.v1.Neighborhood
+	(*RegionCity)(nil),                  // 10: planner.v1.RegionCity
+	(*Region)(nil),                      // 11: planner.v1.Region
+	(*CityStatistics)(nil),              // 12: planner.v1.CityStatistics
+	(*Dataset)(nil),                     // 13: planner.v1.Dataset
+	(*AttractionsResponse)(nil),         // 14: planner.v1.AttractionsResponse
+	(*timestamppb.Timestamp)(nil),       // 15: google.protobuf.Timestamp
+}
+var file_planner_proto_depIdxs = []int32{
+	4,  // 0: planner.v1.FindBestNeighborhoodRequest.attractions:type_name -> planner.v1.Attraction
+	1,  // 1: planner.v1.FindBestNeighborhoodRequest.preferences:type_name -> planner.v1.PlanningPreferences
+	3,  // 2: planner.v1.ResolveAttractionsResponse.resolution:type_name -> planner.v1.AttractionResolution
+	14, // 3: planner.v1.ResolveAttractionsResponse.result:type_name -> planner.v1.AttractionsResponse
+	4,  // 4: planner.v1.AttractionResolution.attraction:type_name -> planner.v1.Attraction
+	6,  // 5: planner.v1.Attraction.geocoding:type_name -> planner.v1.GeocodingResult
+	8,  // 6: planner.v1.Attraction.neighborhood_match:type_name -> planner.v1.NeighborhoodMatch
+	7,  // 7: planner.v1.GeocodingCandidate.bounding_box:type_name -> planner.v1.BoundingBox
+	5,  // 8: planner.v1.GeocodingResult.candidate:type_name -> planner.v1.GeocodingCandidate
+	5,  // 9: planner.v1.GeocodingResult.alternatives:type_name -> planner.v1.GeocodingCandidate
+	9,  // 10: planner.v1.NeighborhoodMatch.neighborhood:type_name -> planner.v1.Neighborhood
+	9,  // 11: planner.v1.Neighborhood.ancestors:type_name -> planner.v1.Neighborhood
+	10, // 12: planner.v1.Region.cities:type_name -> planner.v1.RegionCity
+	15, // 13: planner.v1.Dataset.imported_at:type_name -> google.protobuf.Timestamp
+	4,  // 14: planner.v1.AttractionsResponse.successful_attractions:type_name -> planner.v1.Attraction
+	4,  // 15: planner.v1.AttractionsResponse.failed_attractions:type_name -> planner.v1.Attraction
+	4,  // 16: planner.v1.AttractionsResponse.inside_city_attractions:type_name -> planner.v1.Attraction
+	4,  // 17: planner.v1.AttractionsResponse.near_city_attractions:type_name -> planner.v1.Attraction
+	4,  // 18: planner.v1.AttractionsResponse.outside_city_attractions:type_name -> planner.v1.Attraction
+	9,  // 19: planner.v1.AttractionsResponse.closest_neighborhood:type_name -> planner.v1.Neighborhood
+	11, // 20: planner.v1.AttractionsResponse.region:type_name -> planner.v1.Region
+	12, // 21: planner.v1.AttractionsResponse.cities:type_name -> planner.v1.CityStatistics
+	13, // 22: planner.v1.AttractionsResponse.datasets:type_name -> planner.v1.Dataset
+	0,  // 23: planner.v1.Planner.FindBestNeighborhood:input_type -> planner.v1.FindBestNeighborhoodRequest
+	0,  // 24: planner.v1.Planner.ResolveAttractions:input_type -> planner.v1.FindBestNeighborhoodRequest
+	14, // 25: planner.v1.Planner.FindBestNeighborhood:output_type -> planner.v1.AttractionsResponse
+	2,  // 26: planner.v1.Planner.ResolveAttractions:output_type -> planner.v1.ResolveAttractionsResponse
+	25, // [25:27] is the sub-list for method output_type
+	23, // [23:25] is the sub-list for method input_type
+	23, // [23:23] is the sub-list for extension type_name
+	23, // [23:23] is the sub-list for extension extendee
+	0,  // [0:23] is the sub-list for field type_name
+}
+
+func init() { file_planner_proto_init() }
+func file_planner_proto_init() {
+	if File_planner_proto != nil {
+		return
+	}
+	file_planner_proto_msgTypes[1].OneofWrappers = []any{}
+	file_planner_proto_msgTypes[2].OneofWrappers = []any{
+		(*ResolveAttractionsResponse_Resolution)(nil),
+		(*ResolveAttractionsResponse_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_planner_proto_rawDesc), len(file_planner_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_planner_proto_goTypes,
+		DependencyIndexes: file_planner_proto_depIdxs,
+		MessageInfos:      file_planner_proto_msgTypes,
+	}.Build()
+	File_planner_proto = out.File
+	file_planner_proto_goTypes = nil
+	file_planner_proto_depIdxs = nil
+}
@@ -0,0 +1,172 @@
+// Planner finds the best neighborhood to stay in for a trip's attractions. Messages mirror the JSON of
+// the HTTP API (see /v1/openapi.json); field names match its JSON keys.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: planner.proto
+
+package plannerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Planner_FindBestNeighborhood_FullMethodName = "/planner.v1.Planner/FindBestNeighborhood"
+	Planner_ResolveAttractions_FullMethodName   = "/planner.v1.Planner/ResolveAttractions"
+)
+
+// PlannerClient is the client API for Planner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlannerClient interface {
+	// FindBestNeighborhood plans the attractions as POST /v1/attractions does.
+	FindBestNeighborhood(ctx context.Context, in *FindBestNeighborhoodRequest, opts ...grpc.CallOption) (*AttractionsResponse, error)
+	// ResolveAttractions plans the attractions as POST /v1/attractions/stream does, sending a resolution
+	// as each attraction is located and again as it is classified, then the result.
+	ResolveAttractions(ctx context.Context, in *FindBestNeighborhoodRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResolveAttractionsResponse], error)
+}
+
+type plannerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlannerClient(cc grpc.ClientConnInterface) PlannerClient {
+	return &plannerClient{cc}
+}
+
+func (c *plannerClient) FindBestNeighborhood(ctx context.Context, in *FindBestNeighborhoodRequest, opts ...grpc.CallOption) (*AttractionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttractionsResponse)
+	err := c.cc.Invoke(ctx, Planner_FindBestNeighborhood_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *plannerClient) ResolveAttractions(ctx context.Context, in *FindBestNeighborhoodRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ResolveAttractionsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Planner_ServiceDesc.Streams[0], Planner_ResolveAttractions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FindBestNeighborhoodRequest, ResolveAttractionsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Planner_ResolveAttractionsClient = grpc.ServerStreamingClient[ResolveAttractionsResponse]
+
+// PlannerServer is the server API for Planner service.
+// All implementations must embed UnimplementedPlannerServer
+// for forward compatibility.
+type PlannerServer interface {
+	// FindBestNeighborhood plans the attractions as POST /v1/attractions does.
+	FindBestNeighborhood(context.Context, *FindBestNeighborhoodRequest) (*AttractionsResponse, error)
+	// ResolveAttractions plans the attractions as POST /v1/attractions/stream does, sending a resolution
+	// as each attraction is located and again as it is classified, then the result.
+	ResolveAttractions(*FindBestNeighborhoodRequest, grpc.ServerStreamingServer[ResolveAttractionsResponse]) error
+	mustEmbedUnimplementedPlannerServer()
+}
+
+// UnimplementedPlannerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPlannerServer struct{}
+
+func (UnimplementedPlannerServer) FindBestNeighborhood(context.Context, *FindBestNeighborhoodRequest) (*AttractionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindBestNeighborhood not implemented")
+}
+func (UnimplementedPlannerServer) ResolveAttractions(*FindBestNeighborhoodRequest, grpc.ServerStreamingServer[ResolveAttractionsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ResolveAttractions not implemented")
+}
+func (UnimplementedPlannerServer) mustEmbedUnimplementedPlannerServer() {}
+func (UnimplementedPlannerServer) testEmbeddedByValue()                 {}
+
+// UnsafePlannerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlannerServer will
+// result in compilation errors.
+type UnsafePlannerServer interface {
+	mustEmbedUnimplementedPlannerServer()
+}
+
+func RegisterPlannerServer(s grpc.ServiceRegistrar, srv PlannerServer) {
+	// If the following call pancis, it indicates UnimplementedPlannerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Planner_ServiceDesc, srv)
+}
+
+func _Planner_FindBestNeighborhood_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindBestNeighborhoodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlannerServer).FindBestNeighborhood(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Planner_FindBestNeighborhood_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlannerServer).FindBestNeighborhood(ctx, req.(*FindBestNeighborhoodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Planner_ResolveAttractions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FindBestNeighborhoodRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlannerServer).ResolveAttractions(m, &grpc.GenericServerStream[FindBestNeighborhoodRequest, ResolveAttractionsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Planner_ResolveAttractionsServer = grpc.ServerStreamingServer[ResolveAttractionsResponse]
+
+// Planner_ServiceDesc is the grpc.ServiceDesc for Planner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Planner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "planner.v1.Planner",
+	HandlerType: (*PlannerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FindBestNeighborhood",
+			Handler:    _Planner_FindBestNeighborhood_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ResolveAttractions",
+			Handler:       _Planner_ResolveAttractions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "planner.proto",
+}